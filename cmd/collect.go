@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/collector"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagDataDir    = "data-dir"
+	flagToken      = "token"
+	flagTLSCert    = "tls-cert"
+	flagTLSKey     = "tls-key"
+	flagClientCA   = "client-ca"
+	flagMaxHistory = "max-history"
+	flagStaleAfter = "stale-after"
+)
+
+func collectCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "collect",
+		Usage: "Runs a collector aggregating the reports sent by many tool instances into a fleet dashboard",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagDataDir,
+				Usage:   "Defines the directory where the received reports are stored.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagDataDir)),
+				Value:   "collector-data",
+			},
+			&cli.StringSliceFlag{
+				Name:    flagToken,
+				Usage:   "Defines the bearer token of a cluster, as <cluster>=<token>. A sender using the token can only send the reports of this cluster, and readers can use it to access the dashboard.",
+				Sources: cli.EnvVars("COLLECTOR_TOKENS"),
+			},
+			&cli.StringFlag{
+				Name:    flagTLSCert,
+				Usage:   "Defines the TLS certificate file used to serve the collector over HTTPS.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTLSCert)),
+			},
+			&cli.StringFlag{
+				Name:    flagTLSKey,
+				Usage:   "Defines the TLS private key file used to serve the collector over HTTPS.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTLSKey)),
+			},
+			&cli.StringFlag{
+				Name:    flagClientCA,
+				Usage:   "Defines the CA file used to verify client certificates. Senders presenting a valid certificate are identified by its common name. Requires --tls-cert and --tls-key.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagClientCA)),
+			},
+			&cli.IntFlag{
+				Name:    flagMaxHistory,
+				Usage:   "Defines the number of reports kept per cluster. When 0, all reports are kept.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagMaxHistory)),
+				Value:   100,
+			},
+			&cli.DurationFlag{
+				Name:    flagStaleAfter,
+				Usage:   "Defines the duration after which a cluster which did not report is flagged as stale. When 0, clusters are never stale.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagStaleAfter)),
+				Value:   24 * time.Hour,
+			},
+		},
+		Action: collect,
+	}
+}
+
+func collect(ctx context.Context, cmd *cli.Command) error {
+	logger.Setup("info", os.Stdout)
+
+	tlsConfig, err := buildCollectorTLSConfig(cmd.String(flagTLSCert), cmd.String(flagTLSKey), cmd.String(flagClientCA))
+	if err != nil {
+		return err
+	}
+
+	tokens, err := parseCollectorTokens(cmd.StringSlice(flagToken))
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 && (tlsConfig == nil || tlsConfig.ClientCAs == nil) {
+		return fmt.Errorf("at least one of --%s or --%s is required to authenticate senders", flagToken, flagClientCA)
+	}
+
+	coll, err := collector.New(collector.Config{
+		DataDir:    cmd.String(flagDataDir),
+		Tokens:     tokens,
+		MaxHistory: int(cmd.Int(flagMaxHistory)),
+		StaleAfter: cmd.Duration(flagStaleAfter),
+	})
+	if err != nil {
+		return fmt.Errorf("creating collector: %w", err)
+	}
+
+	addr := cmd.String(flagAddr)
+	errCh := make(chan error)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           coll.Handler(),
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.Info().Msgf("Starting Ingress NGINX migration collector on %s", addr)
+
+		var err error
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-ctx.Done():
+		_ = server.Close()
+	case err := <-errCh:
+		return fmt.Errorf("collector server error: %w", err)
+	}
+
+	return nil
+}
+
+// parseCollectorTokens returns the bearer tokens by cluster name, from <cluster>=<token> values.
+func parseCollectorTokens(values []string) (map[string]string, error) {
+	tokens := make(map[string]string, len(values))
+	for _, value := range values {
+		cluster, token, ok := strings.Cut(value, "=")
+		if !ok || cluster == "" || token == "" {
+			return nil, fmt.Errorf("invalid --%s: expected <cluster>=<token>", flagToken)
+		}
+		if _, exists := tokens[cluster]; exists {
+			return nil, fmt.Errorf("invalid --%s: duplicate cluster %q", flagToken, cluster)
+		}
+
+		tokens[cluster] = token
+	}
+
+	return tokens, nil
+}
+
+// buildCollectorTLSConfig returns the TLS configuration of the collector server,
+// or nil when it is served over plain HTTP.
+func buildCollectorTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("--%s requires --%s and --%s", flagClientCA, flagTLSCert, flagTLSKey)
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile != "" {
+		caCertPEM, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA: %w", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCertPEM) {
+			return nil, errors.New("failed to parse client CA certificate")
+		}
+
+		// Client certificates are optional so that token authenticated senders
+		// and browsers can still reach the collector.
+		tlsConfig.ClientCAs = caCertPool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfig, nil
+}
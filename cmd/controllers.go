@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+	"github.com/traefik/ingress-nginx-migration/pkg/render"
+	"github.com/urfave/cli/v3"
+	"k8s.io/client-go/kubernetes"
+)
+
+// runControllers analyzes the Ingresses of several NGINX ingress controllers, sharing the
+// informers of analyzr, and writes or serves one report per controller.
+func runControllers(ctx context.Context, cmd *cli.Command, k8sClient *kubernetes.Clientset, analyzr *analyzer.Analyzer, oneShot *oneShotOutput) error {
+	if cmd.String(flagHistoryDir) != "" {
+		return fmt.Errorf("--%s is not supported with several controllers", flagHistoryDir)
+	}
+
+	controllers, err := nginxControllers(cmd, analyzr)
+	if err != nil {
+		return err
+	}
+
+	analyzers := make([]*analyzer.Analyzer, 0, len(controllers))
+	for _, controller := range controllers {
+		controllerAnalyzer := analyzr.ForController(controller)
+		if err := controllerAnalyzer.GenerateReport(); err != nil {
+			return fmt.Errorf("generating report of controller %s: %w", controller.ControllerClass, err)
+		}
+
+		analyzers = append(analyzers, controllerAnalyzer)
+	}
+
+	// One-shot mode: write the reports once and exit without serving.
+	if oneShot != nil {
+		reports := make([]render.ControllerReport, 0, len(controllers))
+		for i, controller := range controllers {
+			reports = append(reports, render.ControllerReport{Controller: controller, Report: analyzers[i].Report()})
+		}
+
+		return writeOutput(oneShot.outputFile, func(w io.Writer) error {
+			return render.RenderControllers(reports, oneShot.format, oneShot.summary, w)
+		})
+	}
+
+	clt, err := newClient(cmd.String(flagAuditLog))
+	if err != nil {
+		return err
+	}
+
+	// Each report is served under its own path, the index links to them.
+	mux := http.NewServeMux()
+	scope := namespaceScope(cmd, k8sClient)
+	links := make([]handlers.ControllerLink, 0, len(controllers))
+	for i, controller := range controllers {
+		hdl, err := handlers.New(analyzers[i], clt, nil, scope)
+		if err != nil {
+			return fmt.Errorf("creating handlers: %w", err)
+		}
+
+		prefix := "/controllers/" + strconv.Itoa(i)
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, reportRouter(hdl)))
+
+		links = append(links, handlers.ControllerLink{Controller: controller, Path: prefix + "/", Analyzer: analyzers[i]})
+	}
+
+	index, err := handlers.Controllers(links, scope)
+	if err != nil {
+		return err
+	}
+	mux.Handle("GET /{$}", index)
+
+	return serve(ctx, cmd.String(flagAddr), mux, "reports per controller")
+}
+
+// nginxControllers returns the controllers of --controllers, or the discovered ones
+// with --all-controllers.
+func nginxControllers(cmd *cli.Command, analyzr *analyzer.Analyzer) ([]analyzer.Controller, error) {
+	if !cmd.Bool(flagAllControllers) {
+		var controllers []analyzer.Controller
+		for _, value := range cmd.StringSlice(flagControllers) {
+			controllerClass, ingressClass, _ := strings.Cut(value, "=")
+			if controllerClass == "" {
+				return nil, fmt.Errorf("invalid --%s value %q, expected <controller-class>[=<ingress-class>]", flagControllers, value)
+			}
+
+			controllers = append(controllers, analyzer.Controller{ControllerClass: controllerClass, IngressClass: ingressClass})
+		}
+
+		if err := analyzer.CheckIngressClasses(controllers); err != nil {
+			return nil, fmt.Errorf("invalid --%s: %w, set a distinct <ingress-class> for each controller", flagControllers, err)
+		}
+
+		return controllers, nil
+	}
+
+	if len(cmd.StringSlice(flagControllers)) > 0 {
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", flagControllers, flagAllControllers)
+	}
+
+	controllers, err := analyzr.DiscoverControllers()
+	if err != nil {
+		return nil, fmt.Errorf("discovering controllers: %w", err)
+	}
+	if len(controllers) == 0 {
+		return nil, errors.New("no NGINX ingress controller deployment or IngressClass found")
+	}
+
+	for _, controller := range controllers {
+		log.Info().Str("controllerClass", controller.ControllerClass).Str("ingressClass", controller.IngressClass).Msg("NGINX ingress controller discovered")
+	}
+
+	return controllers, nil
+}
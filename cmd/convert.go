@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/convert"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/urfave/cli/v3"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	flagTo         = "to"
+	flagFileFormat = "file-format"
+	flagSecretsDir = "secrets-dir"
+)
+
+func convertCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "convert",
+		Usage: "Converts the analyzed Ingresses to native Traefik configuration, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagTo,
+				Usage:    fmt.Sprintf("Defines the conversion target (%q or %q).", convert.TargetTraefikCRD, convert.TargetTraefikFile),
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagTo)),
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagFileFormat,
+				Usage:   fmt.Sprintf("Defines the format of the %q target (%q or %q).", convert.TargetTraefikFile, convert.FileFormatYAML, convert.FileFormatTOML),
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagFileFormat)),
+				Value:   convert.FileFormatYAML,
+			},
+			&cli.StringFlag{
+				Name:    flagSecretsDir,
+				Usage:   fmt.Sprintf("Defines the directory the Secrets are exported to for the %q target, as <namespace>/<name>/<key>.", convert.TargetTraefikFile),
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagSecretsDir)),
+				Value:   "/etc/traefik/secrets",
+			},
+		},
+		Action: runConvert,
+	}
+}
+
+func runConvert(ctx context.Context, cmd *cli.Command) error {
+	target := cmd.String(flagTo)
+	if target != convert.TargetTraefikCRD && target != convert.TargetTraefikFile {
+		return fmt.Errorf("invalid --%s %q (must be %q or %q)", flagTo, target, convert.TargetTraefikCRD, convert.TargetTraefikFile)
+	}
+
+	format := cmd.String(flagFileFormat)
+	if format != convert.FileFormatYAML && format != convert.FileFormatTOML {
+		return fmt.Errorf("invalid --%s %q (must be %q or %q)", flagFileFormat, format, convert.FileFormatYAML, convert.FileFormatTOML)
+	}
+	if cmd.IsSet(flagFileFormat) && target != convert.TargetTraefikFile {
+		return fmt.Errorf("--%s is only supported with --%s %s", flagFileFormat, flagTo, convert.TargetTraefikFile)
+	}
+
+	// Stdout is reserved for the generated configuration, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	ingresses, err := analyzr.Ingresses()
+	if err != nil {
+		return fmt.Errorf("listing analyzed Ingresses: %w", err)
+	}
+
+	input := convert.Input{Ingresses: ingresses}
+
+	// The namespaces of the analysis leave out the inaccessible ones skipped by the preflight.
+	input.Services, err = listServices(ctx, k8sClient, analyzr.Namespaces())
+	if err != nil {
+		return err
+	}
+
+	if target == convert.TargetTraefikCRD {
+		conversions := convert.TraefikCRD(input)
+		logUnconverted(conversions)
+
+		return convert.WriteManifests(os.Stdout, conversions)
+	}
+
+	// The file provider targets the Service endpoints directly.
+	input.EndpointSlices, err = listEndpointSlices(ctx, k8sClient, analyzr.Namespaces())
+	if err != nil {
+		return err
+	}
+
+	config, conversions := convert.TraefikFile(input, cmd.String(flagSecretsDir))
+	logUnconverted(conversions)
+
+	return convert.WriteFileConfiguration(os.Stdout, config, conversions, format)
+}
+
+func logUnconverted(conversions []convert.Conversion) {
+	for _, conversion := range conversions {
+		for _, unconverted := range conversion.Unconverted {
+			if unconverted.Line > 0 {
+				log.Warn().
+					Str("ingress", conversion.Namespace+"/"+conversion.Name).
+					Str("annotation", unconverted.Annotation).
+					Int("line", unconverted.Line).
+					Str("directive", unconverted.Directive).
+					Msgf("Snippet directive not converted: %s", unconverted.Reason)
+				continue
+			}
+
+			log.Warn().
+				Str("ingress", conversion.Namespace+"/"+conversion.Name).
+				Str("annotation", unconverted.Annotation).
+				Msgf("Annotation not converted: %s", unconverted.Reason)
+		}
+	}
+}
+
+// listServices lists the Services of the namespaces, or of all namespaces when empty.
+func listServices(ctx context.Context, k8sClient kubernetes.Interface, namespaces []string) ([]*corev1.Service, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{corev1.NamespaceAll}
+	}
+
+	var services []*corev1.Service
+	for _, namespace := range namespaces {
+		list, err := k8sClient.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing Services: %w", err)
+		}
+
+		for i := range list.Items {
+			services = append(services, &list.Items[i])
+		}
+	}
+
+	return services, nil
+}
+
+// listEndpointSlices lists the EndpointSlices of the namespaces, or of all namespaces when empty.
+func listEndpointSlices(ctx context.Context, k8sClient kubernetes.Interface, namespaces []string) ([]*discoveryv1.EndpointSlice, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{corev1.NamespaceAll}
+	}
+
+	var endpointSlices []*discoveryv1.EndpointSlice
+	for _, namespace := range namespaces {
+		list, err := k8sClient.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("listing EndpointSlices: %w", err)
+		}
+
+		for i := range list.Items {
+			endpointSlices = append(endpointSlices, &list.Items[i])
+		}
+	}
+
+	return endpointSlices, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/gateway"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagGatewayName      = "gateway-name"
+	flagGatewayNamespace = "gateway-namespace"
+	flagGatewayClass     = "gateway-class"
+	flagMaxListeners     = "max-listeners"
+)
+
+func gatewayCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "gateway",
+		Usage: "Plans the Gateways, listeners, ReferenceGrants and BackendTLSPolicies serving the analyzed Ingresses, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagGatewayName,
+				Usage:   "Defines the name of the Gateway, suffixed with a number when the listeners are split across several Gateways.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagGatewayName)),
+				Value:   "traefik",
+			},
+			&cli.StringFlag{
+				Name:    flagGatewayNamespace,
+				Usage:   "Defines the namespace of the Gateways.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagGatewayNamespace)),
+				Value:   "traefik",
+			},
+			&cli.StringFlag{
+				Name:    flagGatewayClass,
+				Usage:   "Defines the GatewayClass of the Traefik Gateway controller.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagGatewayClass)),
+				Value:   "traefik",
+			},
+			&cli.IntFlag{
+				Name:    flagMaxListeners,
+				Usage:   fmt.Sprintf("Defines the maximum number of listeners per Gateway, at most %d.", gateway.MaxListeners),
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagMaxListeners)),
+				Value:   gateway.MaxListeners,
+			},
+		},
+		Action: runGateway,
+	}
+}
+
+func runGateway(ctx context.Context, cmd *cli.Command) error {
+	// Stdout is reserved for the generated manifests, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	ingresses, err := analyzr.Ingresses()
+	if err != nil {
+		return fmt.Errorf("listing analyzed Ingresses: %w", err)
+	}
+
+	topology, err := gateway.New(ingresses, gateway.Options{
+		Name:         cmd.String(flagGatewayName),
+		Namespace:    cmd.String(flagGatewayNamespace),
+		ClassName:    cmd.String(flagGatewayClass),
+		MaxListeners: int(cmd.Int(flagMaxListeners)),
+	})
+	if err != nil {
+		return fmt.Errorf("planning Gateway topology: %w", err)
+	}
+
+	for _, warning := range topology.Warnings {
+		log.Warn().Msg(warning)
+	}
+
+	return gateway.WriteManifests(os.Stdout, topology)
+}
@@ -13,9 +13,11 @@ import (
 	"github.com/ettle/strcase"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/access"
 	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
 	"github.com/traefik/ingress-nginx-migration/pkg/client"
 	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+	"github.com/traefik/ingress-nginx-migration/pkg/history"
 	"github.com/traefik/ingress-nginx-migration/pkg/logger"
 	"github.com/traefik/ingress-nginx-migration/pkg/render"
 	"github.com/urfave/cli/v3"
@@ -36,6 +38,18 @@ const (
 	flagFormat             = "format"
 	flagOutputFile         = "output-file"
 	flagSummary            = "summary"
+	flagHistoryDir         = "history-dir"
+	flagAuditLog           = "audit-log"
+	flagAuthUserHeader     = "auth-proxy-user-header"
+	flagAuthGroupsHeader   = "auth-proxy-groups-header"
+	flagContext            = "context"
+	flagAllContexts        = "all-contexts"
+	flagContextTimeout     = "context-timeout"
+	flagAs                 = "as"
+	flagAsGroup            = "as-group"
+	flagInaccessible       = "inaccessible-namespaces"
+	flagControllers        = "controllers"
+	flagAllControllers     = "all-controllers"
 )
 
 func main() {
@@ -48,6 +62,15 @@ func main() {
 				Usage:  "Shows the current version",
 				Action: printVersion,
 			},
+			collectCommand(),
+			sendCommand(),
+			convertCommand(),
+			planCommand(),
+			shadowCommand(),
+			gatewayCommand(),
+			simulateCommand(),
+			replayCommand(),
+			smokeCommand(),
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -101,6 +124,69 @@ func main() {
 				Usage:   "Omit the per-Ingress detail from the report. Only valid with --format markdown.",
 				Sources: cli.EnvVars(strcase.ToSNAKE(flagSummary)),
 			},
+			&cli.StringFlag{
+				Name:    flagHistoryDir,
+				Usage:   "Defines the directory where every report whose content changed is stored, enabling the trend page. When empty, past reports are not stored.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagHistoryDir)),
+			},
+			&cli.StringFlag{
+				Name:    flagAuditLog,
+				Usage:   "Defines the file where a record of every report transmission is appended, as JSON lines. When empty, no record is kept.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAuditLog)),
+				Value:   "ingress-nginx-migration-audit.jsonl",
+			},
+			&cli.StringFlag{
+				Name:    flagAuthUserHeader,
+				Usage:   "Defines the header set by a trusted authenticating proxy holding the viewer user name. When set, the report views are restricted to the namespaces the viewer can list Ingresses in.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAuthUserHeader)),
+			},
+			&cli.StringFlag{
+				Name:    flagAuthGroupsHeader,
+				Usage:   "Defines the header set by a trusted authenticating proxy holding the comma-separated viewer groups. Requires --auth-proxy-user-header.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAuthGroupsHeader)),
+			},
+			&cli.StringSliceFlag{
+				Name:    flagContext,
+				Usage:   "Defines the kubeconfig contexts of the clusters to analyze together, in a combined report. When empty, the cluster of the current context is analyzed.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagContext)),
+			},
+			&cli.BoolFlag{
+				Name:    flagAllContexts,
+				Usage:   "Defines if the clusters of every kubeconfig context are analyzed together, in a combined report.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAllContexts)),
+			},
+			&cli.DurationFlag{
+				Name:    flagContextTimeout,
+				Usage:   "Defines the time given to each cluster to be analyzed, with --context or --all-contexts. A cluster not analyzed by then is reported as failed.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagContextTimeout)),
+				Value:   2 * time.Minute,
+			},
+			&cli.StringFlag{
+				Name:    flagAs,
+				Usage:   "Defines the user to impersonate, to analyze the cluster with the permissions of a user or a service account (system:serviceaccount:<namespace>:<name>).",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAs)),
+			},
+			&cli.StringSliceFlag{
+				Name:    flagAsGroup,
+				Usage:   "Defines the groups to impersonate. Requires --as.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAsGroup)),
+			},
+			&cli.StringFlag{
+				Name:    flagInaccessible,
+				Usage:   "Defines what to do with the namespaces whose Ingresses cannot be read: 'fail' the analysis, or 'skip' them, listing them as not analyzed in the report.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagInaccessible)),
+				Value:   inaccessibleFail,
+			},
+			&cli.StringSliceFlag{
+				Name:    flagControllers,
+				Usage:   "Defines several NGINX ingress controllers to analyze, one report each, as <controller-class>[=<ingress-class>]. The ingress class defaults to 'nginx'. Replaces --controller-class and --ingress-class.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagControllers)),
+			},
+			&cli.BoolFlag{
+				Name:    flagAllControllers,
+				Usage:   "Defines if every NGINX ingress controller is analyzed, one report each, discovered from the IngressClasses whose controller contains 'ingress-nginx'.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagAllControllers)),
+			},
 		},
 		Action: run,
 	}
@@ -116,35 +202,36 @@ func run(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	// Creates the Kubernetes client.
-	config, err := rest.InClusterConfig()
-	if err != nil && !errors.Is(err, rest.ErrNotInCluster) {
-		return fmt.Errorf("creating in cluster config: %w", err)
+	if cmd.String(flagAuthGroupsHeader) != "" && cmd.String(flagAuthUserHeader) == "" {
+		return fmt.Errorf("--%s requires --%s", flagAuthGroupsHeader, flagAuthUserHeader)
 	}
-	if err != nil {
-		config, err = clientcmd.BuildConfigFromFlags("", cmd.String(flagKubeconfig))
-		if err != nil {
-			return fmt.Errorf("creating config from flags: %w", err)
+
+	multiController := len(cmd.StringSlice(flagControllers)) > 0 || cmd.Bool(flagAllControllers)
+
+	if len(cmd.StringSlice(flagContext)) > 0 || cmd.Bool(flagAllContexts) {
+		if multiController {
+			return fmt.Errorf("--%s and --%s are not supported with several clusters", flagControllers, flagAllControllers)
 		}
+		return runMultiCluster(ctx, cmd, oneShot)
 	}
 
-	k8sClient, err := kubernetes.NewForConfig(config)
+	k8sClient, err := newKubernetesClient(cmd)
 	if err != nil {
-		return fmt.Errorf("creating k8s client from config: %w", err)
+		return err
 	}
 
-	// Creates and starts the analyzer and generates the report.
-	analyzr, err := analyzer.New(k8sClient, cmd.StringSlice(flagNamespaces), cmd.String(flagControllerClass), cmd.Bool(flagWatchWithoutClass), cmd.String(flagIngressClass), cmd.Bool(flagIngressClassByName))
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
 	if err != nil {
-		return fmt.Errorf("creating analyzer: %w", err)
+		return err
 	}
 
-	if err = analyzr.Start(ctx); err != nil {
-		return fmt.Errorf("starting analyzer: %w", err)
+	if multiController {
+		return runControllers(ctx, cmd, k8sClient, analyzr, oneShot)
 	}
 
-	if err = analyzr.GenerateReport(); err != nil {
-		return fmt.Errorf("generating report: %w", err)
+	hist, err := openHistory(cmd.String(flagHistoryDir), analyzr.Report())
+	if err != nil {
+		return err
 	}
 
 	// One-shot mode: write the report once and exit without serving.
@@ -153,27 +240,55 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	// Creates the platform client.
-	clt, err := client.New(os.Getenv("ENDPOINT_STATS_URL"))
+	clt, err := newClient(cmd.String(flagAuditLog))
 	if err != nil {
-		return fmt.Errorf("creating client: %w", err)
+		return err
 	}
 
 	// Creates the HTTP server.
-	hdl, err := handlers.New(analyzr, clt)
+	hdl, err := handlers.New(analyzr, clt, hist, namespaceScope(cmd, k8sClient))
 	if err != nil {
 		return fmt.Errorf("creating handlers: %w", err)
 	}
 
+	return serve(ctx, cmd.String(flagAddr), reportRouter(hdl), "report")
+}
+
+// namespaceScope returns the scope restricting the views to the namespaces of the viewer,
+// nil when the views are not restricted.
+func namespaceScope(cmd *cli.Command, k8sClient *kubernetes.Clientset) *handlers.NamespaceScope {
+	userHeader := cmd.String(flagAuthUserHeader)
+	if userHeader == "" {
+		return nil
+	}
+
+	return &handlers.NamespaceScope{
+		Authorizer:   access.NewReviewer(k8sClient),
+		UserHeader:   userHeader,
+		GroupsHeader: cmd.String(flagAuthGroupsHeader),
+	}
+}
+
+// reportRouter returns the router of the report handlers.
+func reportRouter(hdl *handlers.Handlers) *httprouter.Router {
 	router := httprouter.New()
 	router.HandlerFunc(http.MethodPut, "/update", hdl.UpdateReport)
 	router.HandlerFunc(http.MethodPut, "/send", hdl.SendReport)
 	router.HandlerFunc(http.MethodGet, "/", hdl.Report)
+	router.HandlerFunc(http.MethodGet, "/trend", hdl.Trend)
+	router.HandlerFunc(http.MethodGet, "/api/ingresses", hdl.Ingresses)
+	router.HandlerFunc(http.MethodGet, "/api/reports", hdl.Reports)
+	router.HandlerFunc(http.MethodGet, "/api/reports/:id", hdl.PastReport)
 
-	addr := cmd.String(flagAddr)
+	return router
+}
+
+// serve serves the handler on addr until ctx is done. page names what is served, in the logs.
+func serve(ctx context.Context, addr string, handler http.Handler, page string) error {
 	errCh := make(chan error)
 	server := &http.Server{
 		Addr:              addr,
-		Handler:           router,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
@@ -184,7 +299,7 @@ func run(ctx context.Context, cmd *cli.Command) error {
 
 	go func() {
 		log.Info().Msg("Starting Ingress NGINX analyzer server")
-		log.Info().Msgf("Please browse the Ingress NGINX analyzer report on: http://%s", reportAddr)
+		log.Info().Msgf("Please browse the Ingress NGINX analyzer %s on: http://%s", page, reportAddr)
 
 		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
 			errCh <- err
@@ -202,6 +317,123 @@ func run(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+// newKubernetesClient creates the Kubernetes client, from the in-cluster
+// configuration or from the kubeconfig file.
+func newKubernetesClient(cmd *cli.Command) (*kubernetes.Clientset, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil && !errors.Is(err, rest.ErrNotInCluster) {
+		return nil, fmt.Errorf("creating in cluster config: %w", err)
+	}
+	if err != nil {
+		config, err = clientcmd.BuildConfigFromFlags("", cmd.String(flagKubeconfig))
+		if err != nil {
+			return nil, fmt.Errorf("creating config from flags: %w", err)
+		}
+	}
+
+	if err := impersonate(cmd, config); err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating k8s client from config: %w", err)
+	}
+
+	return k8sClient, nil
+}
+
+// impersonate configures the impersonation of the --as user and --as-group groups, keeping
+// the impersonation of the kubeconfig when --as is not set.
+func impersonate(cmd *cli.Command, config *rest.Config) error {
+	user, groups := cmd.String(flagAs), cmd.StringSlice(flagAsGroup)
+	if user == "" {
+		if len(groups) > 0 {
+			return fmt.Errorf("--%s requires --%s", flagAsGroup, flagAs)
+		}
+		return nil
+	}
+
+	config.Impersonate = rest.ImpersonationConfig{UserName: user, Groups: groups}
+
+	return nil
+}
+
+// startAnalyzer starts the analyzer and generates a first report.
+func startAnalyzer(ctx context.Context, cmd *cli.Command, k8sClient *kubernetes.Clientset) (*analyzer.Analyzer, error) {
+	return startContextAnalyzer(ctx, cmd, k8sClient, "")
+}
+
+// startContextAnalyzer starts the analyzer of the cluster of the kubeconfig context, the
+// current one when empty, and generates a first report.
+func startContextAnalyzer(ctx context.Context, cmd *cli.Command, k8sClient *kubernetes.Clientset, kubeContext string) (*analyzer.Analyzer, error) {
+	// Checks the permissions before starting informers, which block on the denied ones.
+	namespaces, unanalyzed, err := preflight(ctx, cmd, k8sClient, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creates and starts the analyzer and generates the report.
+	analyzr, err := analyzer.New(k8sClient, namespaces, cmd.String(flagControllerClass), cmd.Bool(flagWatchWithoutClass), cmd.String(flagIngressClass), cmd.Bool(flagIngressClassByName))
+	if err != nil {
+		return nil, fmt.Errorf("creating analyzer: %w", err)
+	}
+	analyzr.SetUnanalyzedNamespaces(unanalyzed)
+
+	// The controller deployments are optional, their listing requiring permissions the analysis does not.
+	deployments, err := analyzer.DiscoverControllerDeployments(ctx, k8sClient)
+	if err != nil {
+		log.Warn().Err(err).Msg("Unable to discover the NGINX ingress controller deployments, the report has no Controller section")
+	}
+	analyzr.SetControllerDeployments(deployments)
+
+	if err = analyzr.Start(ctx); err != nil {
+		return nil, fmt.Errorf("starting analyzer: %w", err)
+	}
+
+	if err = analyzr.GenerateReport(); err != nil {
+		return nil, fmt.Errorf("generating report: %w", err)
+	}
+
+	return analyzr, nil
+}
+
+// openHistory opens the report history in dir and stores the given report.
+// It returns nil when dir is empty, the history being disabled.
+func openHistory(dir string, report analyzer.Report) (handlers.History, error) {
+	if dir == "" {
+		return nil, nil
+	}
+
+	store, err := history.Open(dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening history: %w", err)
+	}
+
+	if _, err := store.Save(report); err != nil {
+		return nil, fmt.Errorf("storing report in history: %w", err)
+	}
+
+	return store, nil
+}
+
+// newClient creates the client sending the anonymized report, configured from
+// the environment. When auditLog is set, every transmission is recorded in it.
+func newClient(auditLog string) (*client.Client, error) {
+	clt, err := client.New(os.Getenv("ENDPOINT_STATS_URL"), os.Getenv("ENDPOINT_STATS_TOKEN"), os.Getenv("CLUSTER_NAME"))
+	if err != nil {
+		return nil, fmt.Errorf("creating client: %w", err)
+	}
+
+	if auditLog != "" {
+		if err := clt.SetAuditLog(auditLog); err != nil {
+			return nil, err
+		}
+	}
+
+	return clt, nil
+}
+
 // oneShotOutput captures the validated configuration for a one-shot report
 // invocation. A nil *oneShotOutput means the tool runs in serve mode.
 type oneShotOutput struct {
@@ -258,8 +490,16 @@ func validateOutputFlags(format string, summary bool, outputFile string) error {
 
 // writeReport renders the report to outputFile, or to stdout when outputFile is empty.
 func writeReport(report analyzer.Report, format string, summary bool, outputFile string) error {
+	return writeOutput(outputFile, func(w io.Writer) error {
+		return render.Render(report, format, summary, w)
+	})
+}
+
+// writeOutput writes the output to the output file, or to stdout when the output file
+// is empty. The output file is removed when the output cannot be written.
+func writeOutput(outputFile string, write func(w io.Writer) error) error {
 	if outputFile == "" {
-		return render.Render(report, format, summary, os.Stdout)
+		return write(os.Stdout)
 	}
 
 	f, err := os.Create(outputFile)
@@ -267,7 +507,7 @@ func writeReport(report analyzer.Report, format string, summary bool, outputFile
 		return fmt.Errorf("creating output file: %w", err)
 	}
 
-	if err := render.Render(report, format, summary, f); err != nil {
+	if err := write(f); err != nil {
 		_ = f.Close()
 		_ = os.Remove(outputFile)
 		return err
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/multicluster"
+	"github.com/urfave/cli/v3"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// runMultiCluster analyzes the clusters of several kubeconfig contexts and writes or
+// serves their combined report.
+func runMultiCluster(ctx context.Context, cmd *cli.Command, oneShot *oneShotOutput) error {
+	if len(cmd.StringSlice(flagContext)) > 0 && cmd.Bool(flagAllContexts) {
+		return fmt.Errorf("--%s and --%s are mutually exclusive", flagContext, flagAllContexts)
+	}
+	for _, flag := range []string{flagHistoryDir, flagAuthUserHeader} {
+		if cmd.String(flag) != "" {
+			return fmt.Errorf("--%s is not supported with --%s or --%s", flag, flagContext, flagAllContexts)
+		}
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = cmd.String(flagKubeconfig)
+
+	kubeconfig, err := rules.Load()
+	if err != nil {
+		return fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	kubeContexts := cmd.StringSlice(flagContext)
+	if cmd.Bool(flagAllContexts) {
+		kubeContexts = slices.Sorted(maps.Keys(kubeconfig.Contexts))
+		if len(kubeContexts) == 0 {
+			return errors.New("no context in kubeconfig")
+		}
+	}
+
+	var unique []string
+	for _, kubeContext := range kubeContexts {
+		if !slices.Contains(unique, kubeContext) {
+			unique = append(unique, kubeContext)
+		}
+	}
+	kubeContexts = unique
+
+	log.Info().Msgf("Analyzing %d cluster(s): %s", len(kubeContexts), strings.Join(kubeContexts, ", "))
+
+	clusters := multicluster.Start(ctx, kubeContexts, cmd.Duration(flagContextTimeout), func(ctx context.Context, kubeContext string) (*analyzer.Analyzer, error) {
+		config, err := clientcmd.NewNonInteractiveClientConfig(*kubeconfig, kubeContext, &clientcmd.ConfigOverrides{}, rules).ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("creating config from context: %w", err)
+		}
+
+		if err := impersonate(cmd, config); err != nil {
+			return nil, err
+		}
+
+		k8sClient, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("creating k8s client from config: %w", err)
+		}
+
+		return startContextAnalyzer(ctx, cmd, k8sClient, kubeContext)
+	})
+
+	for _, cluster := range clusters {
+		if cluster.Err != nil {
+			log.Error().Err(cluster.Err).Str("context", cluster.Context).Msg("Cluster not analyzed")
+		}
+	}
+
+	// One-shot mode: write the combined report once and exit without serving.
+	if oneShot != nil {
+		return writeMultiClusterReport(multicluster.Combine(clusters), oneShot)
+	}
+
+	return serve(ctx, cmd.String(flagAddr), multicluster.Handler(clusters), "fleet report")
+}
+
+// writeMultiClusterReport writes the combined report to the output file, or to stdout
+// when the output file is empty.
+func writeMultiClusterReport(report multicluster.Report, oneShot *oneShotOutput) error {
+	return writeOutput(oneShot.outputFile, func(w io.Writer) error {
+		return multicluster.Write(w, report, oneShot.format, oneShot.summary)
+	})
+}
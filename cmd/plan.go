@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/traefik/ingress-nginx-migration/pkg/plan"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagGroupBy      = "group-by"
+	flagWaveSize     = "wave-size"
+	flagTraefikClass = "traefik-ingress-class"
+	flagOutputDir    = "output-dir"
+)
+
+func planCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "plan",
+		Usage: "Writes a cutover plan switching the compatible Ingresses to the Traefik IngressClass in waves",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagGroupBy,
+				Usage:   fmt.Sprintf("Defines how the Ingresses are grouped into waves (%q, %q or %q).", plan.GroupByNamespace, plan.GroupBySize, plan.GroupByVersion),
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagGroupBy)),
+				Value:   plan.GroupByNamespace,
+			},
+			&cli.IntFlag{
+				Name:    flagWaveSize,
+				Usage:   "Defines the maximum number of Ingresses per wave. When 0, the groups are not split. Required to group by size.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagWaveSize)),
+				Value:   10,
+			},
+			&cli.StringFlag{
+				Name:    flagTraefikClass,
+				Usage:   "Defines the Traefik IngressClass the Ingresses are switched to.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTraefikClass)),
+				Value:   "traefik",
+			},
+			&cli.StringFlag{
+				Name:    flagOutputDir,
+				Usage:   "Defines the directory where the plan and the patches of the waves are written.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagOutputDir)),
+				Value:   "cutover-plan",
+			},
+		},
+		Action: runPlan,
+	}
+}
+
+func runPlan(ctx context.Context, cmd *cli.Command) error {
+	logger.Setup("info", os.Stdout)
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	cutoverPlan, err := plan.New(analyzr.Report(), plan.Options{
+		GroupBy:      cmd.String(flagGroupBy),
+		WaveSize:     int(cmd.Int(flagWaveSize)),
+		IngressClass: cmd.String(flagTraefikClass),
+	})
+	if err != nil {
+		return fmt.Errorf("building cutover plan: %w", err)
+	}
+
+	outputDir := cmd.String(flagOutputDir)
+	if err := cutoverPlan.Write(outputDir); err != nil {
+		return fmt.Errorf("writing cutover plan: %w", err)
+	}
+
+	log.Info().Msgf("Cutover plan with %d wave(s) written to %s, %d unsupported Ingress(es) excluded", len(cutoverPlan.Waves), outputDir, len(cutoverPlan.Excluded))
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/access"
+	"github.com/urfave/cli/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Handling of the namespaces whose Ingresses cannot be read.
+const (
+	inaccessibleFail = "fail"
+	inaccessibleSkip = "skip"
+)
+
+// preflight checks the permissions the analyzer needs with SelfSubjectAccessReviews,
+// and writes them as a table to stderr. It returns the namespaces to analyze, all of
+// them when empty, and the inaccessible namespaces skipped.
+func preflight(ctx context.Context, cmd *cli.Command, k8sClient kubernetes.Interface, kubeContext string) ([]string, []string, error) {
+	mode := cmd.String(flagInaccessible)
+	if mode != inaccessibleFail && mode != inaccessibleSkip {
+		return nil, nil, fmt.Errorf("--%s must be %q or %q, got %q", flagInaccessible, inaccessibleFail, inaccessibleSkip, mode)
+	}
+
+	namespaces := slices.Clone(cmd.StringSlice(flagNamespaces))
+
+	requirements := access.Requirements(namespaces)
+	if cmd.String(flagAuthUserHeader) != "" {
+		requirements = append(requirements, access.Permission{Group: "authorization.k8s.io", Resource: "subjectaccessreviews", Verb: "create"})
+	}
+
+	permissions, err := access.Check(ctx, k8sClient, requirements)
+	if err != nil {
+		return nil, nil, fmt.Errorf("checking permissions: %w", err)
+	}
+	denied := access.DeniedNamespaces(permissions)
+
+	// The Ingresses of all namespaces cannot be read: the namespaces are checked one by one.
+	if len(namespaces) == 0 && len(denied) > 0 && mode == inaccessibleSkip {
+		list, err := k8sClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("the Ingresses of all namespaces cannot be read, and listing the namespaces to skip the inaccessible ones failed, set --%s: %w", flagNamespaces, err)
+		}
+
+		for _, namespace := range list.Items {
+			namespaces = append(namespaces, namespace.Name)
+		}
+		slices.Sort(namespaces)
+
+		namespacePermissions, err := access.Check(ctx, k8sClient, access.Requirements(namespaces))
+		if err != nil {
+			return nil, nil, fmt.Errorf("checking permissions: %w", err)
+		}
+
+		// The IngressClass permissions are already checked.
+		permissions = append(permissions, slices.DeleteFunc(namespacePermissions, func(p access.Permission) bool { return !p.Namespaced })...)
+		denied = access.DeniedNamespaces(namespacePermissions)
+	}
+
+	title := "Permissions"
+	if kubeContext != "" {
+		title += " in context " + kubeContext
+	}
+	if user := cmd.String(flagAs); user != "" {
+		title += " as " + user
+	}
+	// The title and the table are written at once, the contexts being checked concurrently.
+	var buf bytes.Buffer
+	buf.WriteString(title + ":\n")
+	if err := access.WriteTable(&buf, permissions); err != nil {
+		return nil, nil, err
+	}
+	_, _ = os.Stderr.Write(buf.Bytes())
+
+	if missing := access.DeniedClusterPermissions(permissions); len(missing) > 0 {
+		var names []string
+		for _, permission := range missing {
+			names = append(names, permission.String())
+		}
+		return nil, nil, fmt.Errorf("missing cluster-wide permissions: %s", strings.Join(names, ", "))
+	}
+
+	if len(denied) == 0 {
+		return namespaces, nil, nil
+	}
+
+	if slices.Contains(denied, metav1.NamespaceAll) {
+		return nil, nil, fmt.Errorf("the Ingresses of all namespaces cannot be read: grant the missing permissions, set --%s, or set --%s %s", flagNamespaces, flagInaccessible, inaccessibleSkip)
+	}
+	if mode == inaccessibleFail {
+		return nil, nil, fmt.Errorf("the Ingresses of the namespaces %s cannot be read: grant the missing permissions, or set --%s %s", strings.Join(denied, ", "), flagInaccessible, inaccessibleSkip)
+	}
+
+	namespaces = slices.DeleteFunc(namespaces, func(namespace string) bool { return slices.Contains(denied, namespace) })
+	if len(namespaces) == 0 {
+		return nil, nil, errors.New("the Ingresses of none of the namespaces can be read: grant the missing permissions")
+	}
+
+	log.Warn().Strs("namespaces", denied).Msg("Skipping the namespaces whose Ingresses cannot be read")
+
+	return namespaces, denied, nil
+}
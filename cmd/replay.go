@@ -0,0 +1,173 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/traefik/ingress-nginx-migration/pkg/replay"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagAccessLog          = "access-log"
+	flagLogFormat          = "log-format"
+	flagOldURL             = "old-url"
+	flagNewURL             = "new-url"
+	flagDefaultHost        = "default-host"
+	flagMethods            = "methods"
+	flagCompareHeaders     = "compare-headers"
+	flagLimit              = "limit"
+	flagConcurrency        = "concurrency"
+	flagTimeout            = "timeout"
+	flagInsecureSkipVerify = "insecure-skip-verify"
+)
+
+func replayCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "replay",
+		Usage: "Replays the requests of NGINX access logs against the old and new controllers and compares their responses, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagAccessLog,
+				Usage:    "Defines the NGINX access log file the requests are read from, '-' for stdin.",
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagAccessLog)),
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagLogFormat,
+				Usage:   "Defines the NGINX log format of the access logs. When empty, the default log-format-upstream of the NGINX ingress controller is used.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagLogFormat)),
+			},
+			&cli.StringFlag{
+				Name:     flagOldURL,
+				Usage:    "Defines the base URL of the old controller, such as 'http://ingress-nginx-controller.ingress-nginx'.",
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagOldURL)),
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     flagNewURL,
+				Usage:    "Defines the base URL of the new controller, such as 'http://traefik.traefik'.",
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagNewURL)),
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagDefaultHost,
+				Usage:   "Defines the host of the requests whose host is not logged, as with the default log format. When empty, these requests are skipped.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagDefaultHost)),
+			},
+			&cli.StringSliceFlag{
+				Name:    flagMethods,
+				Usage:   "Defines the methods of the replayed requests. The requests are replayed without their body.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagMethods)),
+				Value:   []string{http.MethodGet, http.MethodHead},
+			},
+			&cli.StringSliceFlag{
+				Name:    flagCompareHeaders,
+				Usage:   "Defines the response headers compared, besides the status and the Location.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagCompareHeaders)),
+				Value:   []string{"Content-Type"},
+			},
+			&cli.IntFlag{
+				Name:    flagLimit,
+				Usage:   "Defines the maximum number of distinct requests replayed. When 0, every request is replayed.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagLimit)),
+				Value:   1000,
+			},
+			&cli.IntFlag{
+				Name:    flagConcurrency,
+				Usage:   "Defines the number of requests replayed at once.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagConcurrency)),
+				Value:   4,
+			},
+			&cli.DurationFlag{
+				Name:    flagTimeout,
+				Usage:   "Defines the timeout of each replayed request.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTimeout)),
+				Value:   10 * time.Second,
+			},
+			&cli.BoolFlag{
+				Name:    flagInsecureSkipVerify,
+				Usage:   "Defines if the certificates of the controllers are not verified.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagInsecureSkipVerify)),
+			},
+		},
+		Action: runReplay,
+	}
+}
+
+func runReplay(ctx context.Context, cmd *cli.Command) error {
+	format := cmp.Or(cmd.String(flagFormat), replay.FormatMarkdown)
+	if format != replay.FormatJSON && format != replay.FormatMarkdown {
+		return fmt.Errorf("invalid --%s %q (must be %q or %q)", flagFormat, format, replay.FormatJSON, replay.FormatMarkdown)
+	}
+
+	// Stdout is reserved for the report, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	logFormat, err := replay.ParseLogFormat(cmp.Or(cmd.String(flagLogFormat), replay.DefaultLogFormat))
+	if err != nil {
+		return err
+	}
+
+	var accessLog io.Reader = os.Stdin
+	if path := cmd.String(flagAccessLog); path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("opening access logs: %w", err)
+		}
+		defer func() { _ = file.Close() }()
+		accessLog = file
+	}
+
+	var methods []string
+	for _, method := range cmd.StringSlice(flagMethods) {
+		methods = append(methods, strings.ToUpper(method))
+	}
+
+	requests, skipped, err := replay.ReadRequests(accessLog, logFormat, replay.ReadOptions{
+		DefaultHost: cmd.String(flagDefaultHost),
+		Methods:     methods,
+		Limit:       int(cmd.Int(flagLimit)),
+	})
+	if err != nil {
+		return err
+	}
+	if skipped.NoHost > 0 && cmd.String(flagDefaultHost) == "" {
+		log.Warn().Msgf("%d request(s) without logged host skipped, set --%s or log the host", skipped.NoHost, flagDefaultHost)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cmd.Bool(flagInsecureSkipVerify) {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // Explicitly requested.
+	}
+	client := replay.NewClient(transport)
+	client.Timeout = cmd.Duration(flagTimeout)
+
+	opts := replay.Options{
+		OldURL:      cmd.String(flagOldURL),
+		NewURL:      cmd.String(flagNewURL),
+		Headers:     cmd.StringSlice(flagCompareHeaders),
+		Concurrency: int(cmd.Int(flagConcurrency)),
+		Client:      client,
+	}
+
+	results, err := replay.Replay(ctx, requests, opts)
+	if err != nil {
+		return fmt.Errorf("replaying requests: %w", err)
+	}
+
+	report := replay.NewReport(opts, skipped, results)
+	log.Info().Msgf("%d request(s) replayed, %d with different responses", len(results), report.Mismatches)
+
+	return replay.Write(os.Stdout, report, format)
+}
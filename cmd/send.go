@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/client"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagDryRun  = "dry-run"
+	flagConfirm = "confirm"
+)
+
+func sendCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "send",
+		Usage: "Sends the anonymized report statistics once, without serving the HTML report",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    flagDryRun,
+				Usage:   "Print the exact payload to stdout and send nothing.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagDryRun)),
+			},
+			&cli.BoolFlag{
+				Name:    flagConfirm,
+				Usage:   "Confirm the transmission of the payload. Required to send the report.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagConfirm)),
+			},
+		},
+		Action: send,
+	}
+}
+
+func send(ctx context.Context, cmd *cli.Command) error {
+	dryRun := cmd.Bool(flagDryRun)
+	confirm := cmd.Bool(flagConfirm)
+
+	switch {
+	case dryRun && confirm:
+		return fmt.Errorf("--%s and --%s are mutually exclusive", flagDryRun, flagConfirm)
+	case !dryRun && !confirm:
+		return fmt.Errorf("refusing to send the report without --%s, use --%s to preview the payload", flagConfirm, flagDryRun)
+	}
+
+	// Stdout is reserved for the dry-run payload, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	// Nothing is sent in dry run, so no record is kept.
+	auditLog := cmd.String(flagAuditLog)
+	if dryRun {
+		auditLog = ""
+	}
+
+	clt, err := newClient(auditLog)
+	if err != nil {
+		return err
+	}
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	payload := handlers.NewReportPayload(analyzr.Report())
+
+	if dryRun {
+		data, err := client.MarshalReport(payload)
+		if err != nil {
+			return err
+		}
+
+		log.Info().Msgf("Dry run: the following payload would be sent to %s", clt.EndpointURL())
+
+		if _, err := fmt.Fprintln(os.Stdout, string(data)); err != nil {
+			return fmt.Errorf("printing payload: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := clt.SendReport(payload); err != nil {
+		return fmt.Errorf("sending report: %w", err)
+	}
+
+	log.Info().Msgf("Report sent to %s", clt.EndpointURL())
+
+	return nil
+}
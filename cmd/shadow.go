@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/traefik/ingress-nginx-migration/pkg/shadow"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagHostSuffix        = "host-suffix"
+	flagReplaceHostSuffix = "replace-host-suffix"
+	flagNameSuffix        = "name-suffix"
+)
+
+func shadowCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "shadow",
+		Usage: "Clones the analyzed Ingresses onto rehearsal hosts with the Traefik IngressClass, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagHostSuffix,
+				Usage:    "Defines the suffix appended to the hosts of the clones, such as '.traefik.local'.",
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagHostSuffix)),
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    flagReplaceHostSuffix,
+				Usage:   "Defines the suffix of the hosts replaced by --host-suffix, such as '.example.com'. The other hosts are suffixed.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagReplaceHostSuffix)),
+			},
+			&cli.StringFlag{
+				Name:    flagNameSuffix,
+				Usage:   "Defines the suffix appended to the names of the clones.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagNameSuffix)),
+				Value:   "-shadow",
+			},
+			&cli.StringFlag{
+				Name:    flagTraefikClass,
+				Usage:   "Defines the Traefik IngressClass of the clones.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTraefikClass)),
+				Value:   "traefik",
+			},
+		},
+		Action: runShadow,
+	}
+}
+
+func runShadow(ctx context.Context, cmd *cli.Command) error {
+	// Stdout is reserved for the generated manifests, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return err
+	}
+
+	ingresses, err := analyzr.Ingresses()
+	if err != nil {
+		return fmt.Errorf("listing analyzed Ingresses: %w", err)
+	}
+
+	shadows, err := shadow.New(ingresses, shadow.Options{
+		HostSuffix:        cmd.String(flagHostSuffix),
+		ReplaceHostSuffix: cmd.String(flagReplaceHostSuffix),
+		NameSuffix:        cmd.String(flagNameSuffix),
+		IngressClass:      cmd.String(flagTraefikClass),
+	})
+	if err != nil {
+		return fmt.Errorf("cloning Ingresses: %w", err)
+	}
+
+	return shadow.WriteManifests(os.Stdout, shadows)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/traefik/ingress-nginx-migration/pkg/simulate"
+	"github.com/urfave/cli/v3"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	flagRequests  = "requests"
+	flagManifests = "manifests"
+)
+
+func simulateCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "simulate",
+		Usage: "Compares the routes NGINX and Traefik pick for sample requests, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     flagRequests,
+				Usage:    "Defines the YAML or JSON file listing the sample requests, with their method, host, path and headers.",
+				Sources:  cli.EnvVars(strcase.ToSNAKE(flagRequests)),
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:    flagManifests,
+				Usage:   "Defines the YAML or JSON files the Ingresses are read from, instead of analyzing the cluster.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagManifests)),
+			},
+		},
+		Action: runSimulate,
+	}
+}
+
+func runSimulate(ctx context.Context, cmd *cli.Command) error {
+	format := cmp.Or(cmd.String(flagFormat), simulate.FormatMarkdown)
+	if format != simulate.FormatJSON && format != simulate.FormatMarkdown {
+		return fmt.Errorf("invalid --%s %q (must be %q or %q)", flagFormat, format, simulate.FormatJSON, simulate.FormatMarkdown)
+	}
+
+	// Stdout is reserved for the results, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	data, err := os.ReadFile(cmd.String(flagRequests))
+	if err != nil {
+		return fmt.Errorf("reading requests: %w", err)
+	}
+
+	requests, err := simulate.ReadRequests(data)
+	if err != nil {
+		return err
+	}
+
+	ingresses, err := simulatedIngresses(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	analyzer.SortByCreation(ingresses)
+
+	results := simulate.New(ingresses).Simulate(requests)
+
+	var mismatches int
+	for _, result := range results {
+		if len(result.Mismatches) > 0 {
+			mismatches++
+		}
+	}
+	log.Info().Msgf("%d request(s) simulated against %d Ingress(es), %d mismatch(es)", len(results), len(ingresses), mismatches)
+
+	return simulate.Write(os.Stdout, results, format)
+}
+
+// simulatedIngresses returns the Ingresses of the manifests when given, without
+// connecting to the cluster, and the analyzed Ingresses otherwise.
+func simulatedIngresses(ctx context.Context, cmd *cli.Command) ([]*netv1.Ingress, error) {
+	manifests := cmd.StringSlice(flagManifests)
+	if len(manifests) == 0 {
+		k8sClient, err := newKubernetesClient(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+
+		ingresses, err := analyzr.Ingresses()
+		if err != nil {
+			return nil, fmt.Errorf("listing analyzed Ingresses: %w", err)
+		}
+		return ingresses, nil
+	}
+
+	var ingresses []*netv1.Ingress
+	for _, manifest := range manifests {
+		file, err := os.Open(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("opening manifests: %w", err)
+		}
+
+		fileIngresses, err := simulate.ReadIngresses(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", manifest, err)
+		}
+		ingresses = append(ingresses, fileIngresses...)
+	}
+
+	return ingresses, nil
+}
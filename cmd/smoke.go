@@ -0,0 +1,142 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ettle/strcase"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/logger"
+	"github.com/traefik/ingress-nginx-migration/pkg/smoke"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagTarget    = "target"
+	flagSuite     = "suite"
+	flagHTTPPort  = "http-port"
+	flagHTTPSPort = "https-port"
+)
+
+func smokeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "smoke",
+		Usage: "Generates HTTP probes from the Ingress rules as a YAML suite, or runs them against a controller, written to stdout",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    flagTarget,
+				Usage:   "Defines the address of the controller the probes are run against, such as 'traefik.traefik'. When empty, the suite is written instead.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTarget)),
+			},
+			&cli.StringFlag{
+				Name:    flagSuite,
+				Usage:   "Defines the YAML suite file run against the target, instead of generating the probes from the analyzed Ingresses.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagSuite)),
+			},
+			&cli.IntFlag{
+				Name:    flagHTTPPort,
+				Usage:   "Defines the HTTP port of the target.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagHTTPPort)),
+				Value:   80,
+			},
+			&cli.IntFlag{
+				Name:    flagHTTPSPort,
+				Usage:   "Defines the HTTPS port of the target.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagHTTPSPort)),
+				Value:   443,
+			},
+			&cli.IntFlag{
+				Name:    flagConcurrency,
+				Usage:   "Defines the number of probes run at once.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagConcurrency)),
+				Value:   4,
+			},
+			&cli.DurationFlag{
+				Name:    flagTimeout,
+				Usage:   "Defines the timeout of each probe.",
+				Sources: cli.EnvVars(strcase.ToSNAKE(flagTimeout)),
+				Value:   10 * time.Second,
+			},
+		},
+		Action: runSmoke,
+	}
+}
+
+func runSmoke(ctx context.Context, cmd *cli.Command) error {
+	target := cmd.String(flagTarget)
+
+	format := cmp.Or(cmd.String(flagFormat), smoke.FormatMarkdown)
+	if target != "" && format != smoke.FormatJSON && format != smoke.FormatMarkdown {
+		return fmt.Errorf("invalid --%s %q (must be %q or %q)", flagFormat, format, smoke.FormatJSON, smoke.FormatMarkdown)
+	}
+
+	// Stdout is reserved for the suite or the report, so logs go to stderr.
+	logger.Setup("info", os.Stderr)
+
+	suite, err := smokeSuite(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	if target == "" {
+		log.Info().Msgf("%d probe(s) generated", len(suite.Probes))
+		return smoke.WriteSuite(os.Stdout, suite)
+	}
+
+	results := smoke.Run(ctx, suite, smoke.RunOptions{
+		Address:     target,
+		HTTPPort:    int(cmd.Int(flagHTTPPort)),
+		HTTPSPort:   int(cmd.Int(flagHTTPSPort)),
+		Timeout:     cmd.Duration(flagTimeout),
+		Concurrency: int(cmd.Int(flagConcurrency)),
+	})
+
+	report := smoke.NewReport(target, results)
+	if err := smoke.Write(os.Stdout, report, format); err != nil {
+		return err
+	}
+
+	if report.Failed > 0 {
+		return fmt.Errorf("%d probe(s) failed", report.Failed)
+	}
+
+	log.Info().Msgf("%d probe(s) passed", report.Passed)
+	return nil
+}
+
+// smokeSuite returns the suite of the suite file when given, and the suite generated
+// from the analyzed Ingresses otherwise.
+func smokeSuite(ctx context.Context, cmd *cli.Command) (smoke.Suite, error) {
+	if path := cmd.String(flagSuite); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return smoke.Suite{}, fmt.Errorf("reading suite: %w", err)
+		}
+		return smoke.ReadSuite(data)
+	}
+
+	k8sClient, err := newKubernetesClient(cmd)
+	if err != nil {
+		return smoke.Suite{}, err
+	}
+
+	analyzr, err := startAnalyzer(ctx, cmd, k8sClient)
+	if err != nil {
+		return smoke.Suite{}, err
+	}
+
+	ingresses, err := analyzr.Ingresses()
+	if err != nil {
+		return smoke.Suite{}, fmt.Errorf("listing analyzed Ingresses: %w", err)
+	}
+
+	suite := smoke.Generate(ingresses)
+	for _, note := range suite.Notes {
+		log.Warn().Msg(note)
+	}
+
+	return suite, nil
+}
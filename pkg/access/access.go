@@ -0,0 +1,102 @@
+// Package access checks which namespaces a user is allowed to see Ingresses in,
+// using SubjectAccessReviews.
+package access
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultCacheTTL is how long an access review result is reused.
+const defaultCacheTTL = time.Minute
+
+type cacheEntry struct {
+	allowed   bool
+	expiresAt time.Time
+}
+
+// Reviewer reviews whether users can list Ingresses in namespaces.
+// Review results are cached to avoid a SubjectAccessReview per namespace on
+// every request.
+type Reviewer struct {
+	k8sClient kubernetes.Interface
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewReviewer creates a new Reviewer.
+func NewReviewer(k8sClient kubernetes.Interface) *Reviewer {
+	return &Reviewer{
+		k8sClient: k8sClient,
+		ttl:       defaultCacheTTL,
+		now:       time.Now,
+		cache:     make(map[string]cacheEntry),
+	}
+}
+
+// AllowedNamespaces returns the namespaces, among the given ones, in which the
+// user, member of groups, is allowed to list Ingresses.
+func (r *Reviewer) AllowedNamespaces(ctx context.Context, user string, groups, namespaces []string) (map[string]bool, error) {
+	allowed := make(map[string]bool, len(namespaces))
+
+	for _, namespace := range namespaces {
+		ok, err := r.allowed(ctx, user, groups, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		allowed[namespace] = ok
+	}
+
+	return allowed, nil
+}
+
+func (r *Reviewer) allowed(ctx context.Context, user string, groups []string, namespace string) (bool, error) {
+	sortedGroups := slices.Sorted(slices.Values(groups))
+	key := user + "\x00" + strings.Join(sortedGroups, "\x00") + "\x00\x00" + namespace
+
+	now := r.now()
+
+	r.mu.Lock()
+	entry, ok := r.cache[key]
+	r.mu.Unlock()
+
+	if ok && now.Before(entry.expiresAt) {
+		return entry.allowed, nil
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user,
+			Groups: sortedGroups,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     "networking.k8s.io",
+				Resource:  "ingresses",
+			},
+		},
+	}
+
+	review, err := r.k8sClient.AuthorizationV1().SubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf("reviewing access of %q to namespace %q: %w", user, namespace, err)
+	}
+
+	r.mu.Lock()
+	r.cache[key] = cacheEntry{allowed: review.Status.Allowed, expiresAt: now.Add(r.ttl)}
+	r.mu.Unlock()
+
+	return review.Status.Allowed, nil
+}
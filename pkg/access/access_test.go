@@ -0,0 +1,58 @@
+package access
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReviewer_AllowedNamespaces(t *testing.T) {
+	t.Parallel()
+
+	var reviews []authorizationv1.SubjectAccessReviewSpec
+
+	k8sClient := fake.NewClientset()
+	k8sClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		reviews = append(reviews, review.Spec)
+
+		// Alice can list ingresses in team-a, the team-b group in team-b.
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = (review.Spec.User == "alice" && attrs.Namespace == "team-a") ||
+			(attrs.Namespace == "team-b" && len(review.Spec.Groups) > 0 && review.Spec.Groups[0] == "team-b")
+
+		return true, review, nil
+	})
+
+	reviewer := NewReviewer(k8sClient)
+
+	now := time.Date(2026, 5, 27, 10, 0, 0, 0, time.UTC)
+	reviewer.now = func() time.Time { return now }
+
+	allowed, err := reviewer.AllowedNamespaces(t.Context(), "alice", []string{"team-b"}, []string{"team-a", "team-b", "team-c"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"team-a": true, "team-b": true, "team-c": false}, allowed)
+
+	require.Len(t, reviews, 3)
+	assert.Equal(t, "list", reviews[0].ResourceAttributes.Verb)
+	assert.Equal(t, "networking.k8s.io", reviews[0].ResourceAttributes.Group)
+	assert.Equal(t, "ingresses", reviews[0].ResourceAttributes.Resource)
+
+	// Results are cached.
+	_, err = reviewer.AllowedNamespaces(t.Context(), "alice", []string{"team-b"}, []string{"team-a"})
+	require.NoError(t, err)
+	assert.Len(t, reviews, 3)
+
+	// Until they expire.
+	now = now.Add(2 * defaultCacheTTL)
+	allowed, err = reviewer.AllowedNamespaces(t.Context(), "bob", nil, []string{"team-a"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"team-a": false}, allowed)
+	assert.Len(t, reviews, 4)
+}
@@ -0,0 +1,160 @@
+package access
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// analyzerVerbs are the verbs the informers of the analyzer need, which read the
+// objects from their cache and never get them.
+var analyzerVerbs = []string{"list", "watch"}
+
+// Permission is an access to a resource, and whether the current user has it.
+type Permission struct {
+	Group    string
+	Resource string
+	Verb     string
+	// Namespaced is true for the resources of a namespace, of all namespaces when
+	// Namespace is empty.
+	Namespaced bool
+	Namespace  string
+
+	Allowed bool
+	// Reason is why the access is allowed or denied, when the authorizer tells.
+	Reason string
+}
+
+// Requirements returns the permissions the analyzer needs to analyze the Ingresses of
+// the namespaces, of all namespaces when empty.
+func Requirements(namespaces []string) []Permission {
+	var permissions []Permission
+	for _, verb := range analyzerVerbs {
+		permissions = append(permissions, Permission{Group: "networking.k8s.io", Resource: "ingressclasses", Verb: verb})
+	}
+
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+	for _, namespace := range namespaces {
+		for _, verb := range analyzerVerbs {
+			permissions = append(permissions, Permission{Group: "networking.k8s.io", Resource: "ingresses", Verb: verb, Namespaced: true, Namespace: namespace})
+		}
+	}
+
+	return permissions
+}
+
+// Check reviews the permissions of the current user, or of the user it impersonates,
+// with SelfSubjectAccessReviews.
+func Check(ctx context.Context, k8sClient kubernetes.Interface, permissions []Permission) ([]Permission, error) {
+	checked := make([]Permission, 0, len(permissions))
+
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: permission.Namespace,
+					Verb:      permission.Verb,
+					Group:     permission.Group,
+					Resource:  permission.Resource,
+				},
+			},
+		}
+
+		review, err := k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing access to %s: %w", permission, err)
+		}
+
+		permission.Allowed = review.Status.Allowed
+		permission.Reason = review.Status.Reason
+		if permission.Reason == "" {
+			permission.Reason = review.Status.EvaluationError
+		}
+
+		checked = append(checked, permission)
+	}
+
+	return checked, nil
+}
+
+// DeniedNamespaces returns the namespaces in which a namespaced permission is denied,
+// in order. The empty namespace stands for all namespaces.
+func DeniedNamespaces(permissions []Permission) []string {
+	var namespaces []string
+	for _, permission := range permissions {
+		if permission.Namespaced && !permission.Allowed && !slices.Contains(namespaces, permission.Namespace) {
+			namespaces = append(namespaces, permission.Namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// DeniedClusterPermissions returns the denied permissions which are not namespaced.
+func DeniedClusterPermissions(permissions []Permission) []Permission {
+	var denied []Permission
+	for _, permission := range permissions {
+		if !permission.Namespaced && !permission.Allowed {
+			denied = append(denied, permission)
+		}
+	}
+
+	return denied
+}
+
+// WriteTable writes the permissions as a table.
+func WriteTable(w io.Writer, permissions []Permission) error {
+	// The table is written at once, so that the tables of concurrent checks do not interleave.
+	var buf bytes.Buffer
+
+	tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "RESOURCE\tVERB\tNAMESPACE\tALLOWED\tREASON")
+	for _, permission := range permissions {
+		allowed := "no"
+		if permission.Allowed {
+			allowed = "yes"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s.%s\t%s\t%s\t%s\t%s\n", permission.Resource, permission.Group, permission.Verb, permission.scope(), allowed, cmp.Or(permission.Reason, "-"))
+	}
+	_ = tw.Flush()
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing permissions: %w", err)
+	}
+
+	return nil
+}
+
+// String returns the permission as "verb resource.group", followed by its namespaces.
+func (p Permission) String() string {
+	switch {
+	case !p.Namespaced:
+		return fmt.Sprintf("%s %s.%s", p.Verb, p.Resource, p.Group)
+	case p.Namespace == metav1.NamespaceAll:
+		return fmt.Sprintf("%s %s.%s in all namespaces", p.Verb, p.Resource, p.Group)
+	default:
+		return fmt.Sprintf("%s %s.%s in namespace %s", p.Verb, p.Resource, p.Group, p.Namespace)
+	}
+}
+
+func (p Permission) scope() string {
+	switch {
+	case !p.Namespaced:
+		return "(cluster)"
+	case p.Namespace == metav1.NamespaceAll:
+		return "(all)"
+	default:
+		return p.Namespace
+	}
+}
@@ -0,0 +1,98 @@
+package access
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRequirements(t *testing.T) {
+	t.Parallel()
+
+	permissions := Requirements(nil)
+	require.Len(t, permissions, 4)
+	assert.Equal(t, Permission{Group: "networking.k8s.io", Resource: "ingressclasses", Verb: "list"}, permissions[0])
+	assert.Equal(t, Permission{Group: "networking.k8s.io", Resource: "ingresses", Verb: "watch", Namespaced: true}, permissions[3])
+
+	permissions = Requirements([]string{"team-a", "team-b"})
+	require.Len(t, permissions, 6)
+	assert.Equal(t, "team-a", permissions[2].Namespace)
+	assert.Equal(t, "team-b", permissions[5].Namespace)
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	k8sClient := fake.NewClientset()
+	k8sClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+
+		// The Ingresses of team-b cannot be watched.
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Namespace != "team-b" || attrs.Verb != "watch"
+		if !review.Status.Allowed {
+			review.Status.Reason = "no RBAC policy matched"
+		}
+
+		return true, review, nil
+	})
+
+	permissions, err := Check(t.Context(), k8sClient, Requirements([]string{"team-a", "team-b"}))
+	require.NoError(t, err)
+	require.Len(t, permissions, 6)
+
+	assert.True(t, permissions[0].Allowed)
+	assert.False(t, permissions[5].Allowed)
+	assert.Equal(t, "no RBAC policy matched", permissions[5].Reason)
+
+	assert.Equal(t, []string{"team-b"}, DeniedNamespaces(permissions))
+	assert.Empty(t, DeniedClusterPermissions(permissions))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, permissions[4:]))
+	assert.Equal(t, `RESOURCE                     VERB   NAMESPACE  ALLOWED  REASON
+ingresses.networking.k8s.io  list   team-b     yes      -
+ingresses.networking.k8s.io  watch  team-b     no       no RBAC policy matched
+`, buf.String())
+}
+
+func TestCheck_ClusterPermissions(t *testing.T) {
+	t.Parallel()
+
+	k8sClient := fake.NewClientset()
+	k8sClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = review.Spec.ResourceAttributes.Resource == "ingresses"
+
+		return true, review, nil
+	})
+
+	permissions, err := Check(t.Context(), k8sClient, Requirements(nil))
+	require.NoError(t, err)
+
+	assert.Empty(t, DeniedNamespaces(permissions))
+
+	denied := DeniedClusterPermissions(permissions)
+	require.Len(t, denied, 2)
+	assert.Equal(t, "list ingressclasses.networking.k8s.io", denied[0].String())
+	assert.Equal(t, "watch ingresses.networking.k8s.io in all namespaces", permissions[3].String())
+}
+
+func TestCheck_Error(t *testing.T) {
+	t.Parallel()
+
+	k8sClient := fake.NewClientset()
+	k8sClient.PrependReactor("create", "selfsubjectaccessreviews", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	_, err := Check(t.Context(), k8sClient, Requirements([]string{"team-a"}))
+	require.EqualError(t, err, "reviewing access to list ingressclasses.networking.k8s.io: connection refused")
+}
@@ -1,8 +1,10 @@
 package analyzer
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -27,18 +29,26 @@ type Analyzer struct {
 	watchIngressWithoutClass bool
 	ingressClassByName       bool
 
+	// namespaces are the analyzed namespaces, all of them when empty.
+	namespaces []string
+
 	clusterFactory kinformers.SharedInformerFactory
 	nsFactories    []kinformers.SharedInformerFactory
 
 	ingressListers     []listersnetv1.IngressLister
 	ingressClassLister listersnetv1.IngressClassLister
 
+	unanalyzedNamespaces  []string
+	controllerDeployments []ControllerDeployment
+
 	reportMu sync.RWMutex
 	report   Report
 }
 
 // New creates a new Analyzer.
 func New(k8sClient *kubernetes.Clientset, namespaces []string, controllerClass string, watchIngressWithoutClass bool, ingressClass string, ingressClassByName bool) (*Analyzer, error) {
+	analyzed := slices.Clone(namespaces)
+
 	// When namespaces list is empty all namespaces are listed.
 	if len(namespaces) == 0 {
 		namespaces = []string{v1.NamespaceAll}
@@ -76,6 +86,7 @@ func New(k8sClient *kubernetes.Clientset, namespaces []string, controllerClass s
 		controllerClass:          controllerClass,
 		watchIngressWithoutClass: watchIngressWithoutClass,
 		ingressClassByName:       ingressClassByName,
+		namespaces:               analyzed,
 		clusterFactory:           clusterFactory,
 		nsFactories:              nsFactories,
 		ingressListers:           ingressListers,
@@ -83,6 +94,16 @@ func New(k8sClient *kubernetes.Clientset, namespaces []string, controllerClass s
 	}, nil
 }
 
+// Namespaces returns the analyzed namespaces, all of them when empty.
+func (a *Analyzer) Namespaces() []string {
+	return a.namespaces
+}
+
+// SetUnanalyzedNamespaces sets the namespaces left out of the analysis, listed in the report.
+func (a *Analyzer) SetUnanalyzedNamespaces(namespaces []string) {
+	a.unanalyzedNamespaces = slices.Sorted(slices.Values(namespaces))
+}
+
 // Start starts the analyzer informers and waits for their caches to sync.
 // This method blocks until the caches are synced or the context is done.
 func (a *Analyzer) Start(ctx context.Context) error {
@@ -109,28 +130,61 @@ func (a *Analyzer) Start(ctx context.Context) error {
 
 // GenerateReport generates the analysis report.
 func (a *Analyzer) GenerateReport() error {
+	ingressClasses, ingresses, err := a.list()
+	if err != nil {
+		return err
+	}
+
+	report := a.computeReport(ingressClasses, ingresses)
+
+	a.reportMu.Lock()
+	a.report = report
+	a.reportMu.Unlock()
+
+	return nil
+}
+
+// Ingresses returns the Ingresses handled by the NGINX ingress controller, as
+// analyzed in the report, sorted by namespace then name.
+func (a *Analyzer) Ingresses() ([]*netv1.Ingress, error) {
+	ingressClasses, ingresses, err := a.list()
+	if err != nil {
+		return nil, err
+	}
+
+	nginxIngressClasses := a.nginxIngressClasses(ingressClasses)
+
+	var nginxIngresses []*netv1.Ingress
+	for _, ing := range ingresses {
+		if ok, _ := a.shouldProcessIngress(ing, nginxIngressClasses); ok {
+			nginxIngresses = append(nginxIngresses, ing)
+		}
+	}
+
+	slices.SortFunc(nginxIngresses, func(a, b *netv1.Ingress) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	return nginxIngresses, nil
+}
+
+func (a *Analyzer) list() ([]*netv1.IngressClass, []*netv1.Ingress, error) {
 	ingressClasses, err := a.ingressClassLister.List(labels.Everything())
 	if err != nil {
-		return fmt.Errorf("listing IngressClasses: %w", err)
+		return nil, nil, fmt.Errorf("listing IngressClasses: %w", err)
 	}
 
 	var ingresses []*netv1.Ingress
 	for _, ingressLister := range a.ingressListers {
 		nsIngresses, err := ingressLister.List(labels.Everything())
 		if err != nil {
-			return fmt.Errorf("listing Ingresses: %w", err)
+			return nil, nil, fmt.Errorf("listing Ingresses: %w", err)
 		}
 
 		ingresses = append(ingresses, nsIngresses...)
 	}
 
-	report := a.computeReport(ingressClasses, ingresses)
-
-	a.reportMu.Lock()
-	a.report = report
-	a.reportMu.Unlock()
-
-	return nil
+	return ingressClasses, ingresses, nil
 }
 
 // Report returns the analysis report.
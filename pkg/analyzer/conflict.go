@@ -0,0 +1,371 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// Kinds of path conflict.
+const (
+	// PathDuplicate is a host and path defined by several Ingresses.
+	PathDuplicate = "duplicate"
+	// PathShadowed is a path whose requests are partly routed to a more specific path
+	// of another Ingress, the same way by NGINX and Traefik.
+	PathShadowed = "shadowed"
+	// PathPriority is a path that NGINX and Traefik prefer differently over the path
+	// of another Ingress.
+	PathPriority = "priority"
+	// PathRegexOrder is a path of a regex host that NGINX tries in another order than
+	// Traefik, relative to the path of another Ingress.
+	PathRegexOrder = "regex-order"
+)
+
+// PathConflict is a conflict between the paths of several Ingresses of a host.
+//
+// NGINX merges the Ingresses of a host into one server, while Traefik creates a router
+// per path whose priority is the length of its rule.
+type PathConflict struct {
+	Host string `json:"host,omitempty"`
+	// Path is the request path both paths match.
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Ingresses are the conflicting Ingresses, as "namespace/name", in creation order.
+	Ingresses []string `json:"ingresses"`
+	Message   string   `json:"message"`
+}
+
+// hostPath is a path of an Ingress rule, as merged in the server of its host.
+type hostPath struct {
+	report   *IngressReport
+	ref      string
+	host     string
+	aliases  []string
+	path     string
+	pathType netv1.PathType
+	// regex is true when the Ingress of the path enables regex paths.
+	regex bool
+}
+
+// analyzePathConflicts adds the path conflicts across the Ingresses of each host to the
+// reports of the ingresses, in the same order. The canary Ingresses, which NGINX merges
+// into the Ingress they share a path with, are left out.
+func analyzePathConflicts(ingresses []*netv1.Ingress, reports []*IngressReport) {
+	var hosts []string
+	byHost := make(map[string][]hostPath)
+	regexHosts := make(map[string]bool)
+	for _, i := range creationOrder(ingresses) {
+		ing := ingresses[i]
+		if isCanary(ing) {
+			continue
+		}
+
+		var aliases []string
+		for alias := range strings.SplitSeq(ing.Annotations["nginx.ingress.kubernetes.io/server-alias"], ",") {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				aliases = append(aliases, alias)
+			}
+		}
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			if _, ok := byHost[rule.Host]; !ok {
+				hosts = append(hosts, rule.Host)
+			}
+			regexHosts[rule.Host] = regexHosts[rule.Host] || regexMode(ing)
+
+			for _, path := range rule.HTTP.Paths {
+				pathType := netv1.PathTypeImplementationSpecific
+				if path.PathType != nil {
+					pathType = *path.PathType
+				}
+
+				byHost[rule.Host] = append(byHost[rule.Host], hostPath{
+					report:   reports[i],
+					ref:      ing.Namespace + "/" + ing.Name,
+					host:     rule.Host,
+					aliases:  aliases,
+					path:     cmp.Or(path.Path, "/"),
+					pathType: pathType,
+					regex:    regexMode(ing),
+				})
+			}
+		}
+	}
+
+	for _, host := range hosts {
+		paths := byHost[host]
+		for i, a := range paths {
+			for _, b := range paths[i+1:] {
+				if a.report == b.report {
+					continue
+				}
+				if conflict, ok := pathConflict(a, b, regexHosts[host]); ok {
+					a.report.PathConflicts = append(a.report.PathConflicts, conflict)
+					b.report.PathConflicts = append(b.report.PathConflicts, conflict)
+				}
+			}
+		}
+	}
+
+	for _, report := range reports {
+		slices.SortStableFunc(report.PathConflicts, func(a, b PathConflict) int {
+			return cmp.Or(cmp.Compare(a.Host, b.Host), cmp.Compare(a.Path, b.Path))
+		})
+	}
+}
+
+// SortByCreation sorts the Ingresses in creation order, the order in which NGINX merges them.
+func SortByCreation(ingresses []*netv1.Ingress) {
+	slices.SortStableFunc(ingresses, compareCreation)
+}
+
+// creationOrder returns the indexes of the ingresses in creation order, in which NGINX
+// merges them.
+func creationOrder(ingresses []*netv1.Ingress) []int {
+	order := make([]int, len(ingresses))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return compareCreation(ingresses[a], ingresses[b])
+	})
+	return order
+}
+
+// compareCreation orders the Ingresses by creation date, then namespace and name.
+func compareCreation(a, b *netv1.Ingress) int {
+	return cmp.Or(a.CreationTimestamp.Compare(b.CreationTimestamp.Time),
+		cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+}
+
+// isCanary returns whether the Ingress is a canary, merged by NGINX into the Ingress
+// it shares a path with.
+func isCanary(ing *netv1.Ingress) bool {
+	return ing.Annotations["nginx.ingress.kubernetes.io/canary"] == "true"
+}
+
+// pathConflict returns the conflict between the paths of two Ingresses, the path a
+// being the oldest, when a request matches both in NGINX.
+func pathConflict(a, b hostPath, regexHost bool) (PathConflict, bool) {
+	conflict := PathConflict{Host: a.host, Ingresses: []string{a.ref, b.ref}}
+
+	if a.path == b.path && a.pathType == b.pathType {
+		conflict.Path = a.path
+		conflict.Kind = PathDuplicate
+		conflict.Message = fmt.Sprintf("the Ingresses %s and %s both define the %s path %q, NGINX keeps the path of %s, created first, and ignores the other",
+			a.ref, b.ref, a.pathType, a.path, a.ref)
+		if aPriority, bPriority := len(a.traefikRule()), len(b.traefikRule()); aPriority == bPriority {
+			conflict.Message += fmt.Sprintf(", while Traefik gives both routers the priority %d and does not guarantee which one wins", aPriority)
+		} else if bPriority > aPriority {
+			conflict.Message += fmt.Sprintf(", while Traefik routes the requests to %s, whose router has the higher priority %d", b.ref, bPriority)
+		}
+		return conflict, true
+	}
+
+	// The regex paths of the same length are already reported as ordering regex warnings.
+	if regexHost && a.regex && b.regex && len(a.path) == len(b.path) && hasMetacharacters(a.path) && hasMetacharacters(b.path) {
+		return conflict, false
+	}
+
+	var overlap string
+	for _, sample := range slices.Concat(a.samples(regexHost), b.samples(regexHost)) {
+		if !a.nginxMatches(sample, regexHost) || !b.nginxMatches(sample, regexHost) {
+			continue
+		}
+
+		nginx := nginxPreferred(a, b, sample, regexHost)
+		if winner, _ := traefikPreferred(a, b, sample); winner == nil || winner.ref != nginx.ref {
+			conflict.Path = sample
+			conflict.Kind = PathPriority
+			if regexHost {
+				conflict.Kind = PathRegexOrder
+			}
+			conflict.Message = fmt.Sprintf("the requests to %q match the path %q of the Ingress %s and the path %q of the Ingress %s, NGINX routes them to %s while %s; set the router priorities explicitly",
+				sample, a.path, a.ref, b.path, b.ref, nginx.ref, traefikChoice(a, b, sample))
+			return conflict, true
+		}
+
+		if overlap == "" {
+			overlap = sample
+		}
+	}
+
+	if overlap == "" {
+		return conflict, false
+	}
+
+	winner := nginxPreferred(a, b, overlap, regexHost)
+	shadowed := a
+	if winner.ref == a.ref {
+		shadowed = b
+	}
+
+	conflict.Path = overlap
+	conflict.Kind = PathShadowed
+	conflict.Message = fmt.Sprintf("the requests to %q under the path %q of the Ingress %s are routed to the more specific path %q of the Ingress %s, by both NGINX and Traefik",
+		overlap, shadowed.path, shadowed.ref, winner.path, winner.ref)
+	return conflict, true
+}
+
+// samples returns the request paths probing the path: the path itself, or the literal
+// prefix of a regex path.
+func (p hostPath) samples(regexHost bool) []string {
+	if !regexHost || p.pathType == netv1.PathTypeExact || !hasMetacharacters(p.path) {
+		return []string{p.path}
+	}
+
+	literal := p.path[:strings.IndexAny(p.path, `\.+*?()|[]{}^$`)]
+	if literal == "" {
+		return nil
+	}
+	return []string{literal}
+}
+
+// nginxMatches returns whether the NGINX location of the path matches the request path.
+// Every path of a regex host but the exact ones is a case-insensitive regex.
+func (p hostPath) nginxMatches(path string, regexHost bool) bool {
+	switch {
+	case p.pathType == netv1.PathTypeExact:
+		return path == p.path
+	case regexHost:
+		if RegexPathError(p.path) != nil {
+			return false
+		}
+		return regexp.MustCompile("(?i)^" + p.path).MatchString(path)
+	default:
+		return p.prefixMatches(path)
+	}
+}
+
+// traefikMatches returns whether the Traefik router of the path matches the request path.
+func (p hostPath) traefikMatches(path string) bool {
+	switch {
+	case p.pathType == netv1.PathTypeExact:
+		return path == p.path
+	case p.regex:
+		if RegexPathError(p.path) != nil {
+			return false
+		}
+		return regexp.MustCompile("(?i)^" + p.path).MatchString(path)
+	default:
+		return p.prefixMatches(path)
+	}
+}
+
+func (p hostPath) prefixMatches(path string) bool {
+	if p.pathType == netv1.PathTypePrefix && p.path != "/" {
+		trimmed := strings.TrimSuffix(p.path, "/")
+		return path == trimmed || strings.HasPrefix(path, trimmed+"/")
+	}
+	return strings.HasPrefix(path, p.path)
+}
+
+// nginxPreferred returns the path whose location NGINX selects for the request path,
+// both matching it: the exact locations first, including those NGINX adds for the
+// Prefix paths of the hosts without regex, then the longest regex or prefix location,
+// the oldest Ingress on ties.
+func nginxPreferred(a, b hostPath, path string, regexHost bool) hostPath {
+	exact := func(p hostPath) bool {
+		return p.pathType == netv1.PathTypeExact ||
+			!regexHost && p.pathType == netv1.PathTypePrefix && p.path != "/" && path == strings.TrimSuffix(p.path, "/")
+	}
+	location := func(p hostPath) int {
+		if !regexHost && p.pathType == netv1.PathTypePrefix && p.path != "/" {
+			return len(strings.TrimSuffix(p.path, "/")) + 1
+		}
+		return len(p.path)
+	}
+
+	switch {
+	case exact(a) != exact(b):
+		if exact(b) {
+			return b
+		}
+		return a
+	case location(b) > location(a):
+		return b
+	default:
+		return a
+	}
+}
+
+// traefikPreferred returns the path whose router Traefik selects for the request path
+// and its priority, nil when no router matches or when both have the same priority.
+func traefikPreferred(a, b hostPath, path string) (*hostPath, int) {
+	aMatches, bMatches := a.traefikMatches(path), b.traefikMatches(path)
+	aPriority, bPriority := len(a.traefikRule()), len(b.traefikRule())
+
+	switch {
+	case aMatches && (!bMatches || aPriority > bPriority):
+		return &a, aPriority
+	case bMatches && (!aMatches || bPriority > aPriority):
+		return &b, bPriority
+	default:
+		return nil, aPriority
+	}
+}
+
+// traefikChoice describes the router Traefik selects for the request path, when it is
+// not the router of the path NGINX selects.
+func traefikChoice(a, b hostPath, path string) string {
+	winner, priority := traefikPreferred(a, b, path)
+	switch {
+	case winner == nil && a.traefikMatches(path):
+		return fmt.Sprintf("Traefik gives both routers the priority %d and does not guarantee which one wins", priority)
+	case winner == nil:
+		return "no Traefik router matches them"
+	default:
+		return fmt.Sprintf("Traefik routes them to %s, whose router has the higher priority %d", winner.ref, priority)
+	}
+}
+
+// traefikRule returns the rule of the Traefik router of the path, whose length is its
+// default priority.
+func (p hostPath) traefikRule() string {
+	return TraefikRule(p.host, p.aliases, p.path, p.pathType, p.regex)
+}
+
+// TraefikRule returns the rule of the Traefik router of an Ingress path, whose length is
+// its default priority. The regex paths are those of the Ingresses enabling use-regex.
+func TraefikRule(host string, aliases []string, path string, pathType netv1.PathType, regex bool) string {
+	var hosts []string
+	for _, h := range slices.Concat([]string{host}, aliases) {
+		switch {
+		case h == "":
+		case strings.HasPrefix(h, "*."):
+			hosts = append(hosts, "HostRegexp(`^[a-z0-9-]+"+regexp.QuoteMeta(h[1:])+"$`)")
+		default:
+			hosts = append(hosts, "Host(`"+h+"`)")
+		}
+	}
+
+	hostRule := strings.Join(hosts, " || ")
+	if len(hosts) > 1 {
+		hostRule = "(" + hostRule + ")"
+	}
+
+	var pathRule string
+	switch {
+	case pathType == netv1.PathTypeExact:
+		pathRule = "Path(`" + path + "`)"
+	case regex:
+		pathRule = "PathRegexp(`(?i)^" + path + "`)"
+	case pathType == netv1.PathTypePrefix && path != "/":
+		trimmed := strings.TrimSuffix(path, "/")
+		pathRule = "(Path(`" + trimmed + "`) || PathPrefix(`" + trimmed + "/`))"
+	default:
+		pathRule = "PathPrefix(`" + path + "`)"
+	}
+
+	if hostRule == "" {
+		return pathRule
+	}
+	return hostRule + " && " + pathRule
+}
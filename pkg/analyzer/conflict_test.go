@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnalyzePathConflicts(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ingress := func(name string, annotations map[string]string, host, path string, pathType netv1.PathType) *netv1.Ingress {
+		created = created.Add(time.Minute)
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Annotations:       annotations,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{Paths: []netv1.HTTPIngressPath{
+					{Path: path, PathType: &pathType},
+				}}},
+			}}},
+		}
+	}
+
+	ingresses := []*netv1.Ingress{
+		ingress("shop", nil, "shop.example.com", "/", netv1.PathTypePrefix),
+		ingress("cart", nil, "shop.example.com", "/cart", netv1.PathTypePrefix),
+		ingress("cart-v2", nil, "shop.example.com", "/cart", netv1.PathTypePrefix),
+		ingress("cart-canary", map[string]string{"nginx.ingress.kubernetes.io/canary": "true"}, "shop.example.com", "/cart", netv1.PathTypePrefix),
+		ingress("web", map[string]string{"nginx.ingress.kubernetes.io/server-alias": "www.example.com"}, "example.com", "/", netv1.PathTypePrefix),
+		ingress("status", nil, "example.com", "/status", netv1.PathTypeExact),
+		ingress("regex", map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}, "api.example.com", "/api/.*", netv1.PathTypeImplementationSpecific),
+		ingress("v1", nil, "api.example.com", "/api/v1", netv1.PathTypePrefix),
+		ingress("other", nil, "other.example.com", "/cart", netv1.PathTypePrefix),
+	}
+
+	// The analysis follows the creation order whatever the listing order.
+	ingresses[0], ingresses[1] = ingresses[1], ingresses[0]
+
+	reports := make([]*IngressReport, len(ingresses))
+	for i, ing := range ingresses {
+		reports[i] = computeIngressReport(ing)
+	}
+
+	analyzePathConflicts(ingresses, reports)
+
+	shadowed := PathConflict{
+		Host:      "shop.example.com",
+		Path:      "/cart",
+		Kind:      PathShadowed,
+		Ingresses: []string{"default/shop", "default/cart"},
+		Message:   `the requests to "/cart" under the path "/" of the Ingress default/shop are routed to the more specific path "/cart" of the Ingress default/cart, by both NGINX and Traefik`,
+	}
+	shadowedV2 := PathConflict{
+		Host:      "shop.example.com",
+		Path:      "/cart",
+		Kind:      PathShadowed,
+		Ingresses: []string{"default/shop", "default/cart-v2"},
+		Message:   `the requests to "/cart" under the path "/" of the Ingress default/shop are routed to the more specific path "/cart" of the Ingress default/cart-v2, by both NGINX and Traefik`,
+	}
+	duplicate := PathConflict{
+		Host:      "shop.example.com",
+		Path:      "/cart",
+		Kind:      PathDuplicate,
+		Ingresses: []string{"default/cart", "default/cart-v2"},
+		Message:   `the Ingresses default/cart and default/cart-v2 both define the Prefix path "/cart", NGINX keeps the path of default/cart, created first, and ignores the other, while Traefik gives both routers the priority 67 and does not guarantee which one wins`,
+	}
+	priority := PathConflict{
+		Host:      "example.com",
+		Path:      "/status",
+		Kind:      PathPriority,
+		Ingresses: []string{"default/web", "default/status"},
+		Message:   `the requests to "/status" match the path "/" of the Ingress default/web and the path "/status" of the Ingress default/status, NGINX routes them to default/status while Traefik routes them to default/web, whose router has the higher priority 67; set the router priorities explicitly`,
+	}
+	regexOrder := PathConflict{
+		Host:      "api.example.com",
+		Path:      "/api/v1",
+		Kind:      PathRegexOrder,
+		Ingresses: []string{"default/regex", "default/v1"},
+		Message:   `the requests to "/api/v1" match the path "/api/.*" of the Ingress default/regex and the path "/api/v1" of the Ingress default/v1, NGINX routes them to default/regex while Traefik routes them to default/v1, whose router has the higher priority 70; set the router priorities explicitly`,
+	}
+
+	assert.Equal(t, []PathConflict{shadowed, shadowedV2}, reports[1].PathConflicts)
+	assert.Equal(t, []PathConflict{shadowed, duplicate}, reports[0].PathConflicts)
+	assert.Equal(t, []PathConflict{shadowedV2, duplicate}, reports[2].PathConflicts)
+	assert.Empty(t, reports[3].PathConflicts)
+	assert.Equal(t, []PathConflict{priority}, reports[4].PathConflicts)
+	assert.Equal(t, []PathConflict{priority}, reports[5].PathConflicts)
+	assert.Equal(t, []PathConflict{regexOrder}, reports[6].PathConflicts)
+	assert.Equal(t, []PathConflict{regexOrder}, reports[7].PathConflicts)
+	assert.Empty(t, reports[8].PathConflicts)
+}
+
+func TestTraefikRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		host     string
+		aliases  []string
+		path     string
+		pathType netv1.PathType
+		regex    bool
+		want     string
+	}{
+		{desc: "exact", host: "example.com", path: "/status", pathType: netv1.PathTypeExact, want: "Host(`example.com`) && Path(`/status`)"},
+		{desc: "prefix", host: "example.com", path: "/api/", pathType: netv1.PathTypePrefix, want: "Host(`example.com`) && (Path(`/api`) || PathPrefix(`/api/`))"},
+		{desc: "root prefix without host", path: "/", pathType: netv1.PathTypePrefix, want: "PathPrefix(`/`)"},
+		{desc: "regex", host: "example.com", path: "/api/.*", pathType: netv1.PathTypeImplementationSpecific, regex: true, want: "Host(`example.com`) && PathRegexp(`(?i)^/api/.*`)"},
+		{
+			desc:     "wildcard and aliases",
+			host:     "*.example.com",
+			aliases:  []string{"example.org"},
+			path:     "/",
+			pathType: netv1.PathTypeImplementationSpecific,
+			want:     "(HostRegexp(`^[a-z0-9-]+\\.example\\.com$`) || Host(`example.org`)) && PathPrefix(`/`)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, TraefikRule(tt.host, tt.aliases, tt.path, tt.pathType, tt.regex))
+		})
+	}
+}
+
+func TestSortByCreation(t *testing.T) {
+	t.Parallel()
+
+	created := metav1.NewTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	ingresses := []*netv1.Ingress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "late", CreationTimestamp: metav1.NewTime(created.Add(time.Hour))}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", CreationTimestamp: created}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web", CreationTimestamp: created}},
+	}
+
+	SortByCreation(ingresses)
+
+	var refs []string
+	for _, ing := range ingresses {
+		refs = append(refs, ing.Namespace+"/"+ing.Name)
+	}
+	assert.Equal(t, []string{"default/web", "shop/web", "default/late"}, refs)
+}
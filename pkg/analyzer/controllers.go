@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Controller is an NGINX ingress controller of the cluster.
+type Controller struct {
+	// ControllerClass is the controller of its IngressClasses.
+	ControllerClass string `json:"controllerClass"`
+	// IngressClass is the value of the class annotation it handles.
+	IngressClass string `json:"ingressClass"`
+}
+
+// DiscoverControllers returns the NGINX ingress controllers of the cluster, sorted by
+// controller class then ingress class: those of the controller deployments, with the
+// classes of their flags, then those of the IngressClasses whose controller contains
+// "ingress-nginx" without deployment, whose class annotation value is unknown and set to
+// the controller default. Controllers handling the same ingress class are rejected.
+func (a *Analyzer) DiscoverControllers() ([]Controller, error) {
+	ingressClasses, _, err := a.list()
+	if err != nil {
+		return nil, err
+	}
+
+	var controllers []Controller
+	for _, deployment := range a.controllerDeployments {
+		controller := Controller{ControllerClass: deployment.ControllerClass, IngressClass: deployment.IngressClass}
+		if !slices.Contains(controllers, controller) {
+			controllers = append(controllers, controller)
+		}
+	}
+	for _, ic := range ingressClasses {
+		if !strings.Contains(ic.Spec.Controller, "ingress-nginx") ||
+			slices.ContainsFunc(controllers, func(c Controller) bool { return c.ControllerClass == ic.Spec.Controller }) {
+			continue
+		}
+		controllers = append(controllers, Controller{ControllerClass: ic.Spec.Controller, IngressClass: defaultAnnotationValue})
+	}
+
+	slices.SortFunc(controllers, func(a, b Controller) int {
+		return cmp.Or(cmp.Compare(a.ControllerClass, b.ControllerClass), cmp.Compare(a.IngressClass, b.IngressClass))
+	})
+
+	if err := CheckIngressClasses(controllers); err != nil {
+		return nil, err
+	}
+
+	return controllers, nil
+}
+
+// CheckIngressClasses returns an error when two controllers handle the same ingress class,
+// the empty one being the default: both would report the Ingresses of its annotation.
+func CheckIngressClasses(controllers []Controller) error {
+	for i, controller := range controllers {
+		ingressClass := cmp.Or(controller.IngressClass, defaultAnnotationValue)
+		j := slices.IndexFunc(controllers[:i], func(c Controller) bool {
+			return cmp.Or(c.IngressClass, defaultAnnotationValue) == ingressClass
+		})
+		if j >= 0 {
+			return fmt.Errorf("the controllers %s and %s both handle the ingress class %q",
+				controllers[j].ControllerClass, controller.ControllerClass, ingressClass)
+		}
+	}
+
+	return nil
+}
+
+// ForController returns an analyzer of the Ingresses of another NGINX ingress controller,
+// sharing the informers of a, which must be started. Its report is generated separately.
+func (a *Analyzer) ForController(controller Controller) *Analyzer {
+	return &Analyzer{
+		ingressClass:             cmp.Or(controller.IngressClass, defaultAnnotationValue),
+		controllerClass:          cmp.Or(controller.ControllerClass, defaultControllerName),
+		watchIngressWithoutClass: a.watchIngressWithoutClass,
+		ingressClassByName:       a.ingressClassByName,
+		clusterFactory:           a.clusterFactory,
+		nsFactories:              a.nsFactories,
+		ingressListers:           a.ingressListers,
+		ingressClassLister:       a.ingressClassLister,
+		unanalyzedNamespaces:     a.unanalyzedNamespaces,
+		controllerDeployments:    a.controllerDeployments,
+	}
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	listersnetv1 "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestControllers(t *testing.T) {
+	t.Parallel()
+
+	ingressClassIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ic := range []*netv1.IngressClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nginx-internal"}, Spec: netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx-internal"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nginx-internal-legacy"}, Spec: netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx-internal"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "traefik"}, Spec: netv1.IngressClassSpec{Controller: "traefik.io/ingress-controller"}},
+	} {
+		require.NoError(t, ingressClassIndexer.Add(ic))
+	}
+
+	ingressIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ing := range []*netv1.Ingress{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "public"}, Spec: netv1.IngressSpec{IngressClassName: new("nginx")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "internal"}, Spec: netv1.IngressSpec{IngressClassName: new("nginx-internal")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "internal-legacy"}, Spec: netv1.IngressSpec{IngressClassName: new("nginx-internal-legacy")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "traefik"}, Spec: netv1.IngressSpec{IngressClassName: new("traefik")}},
+	} {
+		require.NoError(t, ingressIndexer.Add(ing))
+	}
+
+	a := &Analyzer{
+		ingressClass:       "nginx",
+		controllerClass:    "k8s.io/ingress-nginx",
+		ingressListers:     []listersnetv1.IngressLister{listersnetv1.NewIngressLister(ingressIndexer)},
+		ingressClassLister: listersnetv1.NewIngressClassLister(ingressClassIndexer),
+		controllerDeployments: []ControllerDeployment{
+			{Namespace: "ingress-nginx", Name: "internal", ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "internal"},
+			{Namespace: "ingress-nginx", Name: "internal-canary", ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "canary"},
+			{Namespace: "edge", Name: "edge", ControllerClass: "example.com/edge", IngressClass: "edge"},
+		},
+	}
+
+	// The classes of the deployments win over the IngressClasses, whose class annotation
+	// value is unknown and defaults to nginx.
+	controllers, err := a.DiscoverControllers()
+	require.NoError(t, err)
+	assert.Equal(t, []Controller{
+		{ControllerClass: "example.com/edge", IngressClass: "edge"},
+		{ControllerClass: "k8s.io/ingress-nginx", IngressClass: "nginx"},
+		{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "canary"},
+		{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "internal"},
+	}, controllers)
+
+	wantIngresses := [][]string{nil, {"public"}, {"internal", "internal-legacy"}, {"internal", "internal-legacy"}}
+	for i, controller := range controllers {
+		controllerAnalyzer := a.ForController(controller)
+		require.NoError(t, controllerAnalyzer.GenerateReport())
+
+		report := controllerAnalyzer.Report()
+
+		var names []string
+		for _, ing := range report.CompatibleIngresses {
+			names = append(names, ing.Name)
+		}
+		assert.Equal(t, wantIngresses[i], names)
+		assert.Equal(t, len(wantIngresses[i]), report.IngressCount)
+	}
+
+	// The report of the analyzer is not affected by the ones of its controllers.
+	assert.Zero(t, a.Report().IngressCount)
+}
+
+func TestDiscoverControllers_DuplicateIngressClass(t *testing.T) {
+	t.Parallel()
+
+	ingressClassIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ic := range []*netv1.IngressClass{
+		{ObjectMeta: metav1.ObjectMeta{Name: "nginx"}, Spec: netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "nginx-internal"}, Spec: netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx-internal"}},
+	} {
+		require.NoError(t, ingressClassIndexer.Add(ic))
+	}
+
+	a := &Analyzer{
+		ingressListers:     []listersnetv1.IngressLister{listersnetv1.NewIngressLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))},
+		ingressClassLister: listersnetv1.NewIngressClassLister(ingressClassIndexer),
+	}
+
+	// Without deployment, both controllers default to the ingress class nginx.
+	_, err := a.DiscoverControllers()
+	require.EqualError(t, err, `the controllers k8s.io/ingress-nginx and k8s.io/ingress-nginx-internal both handle the ingress class "nginx"`)
+
+	a.SetControllerDeployments([]ControllerDeployment{{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "internal"}})
+	controllers, err := a.DiscoverControllers()
+	require.NoError(t, err)
+	assert.Equal(t, []Controller{
+		{ControllerClass: "k8s.io/ingress-nginx", IngressClass: "nginx"},
+		{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "internal"},
+	}, controllers)
+}
+
+func TestCheckIngressClasses(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, CheckIngressClasses([]Controller{{ControllerClass: "a", IngressClass: "nginx"}, {ControllerClass: "b", IngressClass: "internal"}}))
+	assert.EqualError(t, CheckIngressClasses([]Controller{{ControllerClass: "a"}, {ControllerClass: "b"}}),
+		`the controllers a and b both handle the ingress class "nginx"`)
+	assert.EqualError(t, CheckIngressClasses([]Controller{{ControllerClass: "a", IngressClass: "nginx"}, {ControllerClass: "b"}}),
+		`the controllers a and b both handle the ingress class "nginx"`)
+}
@@ -0,0 +1,279 @@
+package analyzer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	labelName      = "app.kubernetes.io/name"
+	labelComponent = "app.kubernetes.io/component"
+
+	controllerImage = "ingress-nginx/controller"
+)
+
+// ControllerDeployment is a Deployment or DaemonSet running the NGINX ingress controller,
+// with the flags of its controller container.
+type ControllerDeployment struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Image     string `json:"image"`
+	// Version is the tag of the image, empty when the image has none.
+	Version string `json:"version,omitempty"`
+	// ControllerClass and IngressClass are the classes the controller handles, from its
+	// flags or their defaults.
+	ControllerClass string           `json:"controllerClass"`
+	IngressClass    string           `json:"ingressClass"`
+	Flags           []ControllerFlag `json:"flags"`
+}
+
+// ControllerFlag is a command-line flag of the NGINX ingress controller, and its Traefik equivalent.
+type ControllerFlag struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// Traefik is the Traefik equivalent of the flag, empty when Traefik has none.
+	Traefik string `json:"traefik,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// controllerFlag describes how Traefik handles a flag of the NGINX ingress controller.
+type controllerFlag struct {
+	traefik string
+	note    string
+}
+
+var controllerFlags = map[string]controllerFlag{
+	"ingress-class":               {traefik: "providers.kubernetesIngressNGINX.ingressClass"},
+	"controller-class":            {traefik: "providers.kubernetesIngressNGINX.controllerClass"},
+	"watch-ingress-without-class": {traefik: "providers.kubernetesIngressNGINX.watchIngressWithoutClass"},
+	"ingress-class-by-name":       {traefik: "providers.kubernetesIngressNGINX.ingressClassByName"},
+	"watch-namespace": {
+		traefik: "providers.kubernetesIngressNGINX.watchNamespace",
+		note:    "the controller only serves the Ingresses of this namespace",
+	},
+	"watch-namespace-selector": {traefik: "providers.kubernetesIngressNGINX.watchNamespaceSelector"},
+	"publish-service": {
+		traefik: "providers.kubernetesIngressNGINX.publishService",
+		note:    "set it to the Service of Traefik once Traefik receives the traffic, so that the Ingress status holds its address",
+	},
+	"publish-status-address":    {traefik: "providers.kubernetesIngressNGINX.publishStatusAddress"},
+	"default-backend-service":   {traefik: "providers.kubernetesIngressNGINX.defaultBackendService"},
+	"disable-svc-external-name": {traefik: "providers.kubernetesIngressNGINX.disableSvcExternalName"},
+	"default-ssl-certificate": {
+		traefik: "tls.stores.default.defaultCertificate",
+		note:    "load the certificate and key of the Secret as the default certificate of the TLS store",
+	},
+	"enable-ssl-passthrough": {
+		note: "Traefik serves the ssl-passthrough annotation with TCP routers, without any flag",
+	},
+	"http-port":  {traefik: "entryPoints.web.address"},
+	"https-port": {traefik: "entryPoints.websecure.address"},
+	"annotations-prefix": {
+		note: "Traefik only reads the annotations prefixed with nginx.ingress.kubernetes.io, the annotations with another prefix are ignored, and not analyzed by this report",
+	},
+	"enable-annotation-validation": {
+		note: "Traefik does not validate the annotation values against the rules of NGINX",
+	},
+	"configmap": {
+		note: "Traefik does not read the ConfigMap of the controller: its global settings must be configured as entry points, middlewares or TLS options",
+	},
+	"tcp-services-configmap": {note: "expose the TCP services with entry points and IngressRouteTCPs"},
+	"udp-services-configmap": {note: "expose the UDP services with entry points and IngressRouteUDPs"},
+	"enable-topology-aware-routing": {
+		note: "Traefik load balances across all the endpoints of a Service",
+	},
+}
+
+// ignoredControllerFlags are the flags of the NGINX ingress controller not affecting how
+// the Ingresses are served, not reported.
+var ignoredControllerFlags = []string{
+	"v",
+	"election-id",
+	"election-ttl",
+	"health-check-path",
+	"healthz-port",
+	"healthz-host",
+	"metrics-per-host",
+	"metrics-per-undefined-host",
+	"post-shutdown-grace-period",
+	"shutdown-grace-period",
+	"profiler-port",
+	"status-port",
+	"stream-port",
+	"validating-webhook",
+	"validating-webhook-certificate",
+	"validating-webhook-key",
+}
+
+// DiscoverControllerDeployments returns the Deployments and DaemonSets of all namespaces
+// running the NGINX ingress controller, sorted by namespace, name then kind. They are
+// recognized by the labels of the ingress-nginx chart, or by the image of the controller.
+func DiscoverControllerDeployments(ctx context.Context, k8sClient kubernetes.Interface) ([]ControllerDeployment, error) {
+	deploymentList, err := k8sClient.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing Deployments: %w", err)
+	}
+
+	daemonSetList, err := k8sClient.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing DaemonSets: %w", err)
+	}
+
+	var deployments []ControllerDeployment
+	for _, d := range deploymentList.Items {
+		if deployment, ok := controllerDeployment("Deployment", d.ObjectMeta, d.Spec.Template.Spec); ok {
+			deployments = append(deployments, deployment)
+		}
+	}
+	for _, ds := range daemonSetList.Items {
+		if deployment, ok := controllerDeployment("DaemonSet", ds.ObjectMeta, ds.Spec.Template.Spec); ok {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	slices.SortFunc(deployments, func(a, b ControllerDeployment) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Kind, b.Kind))
+	})
+
+	return deployments, nil
+}
+
+// SetControllerDeployments sets the discovered controller deployments, those of the
+// analyzed controller class being listed in the report.
+func (a *Analyzer) SetControllerDeployments(deployments []ControllerDeployment) {
+	a.controllerDeployments = deployments
+}
+
+// reportedControllerDeployments returns the controller deployments of the analyzed controller class.
+func (a *Analyzer) reportedControllerDeployments() []ControllerDeployment {
+	var deployments []ControllerDeployment
+	for _, deployment := range a.controllerDeployments {
+		if deployment.ControllerClass == a.controllerClass {
+			deployments = append(deployments, deployment)
+		}
+	}
+
+	return deployments
+}
+
+// controllerDeployment returns the controller deployment of the workload, false when it
+// does not run the NGINX ingress controller.
+func controllerDeployment(kind string, meta metav1.ObjectMeta, spec corev1.PodSpec) (ControllerDeployment, bool) {
+	container, ok := controllerContainer(meta, spec)
+	if !ok {
+		return ControllerDeployment{}, false
+	}
+
+	deployment := ControllerDeployment{
+		Kind:            kind,
+		Namespace:       meta.Namespace,
+		Name:            meta.Name,
+		Image:           container.Image,
+		Version:         imageTag(container.Image),
+		ControllerClass: defaultControllerName,
+		IngressClass:    defaultAnnotationValue,
+	}
+
+	for _, flag := range parseControllerFlags(slices.Concat(container.Command, container.Args)) {
+		switch flag.Name {
+		case "controller-class":
+			deployment.ControllerClass = cmp.Or(flag.Value, defaultControllerName)
+		case "ingress-class":
+			deployment.IngressClass = cmp.Or(flag.Value, defaultAnnotationValue)
+		}
+
+		if slices.Contains(ignoredControllerFlags, flag.Name) {
+			continue
+		}
+
+		known := controllerFlags[flag.Name]
+		flag.Traefik = known.traefik
+		flag.Note = known.note
+		deployment.Flags = append(deployment.Flags, flag)
+	}
+
+	return deployment, true
+}
+
+// controllerContainer returns the container running the NGINX ingress controller: the
+// container with the image of the controller, or the container named controller of a
+// workload labelled by the ingress-nginx chart.
+func controllerContainer(meta metav1.ObjectMeta, spec corev1.PodSpec) (corev1.Container, bool) {
+	for _, container := range spec.Containers {
+		if strings.Contains(container.Image, controllerImage) {
+			return container, true
+		}
+	}
+
+	if meta.Labels[labelName] != "ingress-nginx" || cmp.Or(meta.Labels[labelComponent], "controller") != "controller" {
+		return corev1.Container{}, false
+	}
+
+	for _, container := range spec.Containers {
+		if container.Name == "controller" {
+			return container, true
+		}
+	}
+	if len(spec.Containers) == 1 {
+		return spec.Containers[0], true
+	}
+
+	return corev1.Container{}, false
+}
+
+// parseControllerFlags parses the flags of the command line, as --name=value, --name value
+// or --name for the boolean flags, sorted by name. The last value of a repeated flag wins.
+func parseControllerFlags(args []string) []ControllerFlag {
+	values := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		name, ok := strings.CutPrefix(args[i], "-")
+		if !ok {
+			continue
+		}
+		name = strings.TrimPrefix(name, "-")
+		if name == "" || strings.HasPrefix(name, "=") {
+			continue
+		}
+
+		if name, value, ok := strings.Cut(name, "="); ok {
+			values[name] = value
+			continue
+		}
+
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			values[name] = args[i+1]
+			i++
+			continue
+		}
+
+		values[name] = "true"
+	}
+
+	flags := make([]ControllerFlag, 0, len(values))
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		flags = append(flags, ControllerFlag{Name: name, Value: values[name]})
+	}
+
+	return flags
+}
+
+// imageTag returns the tag of the image, without its digest.
+func imageTag(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+
+	return image[i+1:]
+}
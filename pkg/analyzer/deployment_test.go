@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverControllerDeployments(t *testing.T) {
+	t.Parallel()
+
+	podSpec := func(containers ...corev1.Container) corev1.PodTemplateSpec {
+		return corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}
+	}
+
+	k8sClient := fake.NewClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-nginx-controller",
+				Namespace: "ingress-nginx",
+				Labels:    map[string]string{"app.kubernetes.io/name": "ingress-nginx", "app.kubernetes.io/component": "controller"},
+			},
+			Spec: appsv1.DeploymentSpec{Template: podSpec(corev1.Container{
+				Name:  "controller",
+				Image: "registry.k8s.io/ingress-nginx/controller:v1.11.2@sha256:d5f8217feeac4887cb1ed21f27c2674e58be06bd8f5184cacea2a69abaf78dce",
+				Args: []string{
+					"/nginx-ingress-controller",
+					"--publish-service=$(POD_NAMESPACE)/ingress-nginx-controller",
+					"--election-id=ingress-nginx-leader",
+					"--controller-class=k8s.io/ingress-nginx",
+					"--ingress-class=nginx",
+					"--configmap=$(POD_NAMESPACE)/ingress-nginx-controller",
+					"--enable-ssl-passthrough",
+					"--annotations-prefix", "custom.ingress.kubernetes.io",
+				},
+			})},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ingress-nginx-defaultbackend",
+				Namespace: "ingress-nginx",
+				Labels:    map[string]string{"app.kubernetes.io/name": "ingress-nginx", "app.kubernetes.io/component": "default-backend"},
+			},
+			Spec: appsv1.DeploymentSpec{Template: podSpec(corev1.Container{Name: "default-backend", Image: "registry.k8s.io/defaultbackend-amd64:1.5"})},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "edge"},
+			Spec: appsv1.DaemonSetSpec{Template: podSpec(
+				corev1.Container{Name: "proxy", Image: "envoyproxy/envoy:v1.31.0"},
+				corev1.Container{
+					Name:    "nginx",
+					Image:   "mirror.example.com:5000/ingress-nginx/controller",
+					Command: []string{"/nginx-ingress-controller", "--controller-class=k8s.io/internal", "--watch-namespace=team-a", "--enable-annotation-validation=false", "--unknown-flag"},
+				},
+			)},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: podSpec(corev1.Container{Name: "web", Image: "nginx:1.27"})},
+		},
+	)
+
+	deployments, err := DiscoverControllerDeployments(t.Context(), k8sClient)
+	require.NoError(t, err)
+
+	want := []ControllerDeployment{
+		{
+			Kind:            "DaemonSet",
+			Namespace:       "edge",
+			Name:            "internal",
+			Image:           "mirror.example.com:5000/ingress-nginx/controller",
+			ControllerClass: "k8s.io/internal",
+			IngressClass:    "nginx",
+			Flags: []ControllerFlag{
+				{Name: "controller-class", Value: "k8s.io/internal", Traefik: "providers.kubernetesIngressNGINX.controllerClass"},
+				{Name: "enable-annotation-validation", Value: "false", Note: "Traefik does not validate the annotation values against the rules of NGINX"},
+				{Name: "unknown-flag", Value: "true"},
+				{Name: "watch-namespace", Value: "team-a", Traefik: "providers.kubernetesIngressNGINX.watchNamespace", Note: "the controller only serves the Ingresses of this namespace"},
+			},
+		},
+		{
+			Kind:            "Deployment",
+			Namespace:       "ingress-nginx",
+			Name:            "ingress-nginx-controller",
+			Image:           "registry.k8s.io/ingress-nginx/controller:v1.11.2@sha256:d5f8217feeac4887cb1ed21f27c2674e58be06bd8f5184cacea2a69abaf78dce",
+			Version:         "v1.11.2",
+			ControllerClass: "k8s.io/ingress-nginx",
+			IngressClass:    "nginx",
+			Flags: []ControllerFlag{
+				{Name: "annotations-prefix", Value: "custom.ingress.kubernetes.io", Note: controllerFlags["annotations-prefix"].note},
+				{Name: "configmap", Value: "$(POD_NAMESPACE)/ingress-nginx-controller", Note: controllerFlags["configmap"].note},
+				{Name: "controller-class", Value: "k8s.io/ingress-nginx", Traefik: "providers.kubernetesIngressNGINX.controllerClass"},
+				{Name: "enable-ssl-passthrough", Value: "true", Note: controllerFlags["enable-ssl-passthrough"].note},
+				{Name: "ingress-class", Value: "nginx", Traefik: "providers.kubernetesIngressNGINX.ingressClass"},
+				{Name: "publish-service", Value: "$(POD_NAMESPACE)/ingress-nginx-controller", Traefik: "providers.kubernetesIngressNGINX.publishService", Note: controllerFlags["publish-service"].note},
+			},
+		},
+	}
+	assert.Equal(t, want, deployments)
+}
+
+func TestReportedControllerDeployments(t *testing.T) {
+	t.Parallel()
+
+	a := &Analyzer{controllerClass: "k8s.io/ingress-nginx"}
+	a.SetControllerDeployments([]ControllerDeployment{
+		{Name: "public", ControllerClass: "k8s.io/ingress-nginx"},
+		{Name: "internal", ControllerClass: "k8s.io/internal"},
+	})
+
+	assert.Equal(t, []ControllerDeployment{{Name: "public", ControllerClass: "k8s.io/ingress-nginx"}}, a.reportedControllerDeployments())
+	assert.Equal(t, []ControllerDeployment{{Name: "internal", ControllerClass: "k8s.io/internal"}},
+		a.ForController(Controller{ControllerClass: "k8s.io/internal", IngressClass: "internal"}).reportedControllerDeployments())
+}
+
+func TestParseControllerFlags(t *testing.T) {
+	t.Parallel()
+
+	flags := parseControllerFlags([]string{"/nginx-ingress-controller", "-", "--", "--=nginx", "-v=2", "--ingress-class", "nginx", "--ingress-class=internal", "--enable-ssl-passthrough"})
+
+	want := []ControllerFlag{
+		{Name: "enable-ssl-passthrough", Value: "true"},
+		{Name: "ingress-class", Value: "internal"},
+		{Name: "v", Value: "2"},
+	}
+	assert.Equal(t, want, flags)
+}
+
+func TestImageTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "registry.k8s.io/ingress-nginx/controller:v1.11.2", want: "v1.11.2"},
+		{image: "registry.k8s.io/ingress-nginx/controller:v1.11.2@sha256:d5f8217f", want: "v1.11.2"},
+		{image: "registry.k8s.io/ingress-nginx/controller@sha256:d5f8217f", want: ""},
+		{image: "mirror.example.com:5000/ingress-nginx/controller", want: ""},
+		{image: "mirror.example.com:5000/ingress-nginx/controller:1.10", want: "1.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, imageTag(tt.image))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package analyzer
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Kinds of blocking annotations, matching the Markdown report.
+const (
+	BlockingUnsupported = "unsupported"
+	BlockingUnknown     = "unknown"
+)
+
+// IngressTotals are the Ingress totals of the reports of several clusters.
+type IngressTotals struct {
+	IngressCount                 int     `json:"ingressCount"`
+	CompatibleIngressCount       int     `json:"compatibleIngressCount"`
+	CompatibleIngressPercentage  float64 `json:"compatibleIngressPercentage"`
+	VanillaIngressCount          int     `json:"vanillaIngressCount"`
+	SupportedIngressCount        int     `json:"supportedIngressCount"`
+	UnsupportedIngressCount      int     `json:"unsupportedIngressCount"`
+	UnsupportedIngressPercentage float64 `json:"unsupportedIngressPercentage"`
+}
+
+// BlockingAnnotation is an annotation preventing the migration, with the number of
+// Ingresses and clusters using it.
+type BlockingAnnotation struct {
+	Annotation   string `json:"annotation"`
+	Kind         string `json:"kind"`
+	IngressCount int    `json:"ingressCount"`
+	ClusterCount int    `json:"clusterCount"`
+}
+
+// AggregateReports returns the Ingress totals of the reports of several clusters, and
+// the annotations preventing their migration, most used first.
+func AggregateReports(reports []Report) (IngressTotals, []BlockingAnnotation) {
+	var totals IngressTotals
+	blocking := make(map[string]*BlockingAnnotation)
+	addBlocking := func(annotation, kind string, count int) {
+		b, ok := blocking[annotation]
+		if !ok {
+			b = &BlockingAnnotation{Annotation: annotation, Kind: kind}
+			blocking[annotation] = b
+		}
+
+		b.IngressCount += count
+		b.ClusterCount++
+	}
+
+	for _, report := range reports {
+		totals.IngressCount += report.IngressCount
+		totals.CompatibleIngressCount += report.CompatibleIngressCount
+		totals.VanillaIngressCount += report.VanillaIngressCount
+		totals.SupportedIngressCount += report.SupportedIngressCount
+		totals.UnsupportedIngressCount += report.UnsupportedIngressCount
+
+		for annotation, count := range report.UnsupportedIngressAnnotations {
+			addBlocking(annotation, BlockingUnsupported, count)
+		}
+		for annotation, count := range report.UnknownIngressAnnotations {
+			addBlocking(annotation, BlockingUnknown, count)
+		}
+	}
+
+	if totals.IngressCount > 0 {
+		totals.CompatibleIngressPercentage = float64(totals.CompatibleIngressCount) / float64(totals.IngressCount) * 100
+		totals.UnsupportedIngressPercentage = float64(totals.UnsupportedIngressCount) / float64(totals.IngressCount) * 100
+	}
+
+	annotations := make([]BlockingAnnotation, 0, len(blocking))
+	for _, b := range blocking {
+		annotations = append(annotations, *b)
+	}
+
+	// Most used first, then by name so that the order is deterministic.
+	slices.SortFunc(annotations, func(a, b BlockingAnnotation) int {
+		return cmp.Or(cmp.Compare(b.IngressCount, a.IngressCount), cmp.Compare(a.Annotation, b.Annotation))
+	})
+
+	return totals, annotations
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregateReports(t *testing.T) {
+	t.Parallel()
+
+	totals, blocking := AggregateReports([]Report{
+		{
+			IngressCount:                  3,
+			CompatibleIngressCount:        2,
+			VanillaIngressCount:           1,
+			SupportedIngressCount:         1,
+			UnsupportedIngressCount:       1,
+			UnsupportedIngressAnnotations: map[string]int{"nginx.ingress.kubernetes.io/configuration-snippet": 1},
+		},
+		{
+			IngressCount:                  1,
+			UnsupportedIngressCount:       1,
+			UnsupportedIngressAnnotations: map[string]int{"nginx.ingress.kubernetes.io/configuration-snippet": 1},
+			UnknownIngressAnnotations:     map[string]int{"nginx.ingress.kubernetes.io/custom-thing": 1},
+		},
+	})
+
+	assert.Equal(t, IngressTotals{
+		IngressCount:                 4,
+		CompatibleIngressCount:       2,
+		CompatibleIngressPercentage:  50,
+		VanillaIngressCount:          1,
+		SupportedIngressCount:        1,
+		UnsupportedIngressCount:      2,
+		UnsupportedIngressPercentage: 50,
+	}, totals)
+	assert.Equal(t, []BlockingAnnotation{
+		{Annotation: "nginx.ingress.kubernetes.io/configuration-snippet", Kind: BlockingUnsupported, IngressCount: 2, ClusterCount: 2},
+		{Annotation: "nginx.ingress.kubernetes.io/custom-thing", Kind: BlockingUnknown, IngressCount: 1, ClusterCount: 1},
+	}, blocking)
+
+	totals, blocking = AggregateReports(nil)
+	assert.Zero(t, totals)
+	assert.NotNil(t, blocking)
+}
@@ -0,0 +1,261 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	annotationDefaultIngressClass = "ingressclass.kubernetes.io/is-default-class"
+	traefikControllerName         = "traefik.io/ingress-controller"
+)
+
+// IngressClassTopology describes the IngressClasses of the cluster, the Ingresses they
+// own, and how Traefik takes over the Ingresses of the NGINX ingress controller.
+type IngressClassTopology struct {
+	IngressClasses []IngressClassReport `json:"ingressClasses"`
+	// Default is the IngressClass Kubernetes assigns to the Ingresses created without class
+	// from now on. The existing Ingresses without class keep none.
+	Default string `json:"default,omitempty"`
+	// WithoutClassIngressCount counts the Ingresses without class nor class annotation.
+	WithoutClassIngressCount int `json:"withoutClassIngressCount"`
+	// WithoutClassIngressCountByNamespace breaks WithoutClassIngressCount down by namespace,
+	// so that FilterReport counts the Ingresses of the allowed namespaces only.
+	WithoutClassIngressCountByNamespace map[string]int `json:"withoutClassIngressCountByNamespace,omitempty"`
+	// Takeover explains how Traefik must be configured to take over the Ingresses of the
+	// NGINX ingress controller.
+	Takeover []string `json:"takeover"`
+}
+
+// IngressClassReport is an IngressClass and the number of Ingresses it owns.
+type IngressClassReport struct {
+	Name       string `json:"name"`
+	Controller string `json:"controller"`
+	// Parameters references the parameters of the IngressClass, as "Kind.group [namespace/]name".
+	Parameters string `json:"parameters,omitempty"`
+	Default    bool   `json:"default"`
+	// NGINX is true for the IngressClasses of the analyzed NGINX ingress controller.
+	NGINX bool `json:"nginx"`
+	// IngressCount counts the Ingresses referencing the IngressClass by name, with
+	// ingressClassName or the class annotation.
+	IngressCount int `json:"ingressCount"`
+	// IngressCountByNamespace breaks IngressCount down by namespace.
+	IngressCountByNamespace map[string]int `json:"ingressCountByNamespace,omitempty"`
+}
+
+// defaultIngressClass returns the IngressClass Kubernetes assigns to the Ingresses created
+// without class: among the IngressClasses marked default, the most recently created one.
+func defaultIngressClass(ingressClasses []*netv1.IngressClass) *netv1.IngressClass {
+	defaults := defaultIngressClasses(ingressClasses)
+	if len(defaults) == 0 {
+		return nil
+	}
+	return defaults[0]
+}
+
+// defaultIngressClasses returns the IngressClasses marked default, most recent first.
+func defaultIngressClasses(ingressClasses []*netv1.IngressClass) []*netv1.IngressClass {
+	var defaults []*netv1.IngressClass
+	for _, ic := range ingressClasses {
+		if ic.Annotations[annotationDefaultIngressClass] == "true" {
+			defaults = append(defaults, ic)
+		}
+	}
+
+	slices.SortFunc(defaults, func(a, b *netv1.IngressClass) int {
+		return cmp.Or(b.CreationTimestamp.Compare(a.CreationTimestamp.Time), cmp.Compare(a.Name, b.Name))
+	})
+
+	return defaults
+}
+
+// ingressClassOwner returns the name of the IngressClass owning the Ingress, empty when
+// the Ingress has no class. The default IngressClass does not own the existing Ingresses
+// without class, Kubernetes only assigning it on creation.
+func ingressClassOwner(ing *netv1.Ingress) string {
+	if ing.Spec.IngressClassName != nil {
+		return *ing.Spec.IngressClassName
+	}
+	return ing.Annotations[annotationIngressClass]
+}
+
+// ingressClassTopology returns the IngressClass topology of the cluster.
+func (a *Analyzer) ingressClassTopology(ingressClasses []*netv1.IngressClass, ingresses []*netv1.Ingress, nginxIngressClasses []*netv1.IngressClass) IngressClassTopology {
+	defaultClass := defaultIngressClass(ingressClasses)
+
+	topology := IngressClassTopology{
+		IngressClasses: make([]IngressClassReport, 0, len(ingressClasses)),
+	}
+	if defaultClass != nil {
+		topology.Default = defaultClass.Name
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, ing := range ingresses {
+		owner := ingressClassOwner(ing)
+		if owner == "" {
+			topology.WithoutClassIngressCount++
+			topology.WithoutClassIngressCountByNamespace = addCount(topology.WithoutClassIngressCountByNamespace, ing.Namespace)
+			continue
+		}
+		counts[owner] = addCount(counts[owner], ing.Namespace)
+	}
+
+	for _, ic := range ingressClasses {
+		topology.IngressClasses = append(topology.IngressClasses, IngressClassReport{
+			Name:                    ic.Name,
+			Controller:              ic.Spec.Controller,
+			Parameters:              ingressClassParameters(ic),
+			Default:                 ic.Annotations[annotationDefaultIngressClass] == "true",
+			NGINX:                   slices.Contains(nginxIngressClasses, ic),
+			IngressCount:            sumCounts(counts[ic.Name]),
+			IngressCountByNamespace: counts[ic.Name],
+		})
+	}
+	slices.SortFunc(topology.IngressClasses, func(a, b IngressClassReport) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	topology.Takeover = a.takeover(ingressClasses, nginxIngressClasses, topology)
+
+	return topology
+}
+
+// takeover explains how Traefik must be configured to take over the Ingresses of the
+// NGINX ingress controller.
+func (a *Analyzer) takeover(ingressClasses, nginxIngressClasses []*netv1.IngressClass, topology IngressClassTopology) []string {
+	var nginxNames []string
+	for _, ic := range nginxIngressClasses {
+		nginxNames = append(nginxNames, ic.Name)
+	}
+
+	options := []string{"ingressClass=" + a.ingressClass, "controllerClass=" + a.controllerClass}
+	if a.ingressClassByName {
+		options = append(options, "ingressClassByName=true")
+	}
+	if a.watchIngressWithoutClass {
+		options = append(options, "watchIngressWithoutClass=true")
+	}
+
+	var steps []string
+	switch {
+	case len(nginxNames) == 0:
+		steps = append(steps, fmt.Sprintf("Configure the kubernetesIngressNGINX provider of Traefik with %s, the options of the NGINX ingress controller, so that Traefik serves the same Ingresses. No IngressClass has the controller %s: only the Ingresses with the class annotation %q are served.",
+			strings.Join(options, ", "), a.controllerClass, a.ingressClass))
+	default:
+		steps = append(steps, fmt.Sprintf("Configure the kubernetesIngressNGINX provider of Traefik with %s, the options of the NGINX ingress controller, so that Traefik serves the Ingresses of the IngressClasses %s while both controllers run.",
+			strings.Join(options, ", "), strings.Join(nginxNames, ", ")))
+	}
+
+	if defaults := defaultIngressClasses(ingressClasses); len(defaults) > 1 {
+		var names []string
+		for _, ic := range defaults {
+			names = append(names, ic.Name)
+		}
+		steps = append(steps, fmt.Sprintf("Several IngressClasses are marked default (%s): Kubernetes assigns the Ingresses created without class to the most recent one, %s. Keep a single default IngressClass.",
+			strings.Join(names, ", "), topology.Default))
+	}
+
+	switch {
+	case topology.Default != "" && slices.Contains(nginxNames, topology.Default):
+		steps = append(steps, fmt.Sprintf("The IngressClass %s is the default one: the Ingresses created without class from now on are assigned to it, and the kubernetesIngressNGINX provider serves them. Move the %s annotation to the IngressClass of Traefik only when the NGINX IngressClasses are removed, so that there is a single default IngressClass.",
+			topology.Default, annotationDefaultIngressClass))
+	case topology.Default != "":
+		steps = append(steps, fmt.Sprintf("The default IngressClass %s is not handled by the NGINX ingress controller: the Ingresses created without class from now on are not part of the migration.", topology.Default))
+	}
+
+	if topology.WithoutClassIngressCount > 0 && a.watchIngressWithoutClass {
+		steps = append(steps, "The existing Ingresses without class are only served because of the watch-ingress-without-class option, whatever the default IngressClass: set watchIngressWithoutClass=true on the provider, or set their ingressClassName.")
+	}
+
+	traefikClass := slices.IndexFunc(topology.IngressClasses, func(ic IngressClassReport) bool {
+		return ic.Controller == traefikControllerName
+	})
+	switch {
+	case traefikClass >= 0:
+		steps = append(steps, fmt.Sprintf("The IngressClass %s of Traefik exists: switching the ingressClassName of the Ingresses to it moves them to the kubernetesIngress provider, once they are compatible.",
+			topology.IngressClasses[traefikClass].Name))
+	default:
+		steps = append(steps, fmt.Sprintf("Create an IngressClass with the controller %s to move the Ingresses to the kubernetesIngress provider once they are compatible, and eventually remove the NGINX ingress controller.", traefikControllerName))
+	}
+
+	return steps
+}
+
+// filterTopology returns the topology with the Ingress counts restricted to the namespaces
+// for which allowed returns true.
+func filterTopology(topology *IngressClassTopology, allowed func(namespace string) bool) *IngressClassTopology {
+	if topology == nil {
+		return nil
+	}
+
+	filtered := *topology
+	filtered.WithoutClassIngressCountByNamespace = filterCounts(topology.WithoutClassIngressCountByNamespace, allowed)
+	filtered.WithoutClassIngressCount = sumCounts(filtered.WithoutClassIngressCountByNamespace)
+
+	filtered.IngressClasses = slices.Clone(topology.IngressClasses)
+	for i, ic := range filtered.IngressClasses {
+		ic.IngressCountByNamespace = filterCounts(ic.IngressCountByNamespace, allowed)
+		ic.IngressCount = sumCounts(ic.IngressCountByNamespace)
+		filtered.IngressClasses[i] = ic
+	}
+
+	return &filtered
+}
+
+func addCount(counts map[string]int, namespace string) map[string]int {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	counts[namespace]++
+
+	return counts
+}
+
+func filterCounts(counts map[string]int, allowed func(namespace string) bool) map[string]int {
+	var filtered map[string]int
+	for namespace, count := range counts {
+		if !allowed(namespace) {
+			continue
+		}
+		if filtered == nil {
+			filtered = make(map[string]int)
+		}
+		filtered[namespace] = count
+	}
+
+	return filtered
+}
+
+func sumCounts(counts map[string]int) int {
+	var sum int
+	for _, count := range counts {
+		sum += count
+	}
+
+	return sum
+}
+
+// ingressClassParameters returns the parameters of the IngressClass as "Kind.group [namespace/]name".
+func ingressClassParameters(ic *netv1.IngressClass) string {
+	params := ic.Spec.Parameters
+	if params == nil {
+		return ""
+	}
+
+	kind := params.Kind
+	if params.APIGroup != nil && *params.APIGroup != "" {
+		kind += "." + *params.APIGroup
+	}
+
+	name := params.Name
+	if params.Namespace != nil && *params.Namespace != "" {
+		name = *params.Namespace + "/" + name
+	}
+
+	return kind + " " + name
+}
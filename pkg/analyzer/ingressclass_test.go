@@ -0,0 +1,165 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestComputeReport_DefaultIngressClass(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ingressClass := func(name, controller string, isDefault bool) *netv1.IngressClass {
+		created = created.Add(time.Minute)
+		ic := &netv1.IngressClass{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+			Spec:       netv1.IngressClassSpec{Controller: controller},
+		}
+		if isDefault {
+			ic.Annotations = map[string]string{"ingressclass.kubernetes.io/is-default-class": "true"}
+		}
+		return ic
+	}
+
+	ingress := func(name string, className *string, annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       netv1.IngressSpec{IngressClassName: className},
+		}
+	}
+
+	ingresses := []*netv1.Ingress{
+		ingress("nginx", new("nginx"), nil),
+		ingress("traefik", new("traefik"), nil),
+		ingress("annotated", nil, map[string]string{"kubernetes.io/ingress.class": "nginx"}),
+		ingress("without-class", nil, nil),
+	}
+
+	nginxClass := ingressClass("nginx", "k8s.io/ingress-nginx", true)
+	nginxClass.Spec.Parameters = &netv1.IngressClassParametersReference{
+		APIGroup:  new("k8s.example.com"),
+		Kind:      "IngressParameters",
+		Name:      "external-lb",
+		Namespace: new("ingress-nginx"),
+	}
+
+	tests := []struct {
+		desc           string
+		ingressClasses []*netv1.IngressClass
+		watchWithout   bool
+		wantAnalyzed   map[string]string
+		wantTopology   IngressClassTopology
+	}{
+		{
+			desc:           "NGINX default class",
+			ingressClasses: []*netv1.IngressClass{nginxClass, ingressClass("traefik", "traefik.io/ingress-controller", false)},
+			// The existing Ingress without class is not assigned to the default IngressClass.
+			wantAnalyzed: map[string]string{"nginx": "nginx", "annotated": "nginx"},
+			wantTopology: IngressClassTopology{
+				IngressClasses: []IngressClassReport{
+					{Name: "nginx", Controller: "k8s.io/ingress-nginx", Parameters: "IngressParameters.k8s.example.com ingress-nginx/external-lb", Default: true, NGINX: true, IngressCount: 2, IngressCountByNamespace: map[string]int{"default": 2}},
+					{Name: "traefik", Controller: "traefik.io/ingress-controller", IngressCount: 1, IngressCountByNamespace: map[string]int{"default": 1}},
+				},
+				Default:                             "nginx",
+				WithoutClassIngressCount:            1,
+				WithoutClassIngressCountByNamespace: map[string]int{"default": 1},
+				Takeover: []string{
+					"Configure the kubernetesIngressNGINX provider of Traefik with ingressClass=nginx, controllerClass=k8s.io/ingress-nginx, the options of the NGINX ingress controller, so that Traefik serves the Ingresses of the IngressClasses nginx while both controllers run.",
+					"The IngressClass nginx is the default one: the Ingresses created without class from now on are assigned to it, and the kubernetesIngressNGINX provider serves them. Move the ingressclass.kubernetes.io/is-default-class annotation to the IngressClass of Traefik only when the NGINX IngressClasses are removed, so that there is a single default IngressClass.",
+					"The IngressClass traefik of Traefik exists: switching the ingressClassName of the Ingresses to it moves them to the kubernetesIngress provider, once they are compatible.",
+				},
+			},
+		},
+		{
+			desc:           "other default class",
+			ingressClasses: []*netv1.IngressClass{ingressClass("nginx", "k8s.io/ingress-nginx", false), ingressClass("traefik", "traefik.io/ingress-controller", true)},
+			watchWithout:   true,
+			wantAnalyzed:   map[string]string{"nginx": "nginx", "annotated": "nginx", "without-class": WithoutClass},
+			wantTopology: IngressClassTopology{
+				IngressClasses: []IngressClassReport{
+					{Name: "nginx", Controller: "k8s.io/ingress-nginx", NGINX: true, IngressCount: 2, IngressCountByNamespace: map[string]int{"default": 2}},
+					{Name: "traefik", Controller: "traefik.io/ingress-controller", Default: true, IngressCount: 1, IngressCountByNamespace: map[string]int{"default": 1}},
+				},
+				Default:                             "traefik",
+				WithoutClassIngressCount:            1,
+				WithoutClassIngressCountByNamespace: map[string]int{"default": 1},
+				Takeover: []string{
+					"Configure the kubernetesIngressNGINX provider of Traefik with ingressClass=nginx, controllerClass=k8s.io/ingress-nginx, watchIngressWithoutClass=true, the options of the NGINX ingress controller, so that Traefik serves the Ingresses of the IngressClasses nginx while both controllers run.",
+					"The default IngressClass traefik is not handled by the NGINX ingress controller: the Ingresses created without class from now on are not part of the migration.",
+					"The existing Ingresses without class are only served because of the watch-ingress-without-class option, whatever the default IngressClass: set watchIngressWithoutClass=true on the provider, or set their ingressClassName.",
+					"The IngressClass traefik of Traefik exists: switching the ingressClassName of the Ingresses to it moves them to the kubernetesIngress provider, once they are compatible.",
+				},
+			},
+		},
+		{
+			desc: "several default classes",
+			ingressClasses: []*netv1.IngressClass{
+				ingressClass("traefik", "traefik.io/ingress-controller", true),
+				ingressClass("nginx", "k8s.io/ingress-nginx", true),
+			},
+			wantAnalyzed: map[string]string{"nginx": "nginx", "annotated": "nginx"},
+			wantTopology: IngressClassTopology{
+				IngressClasses: []IngressClassReport{
+					{Name: "nginx", Controller: "k8s.io/ingress-nginx", Default: true, NGINX: true, IngressCount: 2, IngressCountByNamespace: map[string]int{"default": 2}},
+					{Name: "traefik", Controller: "traefik.io/ingress-controller", Default: true, IngressCount: 1, IngressCountByNamespace: map[string]int{"default": 1}},
+				},
+				Default:                             "nginx",
+				WithoutClassIngressCount:            1,
+				WithoutClassIngressCountByNamespace: map[string]int{"default": 1},
+				Takeover: []string{
+					"Configure the kubernetesIngressNGINX provider of Traefik with ingressClass=nginx, controllerClass=k8s.io/ingress-nginx, the options of the NGINX ingress controller, so that Traefik serves the Ingresses of the IngressClasses nginx while both controllers run.",
+					"Several IngressClasses are marked default (nginx, traefik): Kubernetes assigns the Ingresses created without class to the most recent one, nginx. Keep a single default IngressClass.",
+					"The IngressClass nginx is the default one: the Ingresses created without class from now on are assigned to it, and the kubernetesIngressNGINX provider serves them. Move the ingressclass.kubernetes.io/is-default-class annotation to the IngressClass of Traefik only when the NGINX IngressClasses are removed, so that there is a single default IngressClass.",
+					"The IngressClass traefik of Traefik exists: switching the ingressClassName of the Ingresses to it moves them to the kubernetesIngress provider, once they are compatible.",
+				},
+			},
+		},
+		{
+			desc:           "no default class",
+			ingressClasses: []*netv1.IngressClass{ingressClass("nginx", "k8s.io/ingress-nginx", false)},
+			watchWithout:   true,
+			wantAnalyzed:   map[string]string{"nginx": "nginx", "annotated": "nginx", "without-class": WithoutClass},
+			wantTopology: IngressClassTopology{
+				IngressClasses: []IngressClassReport{
+					{Name: "nginx", Controller: "k8s.io/ingress-nginx", NGINX: true, IngressCount: 2, IngressCountByNamespace: map[string]int{"default": 2}},
+				},
+				WithoutClassIngressCount:            1,
+				WithoutClassIngressCountByNamespace: map[string]int{"default": 1},
+				Takeover: []string{
+					"Configure the kubernetesIngressNGINX provider of Traefik with ingressClass=nginx, controllerClass=k8s.io/ingress-nginx, watchIngressWithoutClass=true, the options of the NGINX ingress controller, so that Traefik serves the Ingresses of the IngressClasses nginx while both controllers run.",
+					"The existing Ingresses without class are only served because of the watch-ingress-without-class option, whatever the default IngressClass: set watchIngressWithoutClass=true on the provider, or set their ingressClassName.",
+					"Create an IngressClass with the controller traefik.io/ingress-controller to move the Ingresses to the kubernetesIngress provider once they are compatible, and eventually remove the NGINX ingress controller.",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			a := &Analyzer{
+				ingressClass:             "nginx",
+				controllerClass:          "k8s.io/ingress-nginx",
+				watchIngressWithoutClass: test.watchWithout,
+			}
+
+			report := a.computeReport(test.ingressClasses, ingresses)
+
+			analyzed := make(map[string]string)
+			for _, ing := range report.CompatibleIngresses {
+				analyzed[ing.Name] = ing.Class
+				assert.Equal(t, ing.Name == "without-class", ing.ClassLess, ing.Name)
+			}
+			assert.Equal(t, test.wantAnalyzed, analyzed)
+
+			require.NotNil(t, report.IngressClassTopology)
+			assert.Equal(t, test.wantTopology, *report.IngressClassTopology)
+		})
+	}
+}
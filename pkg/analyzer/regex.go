@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// Kinds of regex path warning.
+const (
+	// RegexPCREOnly is a regex path using a PCRE construct that Go regexes do not support.
+	RegexPCREOnly = "pcre-only"
+	// RegexInvalid is a regex path that Go regexes cannot compile.
+	RegexInvalid = "invalid"
+	// RegexForced is a path that NGINX matches as a regex because another Ingress
+	// of the host enables regex paths.
+	RegexForced = "forced-regex"
+	// RegexOrdering is a regex path whose priority may differ between NGINX and Traefik.
+	RegexOrdering = "ordering"
+)
+
+// RegexWarning is a difference between the NGINX (PCRE) and the Traefik (Go RE2)
+// matching of a path of an Ingress.
+type RegexWarning struct {
+	Host string `json:"host,omitempty"`
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Ingress is the other Ingress involved, as "namespace/name", if any.
+	Ingress string `json:"ingress,omitempty"`
+	Message string `json:"message"`
+}
+
+// RegexPathError returns an error when the regex path, which NGINX matches with PCRE,
+// cannot be matched by Traefik with a Go regex.
+func RegexPathError(path string) error {
+	if constructs := PCREOnlyConstructs(path); len(constructs) > 0 {
+		return fmt.Errorf("Go regexes do not support the PCRE %s", joinList(constructs))
+	}
+
+	// NGINX regex locations are case-insensitive and anchored at the start of the path.
+	if _, err := regexp.Compile("(?i)^" + path); err != nil {
+		return fmt.Errorf("invalid Go regex: %w", err)
+	}
+
+	return nil
+}
+
+// PCREOnlyConstructs returns the constructs of the PCRE pattern that Go regexes do
+// not support, in order of appearance and without duplicates.
+func PCREOnlyConstructs(pattern string) []string {
+	var constructs []string
+	add := func(construct string) {
+		if !slices.Contains(constructs, construct) {
+			constructs = append(constructs, construct)
+		}
+	}
+
+	// quantified is true after a quantifier, for a following + to make it possessive.
+	var inClass, quantified bool
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		wasQuantified := quantified
+		quantified = false
+
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			i++
+			if inClass {
+				continue
+			}
+			switch e := pattern[i]; {
+			case e >= '1' && e <= '9':
+				add("backreferences")
+			case e == 'k' || e == 'g':
+				add("backreferences")
+			case e == 'K':
+				add(`\K match resets`)
+			case e == 'G':
+				add(`\G anchors`)
+			case e == 'R' || e == 'X':
+				add(`\` + string(e) + " sequences")
+			}
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			// A ] right after the opening bracket is part of the class.
+			if strings.HasPrefix(pattern[i:], "[^]") {
+				i += 2
+			} else if strings.HasPrefix(pattern[i:], "[]") {
+				i++
+			}
+		case c == '(' && strings.HasPrefix(pattern[i:], "(?"):
+			switch rest := pattern[i+2:]; {
+			case strings.HasPrefix(rest, "="), strings.HasPrefix(rest, "!"):
+				add("lookaheads")
+			case strings.HasPrefix(rest, "<="), strings.HasPrefix(rest, "<!"):
+				add("lookbehinds")
+			case strings.HasPrefix(rest, ">"):
+				add("atomic groups")
+			case strings.HasPrefix(rest, "("):
+				add("conditionals")
+			case strings.HasPrefix(rest, "|"):
+				add("branch resets")
+			case strings.HasPrefix(rest, "#"):
+				add("comments")
+			case strings.HasPrefix(rest, "R"), strings.HasPrefix(rest, "&"), strings.HasPrefix(rest, "P>"),
+				len(rest) > 0 && (rest[0] >= '0' && rest[0] <= '9' || rest[0] == '+' || rest[0] == '-' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9'):
+				add("recursions")
+			}
+		case c == '+' && wasQuantified:
+			add("possessive quantifiers")
+		case c == '*' || c == '+' || c == '?' || c == '}':
+			quantified = true
+		}
+	}
+
+	return constructs
+}
+
+// regexMode returns whether NGINX matches the paths of the Ingress as regexes.
+func regexMode(ing *netv1.Ingress) bool {
+	_, hasRewrite := ing.Annotations["nginx.ingress.kubernetes.io/rewrite-target"]
+	return ing.Annotations["nginx.ingress.kubernetes.io/use-regex"] == "true" || hasRewrite
+}
+
+// regexPath is a path of an Ingress that NGINX matches as a regex.
+type regexPath struct {
+	report *IngressReport
+	host   string
+	path   string
+
+	// forcedBy is the Ingress enabling the regex paths of the host, when the Ingress
+	// of the path does not.
+	forcedBy string
+}
+
+// analyzeRegexPaths adds the regex warnings to the reports of the ingresses, in the same order.
+//
+// NGINX matches the regex locations with PCRE, case-insensitively, and every path of
+// a host is a regex as soon as one Ingress of the host enables regex paths. The regex
+// locations of a server are tried by decreasing path length, the first match wins.
+func analyzeRegexPaths(ingresses []*netv1.Ingress, reports []*IngressReport) {
+	// Hosts with at least one Ingress enabling regex paths.
+	regexHosts := make(map[string]string)
+	for _, ing := range ingresses {
+		if !regexMode(ing) {
+			continue
+		}
+		for _, rule := range ing.Spec.Rules {
+			if _, ok := regexHosts[rule.Host]; !ok {
+				regexHosts[rule.Host] = ing.Namespace + "/" + ing.Name
+			}
+		}
+	}
+
+	byHost := make(map[string][]regexPath)
+	for i, ing := range ingresses {
+		regex := regexMode(ing)
+
+		for _, rule := range ing.Spec.Rules {
+			forcedBy, ok := regexHosts[rule.Host]
+			if !ok || rule.HTTP == nil {
+				continue
+			}
+			if regex {
+				forcedBy = ""
+			}
+
+			for _, path := range rule.HTTP.Paths {
+				// NGINX keeps the exact locations of the regex servers.
+				if path.PathType != nil && *path.PathType == netv1.PathTypeExact {
+					continue
+				}
+
+				p := regexPath{report: reports[i], host: rule.Host, path: cmp.Or(path.Path, "/"), forcedBy: forcedBy}
+				byHost[rule.Host] = append(byHost[rule.Host], p)
+
+				if forcedBy != "" {
+					p.warn(RegexForced, forcedBy, fmt.Sprintf("NGINX matches the path as a case-insensitive regex because the Ingress %s enables regex paths on the host, Traefik matches it as a prefix", forcedBy))
+					continue
+				}
+
+				switch err := RegexPathError(p.path); {
+				case err == nil:
+				case len(PCREOnlyConstructs(p.path)) > 0:
+					p.warn(RegexPCREOnly, "", err.Error())
+				default:
+					p.warn(RegexInvalid, "", err.Error())
+				}
+			}
+		}
+	}
+
+	for _, paths := range byHost {
+		for i, p := range paths {
+			if p.forcedBy != "" || !hasMetacharacters(p.path) {
+				continue
+			}
+
+			// Regex paths of the same length are tried in the Ingress order by NGINX,
+			// and have the same priority in Traefik.
+			for j, other := range paths {
+				if i == j || other.forcedBy != "" || other.path == p.path || len(other.path) != len(p.path) || !hasMetacharacters(other.path) {
+					continue
+				}
+
+				ref := other.report.Namespace + "/" + other.report.Name
+				p.warn(RegexOrdering, ref, fmt.Sprintf("the regex path has the same length as the path %q of the Ingress %s, NGINX tries them in the Ingress creation order while Traefik gives them the same priority; set the router priorities explicitly", other.path, ref))
+			}
+		}
+	}
+
+	for _, report := range reports {
+		slices.SortStableFunc(report.RegexWarnings, func(a, b RegexWarning) int {
+			return cmp.Or(cmp.Compare(a.Host, b.Host), cmp.Compare(a.Path, b.Path))
+		})
+	}
+}
+
+func (p regexPath) warn(kind, ingress, message string) {
+	p.report.RegexWarnings = append(p.report.RegexWarnings, RegexWarning{Host: p.host, Path: p.path, Kind: kind, Ingress: ingress, Message: message})
+}
+
+// hasMetacharacters returns whether the path contains regex metacharacters, and
+// could match other paths than itself.
+func hasMetacharacters(path string) bool {
+	return regexp.QuoteMeta(path) != path
+}
+
+// joinList joins the items as an English list.
+func joinList(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPCREOnlyConstructs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "/app(/|$)(.*)"},
+		{pattern: "/api/v[0-9]+/users/(?P<id>[^/]+)"},
+		{pattern: "/(?i)Case/(?<name>.*)"},
+		{pattern: `/a\+\+`},
+		{pattern: "/[(?=]"},
+		{pattern: "/(?!admin).*", want: []string{"lookaheads"}},
+		{pattern: "/api(?=/v1)", want: []string{"lookaheads"}},
+		{pattern: "/(?<!x)y(?<=y)", want: []string{"lookbehinds"}},
+		{pattern: `/(a)\1`, want: []string{"backreferences"}},
+		{pattern: `/(?<n>a)\k<n>`, want: []string{"backreferences"}},
+		{pattern: "/a++b*+c?+d{2}+", want: []string{"possessive quantifiers"}},
+		{pattern: "/(?>a|ab)c", want: []string{"atomic groups"}},
+		{pattern: "/(a(?R)?b)", want: []string{"recursions"}},
+		{pattern: "/(?(1)a|b)", want: []string{"conditionals"}},
+		{pattern: `/foo\Kbar`, want: []string{`\K match resets`}},
+		{pattern: `/(?!a)(?>b)\2`, want: []string{"lookaheads", "atomic groups", "backreferences"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, PCREOnlyConstructs(tt.pattern))
+		})
+	}
+}
+
+func TestRegexPathError(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, RegexPathError("/app(/|$)(.*)"))
+	assert.EqualError(t, RegexPathError("/(?!admin)(?>a)"), "Go regexes do not support the PCRE lookaheads and atomic groups")
+	assert.EqualError(t, RegexPathError("/app(.*"), "invalid Go regex: error parsing regexp: missing closing ): `(?i)^/app(.*`")
+}
+
+func TestAnalyzeRegexPaths(t *testing.T) {
+	t.Parallel()
+
+	regexIngress := func(name string, annotations map[string]string, host string, paths ...string) *netv1.Ingress {
+		var httpPaths []netv1.HTTPIngressPath
+		for _, p := range paths {
+			httpPaths = append(httpPaths, netv1.HTTPIngressPath{Path: p})
+		}
+
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations},
+			Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{{
+				Host:             host,
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{Paths: httpPaths}},
+			}}},
+		}
+	}
+
+	exact := netv1.PathTypeExact
+	plain := regexIngress("plain", nil, "app.example.com", "/Static", "/exact")
+	plain.Spec.Rules[0].HTTP.Paths[1].PathType = &exact
+
+	ingresses := []*netv1.Ingress{
+		regexIngress("regex", map[string]string{"nginx.ingress.kubernetes.io/use-regex": "true"}, "app.example.com", "/(?!admin)", "/api/(v1|v2)"),
+		regexIngress("rewrite", map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/$1"}, "app.example.com", "/api/(v[34])", "/app(.*"),
+		plain,
+		regexIngress("other-host", nil, "other.example.com", "/Static"),
+	}
+
+	reports := make([]*IngressReport, len(ingresses))
+	for i, ing := range ingresses {
+		reports[i] = computeIngressReport(ing)
+	}
+
+	analyzeRegexPaths(ingresses, reports)
+
+	assert.Equal(t, []RegexWarning{
+		{
+			Host:    "app.example.com",
+			Path:    "/(?!admin)",
+			Kind:    RegexPCREOnly,
+			Message: "Go regexes do not support the PCRE lookaheads",
+		},
+		{
+			Host:    "app.example.com",
+			Path:    "/api/(v1|v2)",
+			Kind:    RegexOrdering,
+			Ingress: "default/rewrite",
+			Message: `the regex path has the same length as the path "/api/(v[34])" of the Ingress default/rewrite, NGINX tries them in the Ingress creation order while Traefik gives them the same priority; set the router priorities explicitly`,
+		},
+	}, reports[0].RegexWarnings)
+
+	assert.Equal(t, []RegexWarning{
+		{
+			Host:    "app.example.com",
+			Path:    "/api/(v[34])",
+			Kind:    RegexOrdering,
+			Ingress: "default/regex",
+			Message: `the regex path has the same length as the path "/api/(v1|v2)" of the Ingress default/regex, NGINX tries them in the Ingress creation order while Traefik gives them the same priority; set the router priorities explicitly`,
+		},
+		{
+			Host:    "app.example.com",
+			Path:    "/app(.*",
+			Kind:    RegexInvalid,
+			Message: "invalid Go regex: error parsing regexp: missing closing ): `(?i)^/app(.*`",
+		},
+	}, reports[1].RegexWarnings)
+
+	// The exact paths remain exact, the other paths of the host become regexes.
+	assert.Equal(t, []RegexWarning{{
+		Host:    "app.example.com",
+		Path:    "/Static",
+		Kind:    RegexForced,
+		Ingress: "default/regex",
+		Message: "NGINX matches the path as a case-insensitive regex because the Ingress default/regex enables regex paths on the host, Traefik matches it as a prefix",
+	}}, reports[2].RegexWarnings)
+
+	assert.Empty(t, reports[3].RegexWarnings)
+}
@@ -0,0 +1,671 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Kinds of remediation of an unsupported annotation.
+const (
+	// RemediationMiddleware is a remediation with a Traefik Middleware.
+	RemediationMiddleware = "middleware"
+	// RemediationService is a remediation with a TraefikService or a service option.
+	RemediationService = "service"
+	// RemediationTLSOption is a remediation with a Traefik TLSOption.
+	RemediationTLSOption = "tls-option"
+	// RemediationRouting is a remediation splitting the routes of the Ingress.
+	RemediationRouting = "routing"
+	// RemediationStatic is a remediation in the Traefik static configuration.
+	RemediationStatic = "static-configuration"
+	// RemediationPlugin is a remediation requiring a Traefik plugin.
+	RemediationPlugin = "plugin"
+	// RemediationNotNeeded is the remediation of an annotation whose behavior is the Traefik default.
+	RemediationNotNeeded = "not-needed"
+	// RemediationManual is the remediation of an annotation without Traefik alternative.
+	RemediationManual = "manual"
+)
+
+const traefikAPIVersion = "traefik.io/v1alpha1"
+
+// Remediation is the Traefik alternative to a known-unsupported annotation of an Ingress.
+type Remediation struct {
+	Annotation string `json:"annotation"`
+	Kind       string `json:"kind"`
+	Summary    string `json:"summary"`
+
+	// YAML is the Traefik configuration of the alternative, generated from the
+	// Ingress when possible. It is empty when the alternative has no configuration.
+	YAML string `json:"yaml,omitempty"`
+}
+
+// recipe is the remediation of a known-unsupported annotation.
+type recipe struct {
+	kind    string
+	summary string
+
+	// generate returns the Traefik configuration of the Ingress, or an error when
+	// the annotation value cannot be converted.
+	generate func(ing *netv1.Ingress, value string) (string, error)
+}
+
+// recipes maps every known-unsupported annotation to its remediation.
+var recipes = map[string]recipe{
+	// Authentication.
+	"nginx.ingress.kubernetes.io/auth-tls-error-page": {
+		kind:    RemediationManual,
+		summary: "Traefik rejects the TLS handshake of an invalid client certificate, no error page can be served. Use the VerifyClientCertIfGiven client auth type and redirect the requests without certificate in the application.",
+	},
+	"nginx.ingress.kubernetes.io/auth-tls-match-cn": {
+		kind:     RemediationMiddleware,
+		summary:  "Traefik does not match the client certificate CN. Pass the certificate subject to the backend with a PassTLSClientCert middleware and check the CN in the application.",
+		generate: passCommonName,
+	},
+	"nginx.ingress.kubernetes.io/auth-cache-key": {
+		kind:    RemediationManual,
+		summary: "The ForwardAuth middleware does not cache the authentication responses. Cache them in the authentication service.",
+	},
+	"nginx.ingress.kubernetes.io/auth-cache-duration": {
+		kind:    RemediationManual,
+		summary: "The ForwardAuth middleware does not cache the authentication responses. Cache them in the authentication service.",
+	},
+	"nginx.ingress.kubernetes.io/auth-keepalive": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik keeps the connections to the authentication service alive by default. Remove the annotation.",
+	},
+	"nginx.ingress.kubernetes.io/auth-keepalive-share-vars": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik keeps the connections to the authentication service alive by default. Remove the annotation.",
+	},
+	"nginx.ingress.kubernetes.io/auth-keepalive-requests": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik keeps the connections to the authentication service alive by default. Remove the annotation.",
+	},
+	"nginx.ingress.kubernetes.io/auth-keepalive-timeout": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik keeps the connections to the authentication service alive by default. Remove the annotation.",
+	},
+	"nginx.ingress.kubernetes.io/auth-proxy-set-headers": {
+		kind:     RemediationMiddleware,
+		summary:  "Set the headers of the ConfigMap with a Headers middleware placed before the ForwardAuth middleware, which forwards them to the authentication service.",
+		generate: proxySetHeaders,
+	},
+	"nginx.ingress.kubernetes.io/enable-global-auth": {
+		kind:     RemediationStatic,
+		summary:  "Attach the global ForwardAuth middleware to the entry point in the static configuration. The Ingresses opting out of the global authentication must use another entry point.",
+		generate: globalAuth,
+	},
+	// Error handling.
+	"nginx.ingress.kubernetes.io/disable-proxy-intercept-errors": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik only intercepts the backend errors with an Errors middleware. Do not attach one to the routes of the Ingress.",
+	},
+	// Rate limiting.
+	"nginx.ingress.kubernetes.io/limit-rate-after": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not limit the response bandwidth. Use a bandwidth limiting plugin from the Traefik plugin catalog.",
+	},
+	"nginx.ingress.kubernetes.io/limit-rate": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not limit the response bandwidth. Use a bandwidth limiting plugin from the Traefik plugin catalog.",
+	},
+	"nginx.ingress.kubernetes.io/limit-whitelist": {
+		kind:     RemediationRouting,
+		summary:  "The RateLimit middleware does not exempt clients. Route the exempted source ranges with a ClientIP matcher to a route without the RateLimit middleware.",
+		generate: exemptionRoute,
+	},
+	"nginx.ingress.kubernetes.io/limit-connections": {
+		kind:     RemediationMiddleware,
+		summary:  "Limit the simultaneous requests per client IP with an InFlightReq middleware.",
+		generate: inFlightReq,
+	},
+	"nginx.ingress.kubernetes.io/global-rate-limit": {
+		kind:     RemediationMiddleware,
+		summary:  "Share the rate limit between the Traefik instances with a RateLimit middleware backed by Redis.",
+		generate: globalRateLimit,
+	},
+	"nginx.ingress.kubernetes.io/global-rate-limit-window": {
+		kind:    RemediationMiddleware,
+		summary: "Set the window as the period of the Redis-backed RateLimit middleware generated for global-rate-limit.",
+	},
+	"nginx.ingress.kubernetes.io/global-rate-limit-key": {
+		kind:    RemediationMiddleware,
+		summary: "Set the key as the source criterion of the Redis-backed RateLimit middleware: requestHeaderName for $http_ variables, requestHost for $host, the client IP by default.",
+	},
+	"nginx.ingress.kubernetes.io/global-rate-limit-ignored-cidrs": {
+		kind:     RemediationRouting,
+		summary:  "The RateLimit middleware does not exempt clients. Route the ignored source ranges with a ClientIP matcher to a route without the RateLimit middleware.",
+		generate: exemptionRoute,
+	},
+	// Path handling.
+	"nginx.ingress.kubernetes.io/preserve-trailing-slash": {
+		kind:    RemediationNotNeeded,
+		summary: "The Traefik HTTPS redirections keep the request path as is. Remove the annotation.",
+	},
+	// Proxy / backend.
+	"nginx.ingress.kubernetes.io/proxy-cookie-domain": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not rewrite the Set-Cookie headers of the responses. Use a response header rewriting plugin from the Traefik plugin catalog, or set the domain in the application.",
+	},
+	"nginx.ingress.kubernetes.io/proxy-cookie-path": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not rewrite the Set-Cookie headers of the responses. Use a response header rewriting plugin from the Traefik plugin catalog, or set the path in the application.",
+	},
+	"nginx.ingress.kubernetes.io/proxy-redirect-from": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not rewrite the Location headers of the responses. Use a response header rewriting plugin from the Traefik plugin catalog, or fix the redirections in the application.",
+	},
+	"nginx.ingress.kubernetes.io/proxy-redirect-to": {
+		kind:    RemediationPlugin,
+		summary: "Traefik does not rewrite the Location headers of the responses. Use a response header rewriting plugin from the Traefik plugin catalog, or fix the redirections in the application.",
+	},
+	// TLS / SSL (backend).
+	"nginx.ingress.kubernetes.io/proxy-ssl-ciphers": {
+		kind:    RemediationManual,
+		summary: "The ServersTransport does not configure the cipher suites offered to the backends, Traefik offers the Go defaults. Restrict the cipher suites on the backends.",
+	},
+	"nginx.ingress.kubernetes.io/proxy-ssl-verify-depth": {
+		kind:    RemediationManual,
+		summary: "Traefik verifies the whole backend certificate chain against the ServersTransport root CAs, without depth limit.",
+	},
+	"nginx.ingress.kubernetes.io/proxy-ssl-protocols": {
+		kind:    RemediationManual,
+		summary: "The ServersTransport does not configure the TLS versions offered to the backends, Traefik offers TLS 1.2 and 1.3. Restrict the versions on the backends.",
+	},
+	// Rewriting.
+	"nginx.ingress.kubernetes.io/enable-rewrite-log": {
+		kind:     RemediationStatic,
+		summary:  "Traefik logs the routing decisions at the DEBUG level, and the rewritten paths in the access logs.",
+		generate: debugLogs,
+	},
+	// Access control.
+	"nginx.ingress.kubernetes.io/satisfy": {
+		kind:     RemediationRouting,
+		summary:  "Traefik applies every middleware of a route. With satisfy any, route the allowed source ranges with a ClientIP matcher to a route without the authentication middleware.",
+		generate: satisfyAny,
+	},
+	"nginx.ingress.kubernetes.io/denylist-source-range": {
+		kind:     RemediationMiddleware,
+		summary:  "Allow the complement of the denied source ranges with an IPAllowList middleware, or use a deny list plugin from the Traefik plugin catalog.",
+		generate: denyList,
+	},
+	// Session affinity.
+	"nginx.ingress.kubernetes.io/session-cookie-conditional-samesite-none": {
+		kind:    RemediationService,
+		summary: "Traefik sets the SameSite attribute of the sticky cookie for every browser. Set sameSite to none on the sticky cookie of the service.",
+	},
+	"nginx.ingress.kubernetes.io/session-cookie-change-on-failure": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik sends the requests of an unavailable sticky server to another server and updates the cookie. Remove the annotation.",
+	},
+	// TLS / SSL (ingress).
+	"nginx.ingress.kubernetes.io/ssl-ciphers": {
+		kind:     RemediationTLSOption,
+		summary:  "Restrict the cipher suites with a TLSOption, referenced by the TLS routes of the Ingress. The OpenSSL names are mapped to the Go names.",
+		generate: tlsCiphers,
+	},
+	"nginx.ingress.kubernetes.io/ssl-prefer-server-ciphers": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik selects the cipher suite of the connection, whatever the client preference. Remove the annotation.",
+	},
+	// Connection.
+	"nginx.ingress.kubernetes.io/connection-proxy-header": {
+		kind:    RemediationNotNeeded,
+		summary: "Traefik manages the Connection header of the forwarded requests, and keeps the upgrades. Remove the annotation.",
+	},
+	// Observability / tracing.
+	"nginx.ingress.kubernetes.io/enable-opentracing": {
+		kind:     RemediationStatic,
+		summary:  "Enable the tracing in the static configuration, Traefik exports the traces with OpenTelemetry. The routes can opt out with their observability options.",
+		generate: tracing,
+	},
+	"nginx.ingress.kubernetes.io/opentracing-trust-incoming-span": {
+		kind:     RemediationMiddleware,
+		summary:  "Traefik continues the incoming trace context. Not to trust it, remove the trace context headers with a Headers middleware.",
+		generate: distrustSpan,
+	},
+	"nginx.ingress.kubernetes.io/enable-opentelemetry": {
+		kind:     RemediationStatic,
+		summary:  "Enable the OpenTelemetry tracing in the static configuration. The routes can opt out with their observability options.",
+		generate: tracing,
+	},
+	"nginx.ingress.kubernetes.io/opentelemetry-trust-incoming-span": {
+		kind:     RemediationMiddleware,
+		summary:  "Traefik continues the incoming trace context. Not to trust it, remove the trace context headers with a Headers middleware.",
+		generate: distrustSpan,
+	},
+	// Traffic mirroring.
+	"nginx.ingress.kubernetes.io/mirror-request-body": {
+		kind:    RemediationService,
+		summary: "Set mirrorBody on the mirroring TraefikService generated for mirror-target.",
+	},
+	"nginx.ingress.kubernetes.io/mirror-target": {
+		kind:     RemediationService,
+		summary:  "Mirror the requests with a mirroring TraefikService, to an ExternalName Service of the mirror target. Route the Ingress to the TraefikService.",
+		generate: mirroring,
+	},
+	"nginx.ingress.kubernetes.io/mirror-host": {
+		kind:    RemediationManual,
+		summary: "Traefik mirrors the requests with their Host header. The mirror target must serve the hosts of the Ingress.",
+	},
+	// Streaming.
+	"nginx.ingress.kubernetes.io/stream-snippet": {
+		kind:    RemediationManual,
+		summary: "Declare the TCP and UDP entry points in the static configuration, and route them with IngressRouteTCP and IngressRouteUDP resources.",
+	},
+}
+
+// RemediationSummary returns the kind and summary of the remediation of a
+// known-unsupported annotation.
+func RemediationSummary(annotation string) (kind, summary string, ok bool) {
+	r, ok := recipes[annotation]
+	return r.kind, r.summary, ok
+}
+
+// remediate returns the remediation of the known-unsupported annotation of the Ingress.
+func remediate(ing *netv1.Ingress, annotation string) Remediation {
+	r := recipes[annotation]
+
+	remediation := Remediation{
+		Annotation: annotation,
+		Kind:       cmp.Or(r.kind, RemediationManual),
+		Summary:    r.summary,
+	}
+
+	if r.generate != nil {
+		config, err := r.generate(ing, ing.Annotations[annotation])
+		if err != nil {
+			remediation.Summary += fmt.Sprintf(" The configuration could not be generated: %v.", err)
+			return remediation
+		}
+		remediation.YAML = config
+	}
+
+	return remediation
+}
+
+// traefikObject returns the YAML of a Traefik resource named after the Ingress.
+func traefikObject(ing *netv1.Ingress, kind, purpose string, spec any) (string, error) {
+	data, err := yaml.Marshal(map[string]any{
+		"apiVersion": traefikAPIVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"name": ing.Name + "-" + purpose, "namespace": ing.Namespace},
+		"spec":       spec,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// todo introduces the configuration with a TODO comment, for the values the Ingress does
+// not provide and which are left as TODO placeholders.
+func todo(comment, config string) string {
+	return "# TODO: " + comment + "\n" + config
+}
+
+func marshalYAML(value any) (string, error) {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ingressBackend returns the name and port of the first Service of the Ingress.
+func ingressBackend(ing *netv1.Ingress) (string, any) {
+	backend := ing.Spec.DefaultBackend
+	for _, rule := range ing.Spec.Rules {
+		if backend == nil && rule.HTTP != nil && len(rule.HTTP.Paths) > 0 {
+			backend = &rule.HTTP.Paths[0].Backend
+		}
+	}
+
+	if backend == nil || backend.Service == nil {
+		return "backend", 80
+	}
+	if backend.Service.Port.Name != "" {
+		return backend.Service.Name, backend.Service.Port.Name
+	}
+	return backend.Service.Name, backend.Service.Port.Number
+}
+
+// hostMatcher returns the Host matcher of the hosts of the Ingress, if any.
+func hostMatcher(ing *netv1.Ingress) string {
+	var hosts []string
+	for _, rule := range ing.Spec.Rules {
+		if matcher := "Host(`" + rule.Host + "`)"; rule.Host != "" && !slices.Contains(hosts, matcher) {
+			hosts = append(hosts, matcher)
+		}
+	}
+	if len(hosts) > 1 {
+		return "(" + strings.Join(hosts, " || ") + ")"
+	}
+	return strings.Join(hosts, "")
+}
+
+func splitRanges(value string) []string {
+	var ranges []string
+	for r := range strings.SplitSeq(value, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// clientIPRoute returns an IngressRoute route matching the source ranges on the
+// hosts of the Ingress, to which the middlewares to skip must not be attached.
+func clientIPRoute(ing *netv1.Ingress, ranges []string, comment string) (string, error) {
+	if len(ranges) == 0 {
+		return "", fmt.Errorf("no source range")
+	}
+
+	var matchers []string
+	for _, r := range ranges {
+		matchers = append(matchers, "ClientIP(`"+r+"`)")
+	}
+
+	match := "(" + strings.Join(matchers, " || ") + ")"
+	if host := hostMatcher(ing); host != "" {
+		match = host + " && " + match
+	}
+
+	name, port := ingressBackend(ing)
+	route, err := marshalYAML(map[string]any{"routes": []any{map[string]any{
+		"kind":     "Rule",
+		"match":    match,
+		"services": []any{map[string]any{"name": name, "port": port}},
+	}}})
+	if err != nil {
+		return "", err
+	}
+
+	return "# " + comment + "\n" + route, nil
+}
+
+func exemptionRoute(ing *netv1.Ingress, value string) (string, error) {
+	return clientIPRoute(ing, splitRanges(value), "Route of the exempted clients, without the RateLimit middleware.")
+}
+
+func satisfyAny(ing *netv1.Ingress, value string) (string, error) {
+	if value != "any" {
+		return "", nil
+	}
+
+	ranges := splitRanges(ing.Annotations["nginx.ingress.kubernetes.io/whitelist-source-range"])
+	ranges = append(ranges, splitRanges(ing.Annotations["nginx.ingress.kubernetes.io/allowlist-source-range"])...)
+	if len(ranges) == 0 {
+		return "", nil
+	}
+
+	return clientIPRoute(ing, ranges, "Route of the allowed clients, without the authentication middleware.")
+}
+
+func passCommonName(ing *netv1.Ingress, _ string) (string, error) {
+	return traefikObject(ing, "Middleware", "pass-client-cert", map[string]any{
+		"passTLSClientCert": map[string]any{"info": map[string]any{"subject": map[string]any{"commonName": true}}},
+	})
+}
+
+func proxySetHeaders(ing *netv1.Ingress, value string) (string, error) {
+	config, err := traefikObject(ing, "Middleware", "auth-headers", map[string]any{
+		"headers": map[string]any{"customRequestHeaders": map[string]any{"TODO-Header": "TODO-value"}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return todo("replace TODO-Header with the headers of the ConfigMap "+value+", one customRequestHeaders entry each.", config), nil
+}
+
+func globalAuth(ing *netv1.Ingress, value string) (string, error) {
+	if value == "false" {
+		return "", nil
+	}
+	config, err := marshalYAML(map[string]any{"entryPoints": map[string]any{"websecure": map[string]any{"http": map[string]any{
+		"middlewares": []string{"TODO-global-auth@kubernetescrd"},
+	}}}})
+	if err != nil {
+		return "", err
+	}
+	return todo("replace TODO-global-auth with <namespace>-<name> of the forwardAuth Middleware of the global-auth-url setting.", config), nil
+}
+
+func inFlightReq(ing *netv1.Ingress, value string) (string, error) {
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || amount <= 0 {
+		return "", fmt.Errorf("invalid connection limit %q", value)
+	}
+
+	return traefikObject(ing, "Middleware", "inflightreq", map[string]any{
+		"inFlightReq": map[string]any{
+			"amount":          amount,
+			"sourceCriterion": map[string]any{"ipStrategy": map[string]any{}},
+		},
+	})
+}
+
+func globalRateLimit(ing *netv1.Ingress, value string) (string, error) {
+	average, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || average <= 0 {
+		return "", fmt.Errorf("invalid rate limit %q", value)
+	}
+
+	period := cmp.Or(ing.Annotations["nginx.ingress.kubernetes.io/global-rate-limit-window"], "1s")
+
+	config, err := traefikObject(ing, "Middleware", "global-ratelimit", map[string]any{
+		"rateLimit": map[string]any{
+			"average": average,
+			"period":  period,
+			"burst":   average,
+			"redis":   map[string]any{"endpoints": []string{"TODO-redis:6379"}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return todo("replace TODO-redis:6379 with the endpoints of the Redis shared by the Traefik instances.", config), nil
+}
+
+func debugLogs(_ *netv1.Ingress, value string) (string, error) {
+	if value != "true" {
+		return "", nil
+	}
+	return marshalYAML(map[string]any{"log": map[string]any{"level": "DEBUG"}, "accessLog": map[string]any{}})
+}
+
+func tracing(_ *netv1.Ingress, value string) (string, error) {
+	if value != "true" {
+		return "# Opt the routes of the Ingress out of the tracing.\n" + "observability:\n  tracing: false\n", nil
+	}
+	config, err := marshalYAML(map[string]any{"tracing": map[string]any{"otlp": map[string]any{"grpc": map[string]any{
+		"endpoint": "TODO-otel-collector:4317",
+		"insecure": true,
+	}}}})
+	if err != nil {
+		return "", err
+	}
+	return todo("replace TODO-otel-collector:4317 with the endpoint of the OpenTelemetry collector.", config), nil
+}
+
+func distrustSpan(ing *netv1.Ingress, value string) (string, error) {
+	if value != "false" {
+		return "", nil
+	}
+	return traefikObject(ing, "Middleware", "distrust-span", map[string]any{
+		"headers": map[string]any{"customRequestHeaders": map[string]any{"traceparent": "", "tracestate": ""}},
+	})
+}
+
+func mirroring(ing *netv1.Ingress, value string) (string, error) {
+	target, err := url.Parse(strings.ReplaceAll(value, "$request_uri", ""))
+	if err != nil || target.Hostname() == "" {
+		return "", fmt.Errorf("invalid mirror target %q", value)
+	}
+
+	port := 80
+	if target.Scheme == "https" {
+		port = 443
+	}
+	if target.Port() != "" {
+		if port, err = strconv.Atoi(target.Port()); err != nil {
+			return "", fmt.Errorf("invalid mirror target %q", value)
+		}
+	}
+
+	service, err := marshalYAML(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]any{"name": ing.Name + "-mirror", "namespace": ing.Namespace},
+		"spec": map[string]any{
+			"type":         "ExternalName",
+			"externalName": target.Hostname(),
+			"ports":        []any{map[string]any{"port": port}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	name, backendPort := ingressBackend(ing)
+	traefikService, err := traefikObject(ing, "TraefikService", "mirroring", map[string]any{
+		"mirroring": map[string]any{
+			"name":       name,
+			"port":       backendPort,
+			"mirrorBody": ing.Annotations["nginx.ingress.kubernetes.io/mirror-request-body"] != "off",
+			"mirrors":    []any{map[string]any{"name": ing.Name + "-mirror", "port": port, "percent": 100}},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return service + "---\n" + traefikService, nil
+}
+
+// openSSLCiphers maps the OpenSSL names of the TLS 1.2 cipher suites supported by Go to their Go names.
+var openSSLCiphers = map[string]string{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256":   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384":   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-ECDSA-CHACHA20-POLY1305": "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-RSA-CHACHA20-POLY1305":   "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-SHA256":     "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-RSA-AES128-SHA256":       "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	"ECDHE-ECDSA-AES128-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-RSA-AES128-SHA":          "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-ECDSA-AES256-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	"ECDHE-RSA-AES256-SHA":          "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	"AES128-GCM-SHA256":             "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	"AES256-GCM-SHA384":             "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	"AES128-SHA256":                 "TLS_RSA_WITH_AES_128_CBC_SHA256",
+	"AES128-SHA":                    "TLS_RSA_WITH_AES_128_CBC_SHA",
+	"AES256-SHA":                    "TLS_RSA_WITH_AES_256_CBC_SHA",
+}
+
+func tlsCiphers(ing *netv1.Ingress, value string) (string, error) {
+	var suites, unmapped []string
+	for cipher := range strings.SplitSeq(value, ":") {
+		cipher = strings.TrimSpace(cipher)
+		if cipher == "" {
+			continue
+		}
+		if suite, ok := openSSLCiphers[cipher]; ok {
+			suites = append(suites, suite)
+			continue
+		}
+		unmapped = append(unmapped, cipher)
+	}
+
+	if len(suites) == 0 {
+		return "", fmt.Errorf("no cipher suite supported by Traefik in %q", value)
+	}
+
+	config, err := traefikObject(ing, "TLSOption", "tls-options", map[string]any{
+		"minVersion":   "VersionTLS12",
+		"cipherSuites": suites,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The TLS 1.3 cipher suites cannot be configured.
+	if len(unmapped) > 0 {
+		config = "# Not supported by Traefik: " + strings.Join(unmapped, ", ") + ".\n" + config
+	}
+
+	return config, nil
+}
+
+func denyList(ing *netv1.Ingress, value string) (string, error) {
+	var denied []netip.Prefix
+	for r := range strings.SplitSeq(value, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(r)
+			if addrErr != nil {
+				return "", fmt.Errorf("invalid source range %q", r)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		denied = append(denied, prefix.Masked())
+	}
+
+	if len(denied) == 0 {
+		return "", fmt.Errorf("no source range")
+	}
+
+	var allowed []string
+	for _, root := range []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")} {
+		for _, prefix := range complementPrefixes(root, denied) {
+			allowed = append(allowed, prefix.String())
+		}
+	}
+
+	return traefikObject(ing, "Middleware", "denylist", map[string]any{
+		"ipAllowList": map[string]any{"sourceRange": allowed},
+	})
+}
+
+// complementPrefixes returns the smallest set of prefixes of root covering the
+// addresses of root outside the denied prefixes.
+func complementPrefixes(root netip.Prefix, denied []netip.Prefix) []netip.Prefix {
+	overlapping := false
+	for _, prefix := range denied {
+		if prefix.Addr().Is4() != root.Addr().Is4() || !prefix.Overlaps(root) {
+			continue
+		}
+		if prefix.Bits() <= root.Bits() {
+			// The whole root is denied.
+			return nil
+		}
+		overlapping = true
+	}
+
+	if !overlapping {
+		return []netip.Prefix{root}
+	}
+
+	// Splits the root in its two halves.
+	bits := root.Bits() + 1
+	low := netip.PrefixFrom(root.Addr(), bits)
+
+	high := root.Addr().AsSlice()
+	high[root.Bits()/8] |= 0x80 >> (root.Bits() % 8)
+	highAddr, _ := netip.AddrFromSlice(high)
+
+	return append(complementPrefixes(low, denied), complementPrefixes(netip.PrefixFrom(highAddr, bits), denied)...)
+}
@@ -0,0 +1,276 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecipes(t *testing.T) {
+	t.Parallel()
+
+	for annotation := range knownUnsupportedAnnotations {
+		r, ok := recipes[annotation]
+		if assert.True(t, ok, "missing remediation of %s", annotation) {
+			assert.NotEmpty(t, r.kind, annotation)
+			assert.NotEmpty(t, r.summary, annotation)
+		}
+	}
+
+	for annotation := range recipes {
+		_, ok := knownUnsupportedAnnotations[annotation]
+		assert.True(t, ok, "remediation of %s which is not known-unsupported", annotation)
+	}
+}
+
+func remediationIngress(annotations map[string]string) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web", Annotations: annotations},
+		Spec: netv1.IngressSpec{
+			Rules: []netv1.IngressRule{{
+				Host: "shop.example.com",
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+					Paths: []netv1.HTTPIngressPath{{
+						Path: "/",
+						Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+							Name: "web",
+							Port: netv1.ServiceBackendPort{Name: "http"},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+}
+
+func TestRemediate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		annotations map[string]string
+		annotation  string
+		wantKind    string
+		wantYAML    string
+		wantError   bool
+	}{
+		{
+			desc:        "limit-connections",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/limit-connections": "5"},
+			annotation:  "nginx.ingress.kubernetes.io/limit-connections",
+			wantKind:    RemediationMiddleware,
+			wantYAML: `apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: web-inflightreq
+  namespace: shop
+spec:
+  inFlightReq:
+    amount: 5
+    sourceCriterion:
+      ipStrategy: {}
+`,
+		},
+		{
+			desc:        "invalid limit-connections",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/limit-connections": "many"},
+			annotation:  "nginx.ingress.kubernetes.io/limit-connections",
+			wantKind:    RemediationMiddleware,
+			wantError:   true,
+		},
+		{
+			desc:        "denylist-source-range",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/denylist-source-range": "128.0.0.0/1, 64.0.0.0/2, ::/1"},
+			annotation:  "nginx.ingress.kubernetes.io/denylist-source-range",
+			wantKind:    RemediationMiddleware,
+			wantYAML: `apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: web-denylist
+  namespace: shop
+spec:
+  ipAllowList:
+    sourceRange:
+    - 0.0.0.0/2
+    - 8000::/1
+`,
+		},
+		{
+			desc: "mirror-target",
+			annotations: map[string]string{
+				"nginx.ingress.kubernetes.io/mirror-target":       "https://mirror.example.com$request_uri",
+				"nginx.ingress.kubernetes.io/mirror-request-body": "off",
+			},
+			annotation: "nginx.ingress.kubernetes.io/mirror-target",
+			wantKind:   RemediationService,
+			wantYAML: `apiVersion: v1
+kind: Service
+metadata:
+  name: web-mirror
+  namespace: shop
+spec:
+  externalName: mirror.example.com
+  ports:
+  - port: 443
+  type: ExternalName
+---
+apiVersion: traefik.io/v1alpha1
+kind: TraefikService
+metadata:
+  name: web-mirroring
+  namespace: shop
+spec:
+  mirroring:
+    mirrorBody: false
+    mirrors:
+    - name: web-mirror
+      percent: 100
+      port: 443
+    name: web
+    port: http
+`,
+		},
+		{
+			desc:        "ssl-ciphers",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/ssl-ciphers": "ECDHE-RSA-AES128-GCM-SHA256:DHE-RSA-AES256-GCM-SHA384"},
+			annotation:  "nginx.ingress.kubernetes.io/ssl-ciphers",
+			wantKind:    RemediationTLSOption,
+			wantYAML: `# Not supported by Traefik: DHE-RSA-AES256-GCM-SHA384.
+apiVersion: traefik.io/v1alpha1
+kind: TLSOption
+metadata:
+  name: web-tls-options
+  namespace: shop
+spec:
+  cipherSuites:
+  - TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+  minVersion: VersionTLS12
+`,
+		},
+		{
+			desc:        "limit-whitelist",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/limit-whitelist": "10.0.0.0/8"},
+			annotation:  "nginx.ingress.kubernetes.io/limit-whitelist",
+			wantKind:    RemediationRouting,
+			wantYAML: "# Route of the exempted clients, without the RateLimit middleware.\n" +
+				"routes:\n" +
+				"- kind: Rule\n" +
+				"  match: Host(`shop.example.com`) && (ClientIP(`10.0.0.0/8`))\n" +
+				"  services:\n" +
+				"  - name: web\n" +
+				"    port: http\n",
+		},
+		{
+			desc:        "enable-opentelemetry",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/enable-opentelemetry": "true"},
+			annotation:  "nginx.ingress.kubernetes.io/enable-opentelemetry",
+			wantKind:    RemediationStatic,
+			wantYAML: `# TODO: replace TODO-otel-collector:4317 with the endpoint of the OpenTelemetry collector.
+tracing:
+  otlp:
+    grpc:
+      endpoint: TODO-otel-collector:4317
+      insecure: true
+`,
+		},
+		{
+			desc:        "global-rate-limit",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/global-rate-limit": "100", "nginx.ingress.kubernetes.io/global-rate-limit-window": "1m"},
+			annotation:  "nginx.ingress.kubernetes.io/global-rate-limit",
+			wantKind:    RemediationMiddleware,
+			wantYAML: `# TODO: replace TODO-redis:6379 with the endpoints of the Redis shared by the Traefik instances.
+apiVersion: traefik.io/v1alpha1
+kind: Middleware
+metadata:
+  name: web-global-ratelimit
+  namespace: shop
+spec:
+  rateLimit:
+    average: 100
+    burst: 100
+    period: 1m
+    redis:
+      endpoints:
+      - TODO-redis:6379
+`,
+		},
+		{
+			desc:        "without configuration",
+			annotations: map[string]string{"nginx.ingress.kubernetes.io/auth-keepalive": "10"},
+			annotation:  "nginx.ingress.kubernetes.io/auth-keepalive",
+			wantKind:    RemediationNotNeeded,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			remediation := remediate(remediationIngress(test.annotations), test.annotation)
+
+			assert.Equal(t, test.annotation, remediation.Annotation)
+			assert.Equal(t, test.wantKind, remediation.Kind)
+			assert.Equal(t, test.wantYAML, remediation.YAML)
+			if test.wantError {
+				assert.Contains(t, remediation.Summary, "The configuration could not be generated")
+			}
+		})
+	}
+}
+
+func TestComplementPrefixes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		root   string
+		denied []string
+		want   []string
+	}{
+		{root: "0.0.0.0/0", want: []string{"0.0.0.0/0"}},
+		{root: "0.0.0.0/0", denied: []string{"0.0.0.0/0"}},
+		{root: "0.0.0.0/0", denied: []string{"::/0"}, want: []string{"0.0.0.0/0"}},
+		{root: "0.0.0.0/0", denied: []string{"0.0.0.0/1"}, want: []string{"128.0.0.0/1"}},
+		{root: "10.0.0.0/8", denied: []string{"10.0.0.0/10", "10.192.0.0/10"}, want: []string{"10.64.0.0/10", "10.128.0.0/10"}},
+		{root: "192.168.0.0/30", denied: []string{"192.168.0.1/32"}, want: []string{"192.168.0.0/32", "192.168.0.2/31"}},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s without %v", test.root, test.denied), func(t *testing.T) {
+			t.Parallel()
+
+			var denied []netip.Prefix
+			for _, prefix := range test.denied {
+				denied = append(denied, netip.MustParsePrefix(prefix))
+			}
+
+			var got []string
+			for _, prefix := range complementPrefixes(netip.MustParsePrefix(test.root), denied) {
+				got = append(got, prefix.String())
+			}
+
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestComputeIngressReport_Remediations(t *testing.T) {
+	t.Parallel()
+
+	report := computeIngressReport(remediationIngress(map[string]string{
+		"nginx.ingress.kubernetes.io/limit-connections": "5",
+		"nginx.ingress.kubernetes.io/satisfy":           "any",
+		"nginx.ingress.kubernetes.io/made-up":           "true",
+	}))
+
+	require.Len(t, report.Remediations, 2)
+	assert.Equal(t, "nginx.ingress.kubernetes.io/limit-connections", report.Remediations[0].Annotation)
+	assert.Equal(t, "nginx.ingress.kubernetes.io/satisfy", report.Remediations[1].Annotation)
+
+	// Without allowed source ranges, no route can be generated.
+	assert.Empty(t, report.Remediations[1].YAML)
+}
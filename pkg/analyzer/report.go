@@ -17,9 +17,12 @@ import (
 const (
 	annotationIngressClass       = "kubernetes.io/ingress.class"
 	ingressNginxAnnotationPrefix = "nginx.ingress.kubernetes.io"
-	withoutClass                 = "without-class"
 )
 
+// WithoutClass is the class of the Ingresses analyzed without an IngressClass
+// nor a class annotation, with the watch-ingress-without-class option.
+const WithoutClass = "without-class"
+
 // knownUnsupportedAnnotations is the set of NGINX ingress controller annotations
 // that are explicitly documented as unsupported by Traefik v3.7.
 // An annotation in this set is known to the tool but has no Traefik equivalent.
@@ -198,18 +201,71 @@ var supportedAnnotations = map[string]string{
 	"nginx.ingress.kubernetes.io/modsecurity-snippet":        "Traefik Hub v3.20",
 }
 
+// Support statuses of an NGINX annotation.
+const (
+	// AnnotationSupported is the status of an annotation supported by Traefik.
+	AnnotationSupported = "supported"
+	// AnnotationUnsupported is the status of an annotation explicitly documented as unsupported by Traefik.
+	AnnotationUnsupported = "unsupported"
+	// AnnotationUnknown is the status of an annotation in neither catalog.
+	AnnotationUnknown = "unknown"
+)
+
+// ClassifyAnnotation returns the support status of the NGINX annotation and,
+// when supported, the minimum Traefik version supporting it.
+func ClassifyAnnotation(annotation string) (status, version string) {
+	if ver, ok := supportedAnnotations[annotation]; ok {
+		return AnnotationSupported, ver
+	}
+
+	if _, ok := knownUnsupportedAnnotations[annotation]; ok {
+		return AnnotationUnsupported, ""
+	}
+
+	return AnnotationUnknown, ""
+}
+
+// IsNginxAnnotation returns whether the annotation is an NGINX ingress controller annotation.
+func IsNginxAnnotation(annotation string) bool {
+	return strings.HasPrefix(annotation, ingressNginxAnnotationPrefix)
+}
+
 // AnnotationInfo contains annotation name and its minimum required Traefik version.
 type AnnotationInfo struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 }
 
+// Verdicts of an Ingress analysis.
+const (
+	// VerdictVanilla is the verdict of a compatible Ingress without NGINX annotations.
+	VerdictVanilla = "vanilla"
+	// VerdictSupported is the verdict of a compatible Ingress with only supported NGINX annotations.
+	VerdictSupported = "supported"
+	// VerdictUnsupported is the verdict of an Ingress with unsupported or unknown NGINX annotations.
+	VerdictUnsupported = "unsupported"
+)
+
+// Minimum Traefik versions of the compatible Ingresses.
+const (
+	TraefikV36 = "v3.6"
+	TraefikV37 = "v3.7"
+	TraefikHub = "Traefik Hub"
+)
+
 // IngressReport contains the analysis report for a single Ingress.
 type IngressReport struct {
 	Name             string `json:"name"`
 	Namespace        string `json:"namespace"`
 	IngressClassName string `json:"ingressClassName"`
 
+	// Class is the IngressClass name, the class annotation value or "without-class"
+	// that made the Ingress part of the analysis, as counted in IngressCountByClass.
+	Class string `json:"class,omitempty"`
+	// ClassLess is true when the Ingress has neither ingressClassName nor class annotation,
+	// whatever its Class.
+	ClassLess bool `json:"classLess,omitempty"`
+
 	// UnsupportedAnnotations are nginx.ingress.kubernetes.io/* annotations that are
 	// explicitly documented as unsupported by Traefik. They require manual migration.
 	UnsupportedAnnotations []string `json:"unsupportedAnnotations"`
@@ -219,10 +275,60 @@ type IngressReport struct {
 	// custom extensions, or annotations not yet cataloged by this tool.
 	UnknownAnnotations []string `json:"unknownAnnotations,omitempty"`
 
+	// Remediations are the Traefik alternatives to the UnsupportedAnnotations, in the same order.
+	Remediations []Remediation `json:"remediations,omitempty"`
+
+	// RegexWarnings are the paths that NGINX and Traefik may match differently,
+	// as NGINX matches the regex paths with PCRE and its own priority rules.
+	RegexWarnings []RegexWarning `json:"regexWarnings,omitempty"`
+
+	// PathConflicts are the conflicts between the paths of the Ingress and those of the
+	// other Ingresses of its hosts, which NGINX merges into one server.
+	PathConflicts []PathConflict `json:"pathConflicts,omitempty"`
+
+	// ServerConflicts are the server-scope annotations that the Ingress and the other
+	// Ingresses of its hosts do not agree on.
+	ServerConflicts []ServerConflict `json:"serverConflicts,omitempty"`
+
 	SupportedAnnotations []AnnotationInfo `json:"supportedAnnotations,omitempty"`
 	HasNginxAnnotation   bool             `json:"-"`
 }
 
+// Verdict returns the verdict of the Ingress analysis.
+func (r IngressReport) Verdict() string {
+	switch {
+	case len(r.UnsupportedAnnotations) > 0 || len(r.UnknownAnnotations) > 0:
+		return VerdictUnsupported
+	case len(r.SupportedAnnotations) > 0:
+		return VerdictSupported
+	default:
+		return VerdictVanilla
+	}
+}
+
+// TraefikVersion returns the minimum Traefik version supporting the annotations
+// of a compatible Ingress.
+func (r IngressReport) TraefikVersion() string {
+	return traefikVersion(r.SupportedAnnotations)
+}
+
+func traefikVersion(supportedAnnotations []AnnotationInfo) string {
+	var requiresV37 bool
+	for _, ann := range supportedAnnotations {
+		switch {
+		case strings.HasPrefix(ann.Version, TraefikHub):
+			return TraefikHub
+		case ann.Version == TraefikV37:
+			requiresV37 = true
+		}
+	}
+
+	if requiresV37 {
+		return TraefikV37
+	}
+	return TraefikV36
+}
+
 // Report contains the analysis report for all Ingresses.
 type Report struct {
 	GenerationDate time.Time `json:"generationDate"`
@@ -237,6 +343,18 @@ type Report struct {
 	IngressCount        int            `json:"ingressCount"`
 	IngressCountByClass map[string]int `json:"ingressCountByClass"`
 
+	// IngressClassTopology describes the IngressClasses of the cluster. FilterReport keeps
+	// the IngressClasses, and the Ingress counts of the allowed namespaces.
+	IngressClassTopology *IngressClassTopology `json:"ingressClassTopology,omitempty"`
+
+	// ControllerDeployments are the Deployments and DaemonSets running the analyzed NGINX
+	// ingress controller, with their flags. It is cluster-wide, and kept as is by FilterReport.
+	ControllerDeployments []ControllerDeployment `json:"controllerDeployments,omitempty"`
+
+	// UnanalyzedNamespaces are the namespaces left out of the analysis, their Ingresses
+	// not being readable.
+	UnanalyzedNamespaces []string `json:"unanalyzedNamespaces,omitempty"`
+
 	// Compatible means all ingresses compatible with the ingress-nginx provider with or without NGINX annotations.
 	CompatibleIngressCount      int     `json:"compatibleIngressCount"`
 	CompatibleIngressPercentage float64 `json:"compatibleIngressPercentage"`
@@ -263,6 +381,11 @@ type Report struct {
 
 	UnsupportedIngresses []IngressReport `json:"unsupportedIngresses"`
 
+	// CompatibleIngresses lists the compatible ingresses, so that the migration of
+	// each Ingress can be followed across reports. They are left out of the report
+	// JSON, the history stores them along with it.
+	CompatibleIngresses []IngressReport `json:"-"`
+
 	// SupportedIngressAnnotations lists all supported annotations found in user's ingresses, sorted by name.
 	SupportedIngressAnnotations []AnnotationInfo `json:"supportedIngressAnnotations"`
 
@@ -276,38 +399,172 @@ func (a *Analyzer) computeReport(ingressClasses []*netv1.IngressClass, ingresses
 	report := Report{
 		GenerationDate:                time.Now().UTC(),
 		Version:                       version.Version,
+		UnanalyzedNamespaces:          a.unanalyzedNamespaces,
+		ControllerDeployments:         a.reportedControllerDeployments(),
 		IngressCountByClass:           make(map[string]int),
 		UnsupportedIngressAnnotations: make(map[string]int),
 		UnknownIngressAnnotations:     make(map[string]int),
 	}
 
 	// First we filter all NGINX ingress classes.
-	var nginxIngressClasses []*netv1.IngressClass
-	for _, ic := range ingressClasses {
-		if a.ingressClassByName && ic.Name == a.ingressClass {
-			nginxIngressClasses = append(nginxIngressClasses, ic)
-			break
-		}
-
-		if ic.Spec.Controller == a.controllerClass {
-			nginxIngressClasses = append(nginxIngressClasses, ic)
-		}
-	}
+	nginxIngressClasses := a.nginxIngressClasses(ingressClasses)
 
-	// Aggregate all supported annotations across ingresses.
-	allSupportedAnnotations := make(map[string]string)
+	topology := a.ingressClassTopology(ingressClasses, ingresses, nginxIngressClasses)
+	report.IngressClassTopology = &topology
 
 	// Then we iterate over all ingresses and check if they use a NGINX ingress class.
+	var processed []*netv1.Ingress
+	var processedReports []*IngressReport
 	for _, ing := range ingresses {
 		ok, nginxIngressClass := a.shouldProcessIngress(ing, nginxIngressClasses)
 		if !ok {
 			continue
 		}
 
-		report.IngressCount++
-		report.IngressCountByClass[nginxIngressClass]++
-
 		ingReport := computeIngressReport(ing)
+		ingReport.Class = nginxIngressClass
+
+		processed = append(processed, ing)
+		processedReports = append(processedReports, ingReport)
+	}
+
+	// The regex paths are analyzed per host, across the ingresses.
+	analyzeRegexPaths(processed, processedReports)
+	analyzePathConflicts(processed, processedReports)
+	analyzeServerAnnotations(processed, processedReports)
+
+	var ingReports []IngressReport
+	for _, ingReport := range processedReports {
+		ingReports = append(ingReports, *ingReport)
+	}
+
+	aggregateReport(&report, ingReports)
+
+	return report
+}
+
+// FilterReport returns the report restricted to the ingresses of the namespaces
+// for which allowed returns true, with its totals, percentages and hash recomputed.
+func FilterReport(report Report, allowed func(namespace string) bool) Report {
+	filtered := Report{
+		GenerationDate:                report.GenerationDate,
+		Version:                       report.Version,
+		IngressClassTopology:          filterTopology(report.IngressClassTopology, allowed),
+		ControllerDeployments:         report.ControllerDeployments,
+		IngressCountByClass:           make(map[string]int),
+		UnsupportedIngressAnnotations: make(map[string]int),
+		UnknownIngressAnnotations:     make(map[string]int),
+	}
+
+	for _, namespace := range report.UnanalyzedNamespaces {
+		if allowed(namespace) {
+			filtered.UnanalyzedNamespaces = append(filtered.UnanalyzedNamespaces, namespace)
+		}
+	}
+
+	var ingReports []IngressReport
+	for _, ingReport := range slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses) {
+		if allowed(ingReport.Namespace) {
+			ingReports = append(ingReports, redactIngressReport(ingReport, allowed))
+		}
+	}
+
+	aggregateReport(&filtered, ingReports)
+
+	return filtered
+}
+
+// hiddenIngress replaces the Ingresses of the namespaces left out of a filtered report.
+const hiddenIngress = "<hidden>"
+
+// redactIngressReport returns the ingress report with the references to the Ingresses of
+// the namespaces not allowed, in its conflicts and warnings, replaced by hiddenIngress.
+func redactIngressReport(ingReport IngressReport, allowed func(namespace string) bool) IngressReport {
+	hidden := func(ref string) bool {
+		namespace, _, ok := strings.Cut(ref, "/")
+		return ok && !allowed(namespace)
+	}
+
+	ingReport.PathConflicts = slices.Clone(ingReport.PathConflicts)
+	for i, conflict := range ingReport.PathConflicts {
+		conflict.Ingresses = slices.Clone(conflict.Ingresses)
+		for j, ref := range conflict.Ingresses {
+			if hidden(ref) {
+				conflict.Ingresses[j] = hiddenIngress
+				conflict.Message = redactIngressRef(conflict.Message, ref)
+			}
+		}
+		ingReport.PathConflicts[i] = conflict
+	}
+
+	ingReport.ServerConflicts = slices.Clone(ingReport.ServerConflicts)
+	for i, conflict := range ingReport.ServerConflicts {
+		conflict.Ingresses = slices.Clone(conflict.Ingresses)
+		for j, ref := range conflict.Ingresses {
+			if hidden(ref) {
+				conflict.Ingresses[j] = hiddenIngress
+				conflict.Message = redactIngressRef(conflict.Message, ref)
+			}
+		}
+		if hidden(conflict.Winner) {
+			conflict.Winner = hiddenIngress
+		}
+		ingReport.ServerConflicts[i] = conflict
+	}
+
+	ingReport.RegexWarnings = slices.Clone(ingReport.RegexWarnings)
+	for i, warning := range ingReport.RegexWarnings {
+		if hidden(warning.Ingress) {
+			warning.Message = redactIngressRef(warning.Message, warning.Ingress)
+			warning.Ingress = hiddenIngress
+		}
+		ingReport.RegexWarnings[i] = warning
+	}
+
+	return ingReport
+}
+
+// redactIngressRef replaces the Ingress reference, as "namespace/name", in the message
+// with hiddenIngress, where it is not part of a longer reference.
+func redactIngressRef(message, ref string) string {
+	var b strings.Builder
+	for {
+		i := strings.Index(message, ref)
+		if i < 0 {
+			b.WriteString(message)
+			return b.String()
+		}
+
+		end := i + len(ref)
+		before := i == 0 || !isRefByte(message[i-1]) && message[i-1] != '/'
+		// A dot ends the reference when it ends the sentence.
+		after := end == len(message) || !isRefByte(message[end]) ||
+			message[end] == '.' && (end+1 == len(message) || !isRefByte(message[end+1]))
+
+		b.WriteString(message[:i])
+		if before && after {
+			b.WriteString(hiddenIngress)
+		} else {
+			b.WriteString(ref)
+		}
+		message = message[end:]
+	}
+}
+
+// isRefByte returns whether the byte can be part of a Kubernetes object name.
+func isRefByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.'
+}
+
+// aggregateReport computes the report totals, percentages and hash from the
+// ingress reports.
+func aggregateReport(report *Report, ingReports []IngressReport) {
+	// Aggregate all supported annotations across ingresses.
+	allSupportedAnnotations := make(map[string]string)
+
+	for _, ingReport := range ingReports {
+		report.IngressCount++
+		report.IngressCountByClass[cmp.Or(ingReport.Class, ingReport.IngressClassName, WithoutClass)]++
 
 		// Merge supported annotations into report-level map.
 		for _, ann := range ingReport.SupportedAnnotations {
@@ -315,30 +572,31 @@ func (a *Analyzer) computeReport(ingressClasses []*netv1.IngressClass, ingresses
 		}
 
 		// Ingress is compatible only if it has no known-unsupported and no unknown annotations.
-		if len(ingReport.UnsupportedAnnotations) == 0 && len(ingReport.UnknownAnnotations) == 0 {
+		switch ingReport.Verdict() {
+		case VerdictVanilla:
 			report.CompatibleIngressCount++
+			report.CompatibleIngresses = append(report.CompatibleIngresses, ingReport)
+			report.VanillaIngressCount++
+			report.CompatibleV36IngressCount++
 
-			if !ingReport.HasNginxAnnotation {
-				report.VanillaIngressCount++
-				report.CompatibleV36IngressCount++
-				continue
-			}
-
+		case VerdictSupported:
+			report.CompatibleIngressCount++
+			report.CompatibleIngresses = append(report.CompatibleIngresses, ingReport)
 			report.SupportedIngressCount++
 			report.classifyIngressVersion(ingReport.SupportedAnnotations)
-			continue
-		}
 
-		// Has known-unsupported or unknown NGINX annotations.
-		report.UnsupportedIngressCount++
-		report.UnsupportedIngresses = append(report.UnsupportedIngresses, *ingReport)
+		default:
+			// Has known-unsupported or unknown NGINX annotations.
+			report.UnsupportedIngressCount++
+			report.UnsupportedIngresses = append(report.UnsupportedIngresses, ingReport)
 
-		for _, a := range ingReport.UnsupportedAnnotations {
-			report.UnsupportedIngressAnnotations[a]++
-		}
+			for _, a := range ingReport.UnsupportedAnnotations {
+				report.UnsupportedIngressAnnotations[a]++
+			}
 
-		for _, a := range ingReport.UnknownAnnotations {
-			report.UnknownIngressAnnotations[a]++
+			for _, a := range ingReport.UnknownAnnotations {
+				report.UnknownIngressAnnotations[a]++
+			}
 		}
 	}
 
@@ -350,15 +608,11 @@ func (a *Analyzer) computeReport(ingressClasses []*netv1.IngressClass, ingresses
 		return cmp.Compare(a.Name, b.Name)
 	})
 
-	// Sort unsupported ingresses by namespace then name so that the report (and
+	// Sort ingresses by namespace then name so that the report (and
 	// its JSON/Markdown rendering) is deterministic across runs. Listers return
 	// items in indexer order, which is not stable.
-	slices.SortFunc(report.UnsupportedIngresses, func(a, b IngressReport) int {
-		if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
-			return c
-		}
-		return cmp.Compare(a.Name, b.Name)
-	})
+	slices.SortFunc(report.UnsupportedIngresses, compareIngressReports)
+	slices.SortFunc(report.CompatibleIngresses, compareIngressReports)
 
 	// Calculate percentages
 	if report.IngressCount > 0 {
@@ -369,43 +623,44 @@ func (a *Analyzer) computeReport(ingressClasses []*netv1.IngressClass, ingresses
 	}
 
 	// Compute hash for localStorage persistence (excludes GenerationDate).
-	report.Hash = computeReportHash(report)
+	report.Hash = computeReportHash(*report)
+}
 
-	return report
+// compareIngressReports orders ingress reports by namespace then name.
+func compareIngressReports(a, b IngressReport) int {
+	if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Name, b.Name)
 }
 
 // reportHashPayload contains fields used to compute the report hash (excludes GenerationDate).
 type reportHashPayload struct {
-	Version                       string           `json:"version"`
-	IngressCount                  int              `json:"ingressCount"`
-	CompatibleIngressCount        int              `json:"compatibleIngressCount"`
-	VanillaIngressCount           int              `json:"vanillaIngressCount"`
-	SupportedIngressCount         int              `json:"supportedIngressCount"`
-	UnsupportedIngressCount       int              `json:"unsupportedIngressCount"`
-	UnsupportedIngressAnnotations map[string]int   `json:"unsupportedIngressAnnotations"`
-	UnknownIngressAnnotations     map[string]int   `json:"unknownIngressAnnotations"`
-	SupportedIngressAnnotations   []AnnotationInfo `json:"supportedIngressAnnotations"`
-	CompatibleV36IngressCount     int              `json:"compatibleV36IngressCount"`
-	CompatibleV37IngressCount     int              `json:"compatibleV37IngressCount"`
-	CompatibleHubIngressCount     int              `json:"compatibleHubIngressCount"`
+	Version                       string                 `json:"version"`
+	IngressCount                  int                    `json:"ingressCount"`
+	CompatibleIngressCount        int                    `json:"compatibleIngressCount"`
+	VanillaIngressCount           int                    `json:"vanillaIngressCount"`
+	SupportedIngressCount         int                    `json:"supportedIngressCount"`
+	UnsupportedIngressCount       int                    `json:"unsupportedIngressCount"`
+	UnsupportedIngressAnnotations map[string]int         `json:"unsupportedIngressAnnotations"`
+	UnknownIngressAnnotations     map[string]int         `json:"unknownIngressAnnotations"`
+	SupportedIngressAnnotations   []AnnotationInfo       `json:"supportedIngressAnnotations"`
+	CompatibleV36IngressCount     int                    `json:"compatibleV36IngressCount"`
+	CompatibleV37IngressCount     int                    `json:"compatibleV37IngressCount"`
+	CompatibleHubIngressCount     int                    `json:"compatibleHubIngressCount"`
+	UnanalyzedNamespaces          []string               `json:"unanalyzedNamespaces,omitempty"`
+	IngressClassTopology          *IngressClassTopology  `json:"ingressClassTopology,omitempty"`
+	ControllerDeployments         []ControllerDeployment `json:"controllerDeployments,omitempty"`
+	// Ingresses are the verdicts of the Ingresses, as "namespace/name=verdict", so that an
+	// Ingress becoming compatible changes the hash even when the totals do not.
+	Ingresses []string `json:"ingresses,omitempty"`
 }
 
 func (r *Report) classifyIngressVersion(supportedAnnotations []AnnotationInfo) {
-	var requiresHub, requiresV37 bool
-
-	for _, ann := range supportedAnnotations {
-		switch {
-		case strings.HasPrefix(ann.Version, "Traefik Hub"):
-			requiresHub = true
-		case ann.Version == "v3.7":
-			requiresV37 = true
-		}
-	}
-
-	switch {
-	case requiresHub:
+	switch traefikVersion(supportedAnnotations) {
+	case TraefikHub:
 		r.CompatibleHubIngressCount++
-	case requiresV37:
+	case TraefikV37:
 		r.CompatibleV37IngressCount++
 	default:
 		r.CompatibleV36IngressCount++
@@ -426,7 +681,14 @@ func computeReportHash(report Report) string {
 		CompatibleV36IngressCount:     report.CompatibleV36IngressCount,
 		CompatibleV37IngressCount:     report.CompatibleV37IngressCount,
 		CompatibleHubIngressCount:     report.CompatibleHubIngressCount,
+		UnanalyzedNamespaces:          report.UnanalyzedNamespaces,
+		IngressClassTopology:          report.IngressClassTopology,
+		ControllerDeployments:         report.ControllerDeployments,
 	}
+	for _, ingReport := range slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses) {
+		payload.Ingresses = append(payload.Ingresses, ingReport.Namespace+"/"+ingReport.Name+"="+ingReport.Verdict())
+	}
+	slices.Sort(payload.Ingresses)
 
 	data, _ := json.Marshal(payload) //nolint:errchkjson
 	hash := sha256.Sum256(data)
@@ -441,19 +703,20 @@ func computeIngressReport(ing *netv1.Ingress) *IngressReport {
 	var supported []AnnotationInfo
 
 	for annotation := range ing.Annotations {
-		if !strings.HasPrefix(annotation, ingressNginxAnnotationPrefix) {
+		if !IsNginxAnnotation(annotation) {
 			continue
 		}
 
 		hasNginxAnnotation = true
 
-		if ver, ok := supportedAnnotations[annotation]; ok {
+		switch status, ver := ClassifyAnnotation(annotation); status {
+		case AnnotationSupported:
 			// Known and supported by Traefik.
 			supported = append(supported, AnnotationInfo{Name: annotation, Version: ver})
-		} else if _, ok := knownUnsupportedAnnotations[annotation]; ok {
+		case AnnotationUnsupported:
 			// Known but explicitly unsupported by Traefik.
 			unsupportedAnnotations = append(unsupportedAnnotations, annotation)
-		} else {
+		default:
 			// Not in either list: could be a typo, custom extension, or an annotation
 			// not yet cataloged by this tool.
 			unknownAnnotations = append(unknownAnnotations, annotation)
@@ -467,17 +730,47 @@ func computeIngressReport(ing *netv1.Ingress) *IngressReport {
 	slices.Sort(unsupportedAnnotations)
 	slices.Sort(unknownAnnotations)
 
+	var remediations []Remediation
+	for _, annotation := range unsupportedAnnotations {
+		remediations = append(remediations, remediate(ing, annotation))
+	}
+
+	_, annotatedClass := ing.Annotations[annotationIngressClass]
+
 	return &IngressReport{
 		Name:                   ing.Name,
 		Namespace:              ing.Namespace,
 		IngressClassName:       ptr.Deref(ing.Spec.IngressClassName, ""),
+		ClassLess:              ing.Spec.IngressClassName == nil && !annotatedClass,
 		UnsupportedAnnotations: unsupportedAnnotations,
 		UnknownAnnotations:     unknownAnnotations,
+		Remediations:           remediations,
 		SupportedAnnotations:   supported,
 		HasNginxAnnotation:     hasNginxAnnotation,
 	}
 }
 
+// nginxIngressClasses returns the IngressClasses of the NGINX ingress controller.
+func (a *Analyzer) nginxIngressClasses(ingressClasses []*netv1.IngressClass) []*netv1.IngressClass {
+	var nginxIngressClasses []*netv1.IngressClass
+	for _, ic := range ingressClasses {
+		if a.ingressClassByName && ic.Name == a.ingressClass {
+			nginxIngressClasses = append(nginxIngressClasses, ic)
+			break
+		}
+
+		if ic.Spec.Controller == a.controllerClass {
+			nginxIngressClasses = append(nginxIngressClasses, ic)
+		}
+	}
+
+	return nginxIngressClasses
+}
+
+// shouldProcessIngress returns whether the NGINX ingress controller handles the Ingress,
+// and the class it is handled with. An existing Ingress without class nor class annotation
+// was created before the default IngressClass, Kubernetes only assigning it on creation: it
+// stays without class, handled with the watch-ingress-without-class option only.
 func (a *Analyzer) shouldProcessIngress(ingress *netv1.Ingress, ingressClasses []*netv1.IngressClass) (bool, string) {
 	if len(ingressClasses) > 0 && ingress.Spec.IngressClassName != nil {
 		for _, ic := range ingressClasses {
@@ -491,5 +784,10 @@ func (a *Analyzer) shouldProcessIngress(ingress *netv1.Ingress, ingressClasses [
 		return class == a.ingressClass, class
 	}
 
-	return a.watchIngressWithoutClass, withoutClass
+	// An Ingress of another IngressClass is not handled, whatever the watch-ingress-without-class option.
+	if ingress.Spec.IngressClassName != nil {
+		return false, *ingress.Spec.IngressClassName
+	}
+
+	return a.watchIngressWithoutClass, WithoutClass
 }
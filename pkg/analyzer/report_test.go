@@ -1,9 +1,13 @@
 package analyzer
 
 import (
+	"encoding/json"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -320,6 +324,15 @@ func TestComputeReport_AnnotationClassification(t *testing.T) {
 	assert.Equal(t, []string{"nginx.ingress.kubernetes.io/limit-connections"}, mixedReport.UnsupportedAnnotations)
 	assert.Equal(t, []string{"nginx.ingress.kubernetes.io/totally-made-up"}, mixedReport.UnknownAnnotations)
 	assert.Len(t, mixedReport.SupportedAnnotations, 1)
+	assert.Equal(t, "nginx", mixedReport.Class)
+	assert.Equal(t, VerdictUnsupported, mixedReport.Verdict())
+
+	// Compatible ingresses are listed too, sorted by namespace then name.
+	require.Len(t, report.CompatibleIngresses, 2)
+	assert.Equal(t, "supported", report.CompatibleIngresses[0].Name)
+	assert.Equal(t, VerdictSupported, report.CompatibleIngresses[0].Verdict())
+	assert.Equal(t, "vanilla", report.CompatibleIngresses[1].Name)
+	assert.Equal(t, VerdictVanilla, report.CompatibleIngresses[1].Verdict())
 }
 
 // TestNoOverlapBetweenSupportedAndKnownUnsupported guards against an annotation
@@ -333,3 +346,187 @@ func TestNoOverlapBetweenSupportedAndKnownUnsupported(t *testing.T) {
 		assert.Falsef(t, ok, "annotation %q is present in both supportedAnnotations and knownUnsupportedAnnotations", ann)
 	}
 }
+
+func TestComputeReport_HashIngressVerdicts(t *testing.T) {
+	t.Parallel()
+
+	a := &Analyzer{
+		ingressClass:    "nginx",
+		controllerClass: "k8s.io/ingress-nginx",
+	}
+
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	makeIngress := func(name string, annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       netv1.IngressSpec{IngressClassName: new("nginx")},
+		}
+	}
+	unsupported := map[string]string{"nginx.ingress.kubernetes.io/limit-connections": "10"}
+
+	before := a.computeReport([]*netv1.IngressClass{ingressClass}, []*netv1.Ingress{
+		makeIngress("api", unsupported),
+		makeIngress("web", nil),
+	})
+	// The api Ingress becomes compatible while the web Ingress regresses: the totals are unchanged.
+	after := a.computeReport([]*netv1.IngressClass{ingressClass}, []*netv1.Ingress{
+		makeIngress("api", nil),
+		makeIngress("web", unsupported),
+	})
+
+	assert.Equal(t, before.UnsupportedIngressAnnotations, after.UnsupportedIngressAnnotations)
+	assert.Equal(t, before.CompatibleIngressCount, after.CompatibleIngressCount)
+	assert.NotEqual(t, before.Hash, after.Hash)
+}
+
+func TestFilterReport(t *testing.T) {
+	t.Parallel()
+
+	a := &Analyzer{
+		ingressClass:    "nginx",
+		controllerClass: "k8s.io/ingress-nginx",
+	}
+	a.SetUnanalyzedNamespaces([]string{"team-c", "team-b"})
+
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	makeIngress := func(namespace, name string, annotations map[string]string) *netv1.Ingress {
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Annotations: annotations},
+			Spec:       netv1.IngressSpec{IngressClassName: new("nginx")},
+		}
+	}
+
+	report := a.computeReport([]*netv1.IngressClass{ingressClass}, []*netv1.Ingress{
+		makeIngress("team-a", "vanilla", nil),
+		makeIngress("team-a", "unsupported", map[string]string{"nginx.ingress.kubernetes.io/limit-connections": "10"}),
+		makeIngress("team-b", "supported", map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/"}),
+		makeIngress("team-b", "unknown", map[string]string{"nginx.ingress.kubernetes.io/totally-made-up": "true"}),
+	})
+
+	assert.Equal(t, []string{"team-b", "team-c"}, report.UnanalyzedNamespaces)
+
+	filtered := FilterReport(report, func(namespace string) bool { return namespace == "team-b" })
+
+	assert.Equal(t, report.GenerationDate, filtered.GenerationDate)
+	assert.Equal(t, []string{"team-b"}, filtered.UnanalyzedNamespaces)
+	assert.Equal(t, 2, filtered.IngressCount)
+	assert.Equal(t, map[string]int{"nginx": 2}, filtered.IngressCountByClass)
+	assert.Equal(t, 1, filtered.CompatibleIngressCount)
+	assert.Equal(t, 1, filtered.SupportedIngressCount)
+	assert.Equal(t, 1, filtered.CompatibleV37IngressCount)
+	assert.Zero(t, filtered.VanillaIngressCount)
+	assert.Equal(t, 1, filtered.UnsupportedIngressCount)
+	assert.InDelta(t, 50.0, filtered.UnsupportedIngressPercentage, 0.001)
+	assert.Empty(t, filtered.UnsupportedIngressAnnotations)
+	assert.Equal(t, map[string]int{"nginx.ingress.kubernetes.io/totally-made-up": 1}, filtered.UnknownIngressAnnotations)
+	assert.Equal(t, []AnnotationInfo{{Name: "nginx.ingress.kubernetes.io/rewrite-target", Version: "v3.7"}}, filtered.SupportedIngressAnnotations)
+	assert.NotEqual(t, report.Hash, filtered.Hash)
+
+	// The IngressClass topology only counts the Ingresses of the allowed namespaces.
+	require.NotNil(t, filtered.IngressClassTopology)
+	assert.Equal(t, []IngressClassReport{{
+		Name:                    "nginx",
+		Controller:              "k8s.io/ingress-nginx",
+		NGINX:                   true,
+		IngressCount:            2,
+		IngressCountByNamespace: map[string]int{"team-b": 2},
+	}}, filtered.IngressClassTopology.IngressClasses)
+	assert.Equal(t, 4, report.IngressClassTopology.IngressClasses[0].IngressCount)
+
+	// Filtering with every namespace allowed is a no-op.
+	assert.Equal(t, report, FilterReport(report, func(string) bool { return true }))
+}
+
+func TestFilterReport_HiddenIngresses(t *testing.T) {
+	t.Parallel()
+
+	a := &Analyzer{
+		ingressClass:    "nginx",
+		controllerClass: "k8s.io/ingress-nginx",
+	}
+
+	ingressClass := &netv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx"},
+		Spec:       netv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	makeIngress := func(namespace, service string, annotations map[string]string) *netv1.Ingress {
+		created = created.Add(time.Minute)
+		return &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: namespace, Annotations: annotations, CreationTimestamp: metav1.NewTime(created)},
+			Spec: netv1.IngressSpec{
+				IngressClassName: new("nginx"),
+				Rules: []netv1.IngressRule{{
+					Host: "shared.example.com",
+					IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+						Paths: []netv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: new(netv1.PathTypePrefix),
+							Backend:  netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: service, Port: netv1.ServiceBackendPort{Number: 80}}},
+						}},
+					}},
+				}},
+			},
+		}
+	}
+
+	report := a.computeReport([]*netv1.IngressClass{ingressClass}, []*netv1.Ingress{
+		makeIngress("team-b", "b", map[string]string{
+			"nginx.ingress.kubernetes.io/use-regex":      "true",
+			"nginx.ingress.kubernetes.io/server-snippet": "return 403;",
+		}),
+		makeIngress("team-a", "a", nil),
+	})
+
+	filtered := FilterReport(report, func(namespace string) bool { return namespace == "team-a" })
+	require.Len(t, slices.Concat(filtered.UnsupportedIngresses, filtered.CompatibleIngresses), 1)
+	ingReport := slices.Concat(filtered.UnsupportedIngresses, filtered.CompatibleIngresses)[0]
+
+	require.NotEmpty(t, ingReport.PathConflicts)
+	require.NotEmpty(t, ingReport.ServerConflicts)
+	require.NotEmpty(t, ingReport.RegexWarnings)
+
+	data, err := json.Marshal(ingReport)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "team-b")
+	assert.Contains(t, string(data), "team-a/app")
+	assert.Equal(t, hiddenIngress, ingReport.ServerConflicts[0].Winner)
+	assert.Equal(t, hiddenIngress, ingReport.RegexWarnings[0].Ingress)
+
+	// The unfiltered report is left untouched.
+	data, err = json.Marshal(report)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), hiddenIngress)
+}
+
+func TestRedactIngressRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		message string
+		want    string
+	}{
+		{desc: "reference", message: "the Ingress ns/app enables regex paths", want: "the Ingress <hidden> enables regex paths"},
+		{desc: "list", message: "set by ns/app, ns/app-tls.", want: "set by <hidden>, ns/app-tls."},
+		{desc: "end of sentence", message: "only set by ns/app.", want: "only set by <hidden>."},
+		{desc: "longer name", message: "the Ingress ns/app.v2 and xns/app", want: "the Ingress ns/app.v2 and xns/app"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, redactIngressRef(tt.message, "ns/app"))
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+// Kinds of server annotation conflict.
+const (
+	// ServerAnnotationDisagree is a server-scope annotation set to different values by
+	// the Ingresses of a host.
+	ServerAnnotationDisagree = "disagree"
+	// ServerAnnotationPartial is a server-scope annotation set by only some Ingresses of a host.
+	ServerAnnotationPartial = "partial"
+)
+
+// ServerConflict is a server-scope annotation that the Ingresses of a host do not agree on.
+//
+// NGINX applies these annotations to the whole server of the host, from a single
+// Ingress, while Traefik applies them to the routers of the Ingress setting them.
+type ServerConflict struct {
+	Host       string `json:"host"`
+	Annotation string `json:"annotation"`
+	Kind       string `json:"kind"`
+	// Ingresses are the Ingresses of the host involved, as "namespace/name", in creation order.
+	Ingresses []string `json:"ingresses"`
+	// Winner is the Ingress whose annotation NGINX applies to the whole server.
+	Winner  string `json:"winner"`
+	Message string `json:"message"`
+}
+
+// serverAnnotation is an annotation that NGINX applies to the whole server of a host.
+type serverAnnotation struct {
+	name string
+	// value returns the value of the annotation for the host, false when unset.
+	value func(ing *netv1.Ingress, host string) (string, bool)
+	// exempt returns whether the Ingress is not affected by the annotation of another Ingress.
+	exempt func(ing *netv1.Ingress) bool
+	// only describes the annotation set by only some Ingresses.
+	only string
+	// nginx describes how NGINX applies the annotation of the winner.
+	nginx string
+	// traefik describes how Traefik applies the annotation.
+	traefik string
+}
+
+var serverAnnotations = []serverAnnotation{
+	{
+		name:    "server-snippet",
+		value:   annotationValue("server-snippet"),
+		only:    "the server-snippet annotation is only set by %s",
+		nginx:   "adds the snippet of %s to the whole server",
+		traefik: "Traefik applies the converted snippet to the routers of its Ingress only",
+	},
+	{
+		name:    "server-alias",
+		value:   annotationValue("server-alias"),
+		only:    "the server-alias annotation is only set by %s",
+		nginx:   "serves the whole server on the aliases of %s",
+		traefik: "Traefik adds the aliases to the routers of their Ingress only, the other paths are not served on the aliases",
+	},
+	{
+		name:    "auth-tls-*",
+		value:   authTLSValue,
+		only:    "the auth-tls-* annotations are only set by %s",
+		nginx:   "verifies the client certificates of the whole server with the settings of %s",
+		traefik: "Traefik verifies the client certificates on the routers of their Ingress only, and uses the default TLS options when the routers of a host use different ones",
+	},
+	{
+		name: "from-to-www-redirect",
+		value: func(ing *netv1.Ingress, _ string) (string, bool) {
+			value, ok := annotationValue("from-to-www-redirect")(ing, "")
+			return value, ok && value == "true"
+		},
+		only:    "the from-to-www-redirect annotation is only set by %s",
+		nginx:   "redirects the www and non-www hosts of the whole server as %s enables it",
+		traefik: "Traefik redirects the requests of the routers of their Ingress only",
+	},
+	{
+		name: "ssl-redirect",
+		value: func(ing *netv1.Ingress, host string) (string, bool) {
+			for _, tls := range ing.Spec.TLS {
+				if slices.Contains(tls.Hosts, host) {
+					return "tls", true
+				}
+			}
+			return "", false
+		},
+		exempt: func(ing *netv1.Ingress) bool {
+			return ing.Annotations["nginx.ingress.kubernetes.io/ssl-redirect"] == "false" ||
+				ing.Annotations["nginx.ingress.kubernetes.io/force-ssl-redirect"] == "true"
+		},
+		only:    "TLS for the host is only configured by %s",
+		nginx:   "serves the whole server over HTTPS with the certificate of %s and redirects every path to HTTPS by default",
+		traefik: "Traefik only redirects the routers of the Ingresses with a TLS entry to HTTPS",
+	},
+}
+
+// annotationValue returns the value of the NGINX annotation.
+func annotationValue(name string) func(ing *netv1.Ingress, _ string) (string, bool) {
+	return func(ing *netv1.Ingress, _ string) (string, bool) {
+		value, ok := ing.Annotations[ingressNginxAnnotationPrefix+"/"+name]
+		return strings.TrimSpace(value), ok
+	}
+}
+
+// authTLSValue returns the auth-tls-* annotations of the Ingress, as sorted name=value pairs.
+func authTLSValue(ing *netv1.Ingress, _ string) (string, bool) {
+	var pairs []string
+	for name, value := range ing.Annotations {
+		if suffix, ok := strings.CutPrefix(name, ingressNginxAnnotationPrefix+"/auth-tls-"); ok {
+			pairs = append(pairs, "auth-tls-"+suffix+"="+value)
+		}
+	}
+	slices.Sort(pairs)
+
+	return strings.Join(pairs, ", "), len(pairs) > 0
+}
+
+// analyzeServerAnnotations adds the server-scope annotation conflicts of the hosts shared
+// by several Ingresses to the reports of the ingresses, in the same order.
+func analyzeServerAnnotations(ingresses []*netv1.Ingress, reports []*IngressReport) {
+	type hostIngress struct {
+		ing    *netv1.Ingress
+		report *IngressReport
+		ref    string
+	}
+
+	var hosts []string
+	byHost := make(map[string][]hostIngress)
+	for _, i := range creationOrder(ingresses) {
+		ing := ingresses[i]
+		if isCanary(ing) {
+			continue
+		}
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" || slices.ContainsFunc(byHost[rule.Host], func(h hostIngress) bool { return h.ing == ing }) {
+				continue
+			}
+			if _, ok := byHost[rule.Host]; !ok {
+				hosts = append(hosts, rule.Host)
+			}
+			byHost[rule.Host] = append(byHost[rule.Host], hostIngress{ing: ing, report: reports[i], ref: ing.Namespace + "/" + ing.Name})
+		}
+	}
+
+	for _, host := range hosts {
+		shared := byHost[host]
+		if len(shared) < 2 {
+			continue
+		}
+
+		for _, annotation := range serverAnnotations {
+			var setters, missing []string
+			var involved []*IngressReport
+			values := make(map[string]bool)
+
+			for _, h := range shared {
+				value, ok := annotation.value(h.ing, host)
+				switch {
+				case ok:
+					setters = append(setters, h.ref)
+					values[value] = true
+				case annotation.exempt != nil && annotation.exempt(h.ing):
+					continue
+				default:
+					missing = append(missing, h.ref)
+				}
+				involved = append(involved, h.report)
+			}
+
+			if len(setters) == 0 || len(values) == 1 && len(missing) == 0 {
+				continue
+			}
+
+			conflict := ServerConflict{
+				Host:       host,
+				Annotation: annotation.name,
+				Winner:     setters[0],
+			}
+			for _, h := range shared {
+				if slices.Contains(setters, h.ref) || slices.Contains(missing, h.ref) {
+					conflict.Ingresses = append(conflict.Ingresses, h.ref)
+				}
+			}
+
+			nginx := fmt.Sprintf(annotation.nginx, conflict.Winner)
+			if len(values) > 1 {
+				conflict.Kind = ServerAnnotationDisagree
+				conflict.Message = fmt.Sprintf("the %s annotation differs between %s and NGINX ignores all but the oldest: it %s, while %s",
+					annotation.name, strings.Join(setters, ", "), nginx, annotation.traefik)
+			} else {
+				conflict.Kind = ServerAnnotationPartial
+				conflict.Message = fmt.Sprintf("%s, and NGINX %s, including the paths of %s, while %s",
+					fmt.Sprintf(annotation.only, strings.Join(setters, ", ")), nginx, strings.Join(missing, ", "), annotation.traefik)
+			}
+
+			for _, report := range involved {
+				report.ServerConflicts = append(report.ServerConflicts, conflict)
+			}
+		}
+	}
+
+	for _, report := range reports {
+		slices.SortStableFunc(report.ServerConflicts, func(a, b ServerConflict) int {
+			return cmp.Or(cmp.Compare(a.Host, b.Host), cmp.Compare(a.Annotation, b.Annotation))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnalyzeServerAnnotations(t *testing.T) {
+	t.Parallel()
+
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ingress := func(name string, annotations map[string]string, tls bool, host string) *netv1.Ingress {
+		created = created.Add(time.Minute)
+		ing := &netv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Annotations:       annotations,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: netv1.IngressSpec{Rules: []netv1.IngressRule{{
+				Host:             host,
+				IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{}},
+			}}},
+		}
+		if tls {
+			ing.Spec.TLS = []netv1.IngressTLS{{Hosts: []string{host}, SecretName: name + "-tls"}}
+		}
+		return ing
+	}
+
+	ingresses := []*netv1.Ingress{
+		ingress("web", map[string]string{
+			"nginx.ingress.kubernetes.io/server-snippet":  "add_header X-Web 1;",
+			"nginx.ingress.kubernetes.io/auth-tls-secret": "default/ca",
+		}, true, "shop.example.com"),
+		ingress("api", map[string]string{
+			"nginx.ingress.kubernetes.io/server-snippet":  "add_header X-Api 1;",
+			"nginx.ingress.kubernetes.io/auth-tls-secret": "default/ca",
+		}, true, "shop.example.com"),
+		ingress("legacy", map[string]string{
+			"nginx.ingress.kubernetes.io/server-snippet":  "add_header X-Web 1;",
+			"nginx.ingress.kubernetes.io/auth-tls-secret": "default/ca",
+			"nginx.ingress.kubernetes.io/ssl-redirect":    "false",
+		}, false, "shop.example.com"),
+		ingress("www", map[string]string{"nginx.ingress.kubernetes.io/from-to-www-redirect": "true"}, false, "example.com"),
+		ingress("docs", map[string]string{"nginx.ingress.kubernetes.io/from-to-www-redirect": "false"}, false, "example.com"),
+		ingress("canary", map[string]string{"nginx.ingress.kubernetes.io/canary": "true"}, false, "example.com"),
+		ingress("alone", map[string]string{"nginx.ingress.kubernetes.io/server-alias": "alone.example.org"}, false, "alone.example.com"),
+	}
+
+	reports := make([]*IngressReport, len(ingresses))
+	for i, ing := range ingresses {
+		reports[i] = computeIngressReport(ing)
+	}
+
+	analyzeServerAnnotations(ingresses, reports)
+
+	snippet := ServerConflict{
+		Host:       "shop.example.com",
+		Annotation: "server-snippet",
+		Kind:       ServerAnnotationDisagree,
+		Ingresses:  []string{"default/web", "default/api", "default/legacy"},
+		Winner:     "default/web",
+		Message:    "the server-snippet annotation differs between default/web, default/api, default/legacy and NGINX ignores all but the oldest: it adds the snippet of default/web to the whole server, while Traefik applies the converted snippet to the routers of its Ingress only",
+	}
+	wwwRedirect := ServerConflict{
+		Host:       "example.com",
+		Annotation: "from-to-www-redirect",
+		Kind:       ServerAnnotationPartial,
+		Ingresses:  []string{"default/www", "default/docs"},
+		Winner:     "default/www",
+		Message:    "the from-to-www-redirect annotation is only set by default/www, and NGINX redirects the www and non-www hosts of the whole server as default/www enables it, including the paths of default/docs, while Traefik redirects the requests of the routers of their Ingress only",
+	}
+
+	// The auth-tls-* annotations agree, and the Ingress without TLS opts out of the redirect.
+	assert.Equal(t, []ServerConflict{snippet}, reports[0].ServerConflicts)
+	assert.Equal(t, []ServerConflict{snippet}, reports[1].ServerConflicts)
+	assert.Equal(t, []ServerConflict{snippet}, reports[2].ServerConflicts)
+	assert.Equal(t, []ServerConflict{wwwRedirect}, reports[3].ServerConflicts)
+	assert.Equal(t, []ServerConflict{wwwRedirect}, reports[4].ServerConflicts)
+	assert.Empty(t, reports[5].ServerConflicts)
+	assert.Empty(t, reports[6].ServerConflicts)
+
+	// Without opting out, the Ingress without TLS is redirected to HTTPS by NGINX.
+	delete(ingresses[2].Annotations, "nginx.ingress.kubernetes.io/ssl-redirect")
+	reports[2] = computeIngressReport(ingresses[2])
+	analyzeServerAnnotations(ingresses[2:3], reports[2:3])
+	assert.Empty(t, reports[2].ServerConflicts)
+
+	for i := range reports {
+		reports[i] = computeIngressReport(ingresses[i])
+	}
+	analyzeServerAnnotations(ingresses, reports)
+
+	assert.Equal(t, []ServerConflict{snippet, {
+		Host:       "shop.example.com",
+		Annotation: "ssl-redirect",
+		Kind:       ServerAnnotationPartial,
+		Ingresses:  []string{"default/web", "default/api", "default/legacy"},
+		Winner:     "default/web",
+		Message:    "TLS for the host is only configured by default/web, default/api, and NGINX serves the whole server over HTTPS with the certificate of default/web and redirects every path to HTTPS by default, including the paths of default/legacy, while Traefik only redirects the routers of the Ingresses with a TLS entry to HTTPS",
+	}}, reports[2].ServerConflicts)
+}
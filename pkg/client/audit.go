@@ -0,0 +1,46 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+)
+
+// AuditRecord is a persisted record of a report transmission.
+type AuditRecord struct {
+	SentAt   time.Time              `json:"sentAt"`
+	Endpoint string                 `json:"endpoint"`
+	Cluster  string                 `json:"cluster,omitempty"`
+	Payload  handlers.ReportPayload `json:"payload"`
+
+	// Error is the transmission error, empty when the report was sent successfully.
+	Error string `json:"error,omitempty"`
+}
+
+// AppendAuditRecord appends the record as a JSON line to the audit log at path,
+// creating the file when it does not exist.
+func AppendAuditRecord(path string, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+
+	return nil
+}
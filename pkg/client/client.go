@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
@@ -25,14 +26,25 @@ var (
 	CACertB64 = ""
 )
 
+// Headers used to identify the sender when reporting to a self-hosted collector.
+const (
+	headerAuthorization = "Authorization"
+	headerClusterName   = "X-Cluster-Name"
+)
+
 // Client is a client for sending reports to a remote endpoint.
 type Client struct {
 	endpointURL string
+	token       string
+	clusterName string
+	auditLog    string
 	httpClient  *http.Client
 }
 
 // New creates a new Client.
-func New(endpointURL string) (*Client, error) {
+// When set, token is sent as a bearer token and clusterName identifies the
+// cluster to a self-hosted collector (see the collect command).
+func New(endpointURL, token, clusterName string) (*Client, error) {
 	// When no endpointURL is provided, use the default one.
 	if endpointURL == "" {
 		endpointURL = "https://collect.ingressnginxmigration.org/a2181946f5561e7e7405000e5c94de97"
@@ -45,6 +57,8 @@ func New(endpointURL string) (*Client, error) {
 
 	return &Client{
 		endpointURL: endpointURL,
+		token:       token,
+		clusterName: clusterName,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 			Transport: &http.Transport{
@@ -59,10 +73,73 @@ func New(endpointURL string) (*Client, error) {
 	}, nil
 }
 
-func (c *Client) SendReport(reportPayload handlers.ReportPayload) error {
+// EndpointURL returns the URL the reports are sent to.
+func (c *Client) EndpointURL() string {
+	return c.endpointURL
+}
+
+// ClusterName returns the cluster name sent along with the reports.
+func (c *Client) ClusterName() string {
+	return c.clusterName
+}
+
+// SetAuditLog makes the client append a record of every transmission to the audit log at path,
+// as JSON lines. The file is created right away, so that an unwritable audit log fails before
+// anything is sent.
+func (c *Client) SetAuditLog(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing audit log: %w", err)
+	}
+
+	c.auditLog = path
+
+	return nil
+}
+
+// MarshalReport returns the exact bytes sent for the given payload.
+func MarshalReport(reportPayload handlers.ReportPayload) ([]byte, error) {
 	reportBytes, err := json.Marshal(reportPayload)
 	if err != nil {
-		return fmt.Errorf("marshaling report to JSON: %w", err)
+		return nil, fmt.Errorf("marshaling report to JSON: %w", err)
+	}
+
+	return reportBytes, nil
+}
+
+// SendReport sends the report payload to the endpoint, and records the transmission in the
+// audit log when one is set.
+func (c *Client) SendReport(reportPayload handlers.ReportPayload) error {
+	sendErr := c.send(reportPayload)
+	if c.auditLog == "" {
+		return sendErr
+	}
+
+	record := AuditRecord{
+		SentAt:   time.Now().UTC(),
+		Endpoint: c.endpointURL,
+		Cluster:  c.clusterName,
+		Payload:  reportPayload,
+	}
+	if sendErr != nil {
+		record.Error = sendErr.Error()
+	}
+
+	if err := AppendAuditRecord(c.auditLog, record); err != nil {
+		return errors.Join(sendErr, fmt.Errorf("recording transmission: %w", err))
+	}
+
+	return sendErr
+}
+
+func (c *Client) send(reportPayload handlers.ReportPayload) error {
+	reportBytes, err := MarshalReport(reportPayload)
+	if err != nil {
+		return err
 	}
 
 	req, err := http.NewRequest(http.MethodPost, c.endpointURL, bytes.NewBuffer(reportBytes))
@@ -71,6 +148,12 @@ func (c *Client) SendReport(reportPayload handlers.ReportPayload) error {
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set(headerAuthorization, "Bearer "+c.token)
+	}
+	if c.clusterName != "" {
+		req.Header.Set(headerClusterName, c.clusterName)
+	}
 
 	res, err := c.httpClient.Do(req)
 	if err != nil {
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+)
+
+func TestSendReport(t *testing.T) {
+	t.Parallel()
+
+	payload := handlers.ReportPayload{
+		IngressCount:            3,
+		UnsupportedIngressCount: 1,
+		Version:                 "v0.3.0",
+	}
+	want, err := MarshalReport(payload)
+	require.NoError(t, err)
+
+	var (
+		gotBody          []byte
+		gotAuthorization string
+		gotCluster       string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		gotBody, _ = io.ReadAll(req.Body)
+		gotAuthorization = req.Header.Get(headerAuthorization)
+		gotCluster = req.Header.Get(headerClusterName)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	clt, err := New(srv.URL, "secret", "prod-eu")
+	require.NoError(t, err)
+
+	require.NoError(t, clt.SendReport(payload))
+	assert.Equal(t, want, gotBody, "the dry-run payload must be exactly what is sent")
+	assert.Equal(t, "Bearer secret", gotAuthorization)
+	assert.Equal(t, "prod-eu", gotCluster)
+}
+
+func TestSendReport_NoIdentity(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Empty(t, req.Header.Get(headerAuthorization))
+		assert.Empty(t, req.Header.Get(headerClusterName))
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	clt, err := New(srv.URL, "", "")
+	require.NoError(t, err)
+
+	require.Error(t, clt.SendReport(handlers.ReportPayload{}))
+}
+
+func TestAppendAuditRecord(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sentAt := time.Date(2026, 5, 27, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, AppendAuditRecord(path, AuditRecord{SentAt: sentAt, Endpoint: "https://example.com", Payload: handlers.ReportPayload{IngressCount: 1}}))
+	require.NoError(t, AppendAuditRecord(path, AuditRecord{SentAt: sentAt, Endpoint: "https://example.com", Error: "boom"}))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"sentAt":"2026-05-27T10:00:00Z","endpoint":"https://example.com","payload":{"generationDate":"0001-01-01T00:00:00Z","ingressCount":1,"compatibleIngressCount":0,"vanillaIngressCount":0,"supportedIngressCount":0,"unsupportedIngressCount":0,"unsupportedIngressAnnotations":null,"version":""}}`, lines[0])
+	assert.Contains(t, lines[1], `"error":"boom"`)
+}
+
+func TestSendReport_AuditLog(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+
+	clt, err := New(srv.URL, "", "prod-eu")
+	require.NoError(t, err)
+
+	require.Error(t, clt.SetAuditLog(filepath.Join(t.TempDir(), "missing", "audit.jsonl")))
+
+	// The audit log is created before anything is sent.
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, clt.SetAuditLog(path))
+	require.FileExists(t, path)
+
+	require.Error(t, clt.SendReport(handlers.ReportPayload{IngressCount: 2}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"cluster":"prod-eu"`)
+	assert.Contains(t, string(data), `"ingressCount":2`)
+	assert.Contains(t, string(data), `"error":"invalid response status code: 500"`)
+}
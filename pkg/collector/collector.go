@@ -0,0 +1,279 @@
+// Package collector implements a self-hosted server aggregating the reports
+// sent by many tool instances, typically one per cluster, into a fleet view.
+package collector
+
+import (
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+)
+
+const (
+	headerClusterName = "X-Cluster-Name"
+
+	// maxReportSize bounds the size of a received report.
+	maxReportSize = 10 << 20
+
+	defaultTopBlockingAnnotations = 10
+)
+
+//go:embed fleet.html
+var fleetTemplate string
+
+// clusterNameRegexp restricts cluster names to values which are safe to use as
+// directory names.
+var clusterNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,62}$`)
+
+var errUnauthorized = errors.New("unauthorized")
+
+// Config is the collector configuration.
+type Config struct {
+	// DataDir is the directory where the received reports are stored.
+	DataDir string
+	// Tokens are the bearer tokens accepted from senders, by cluster name.
+	// A sender using a token can only send the reports of its cluster.
+	Tokens map[string]string
+	// MaxHistory is the number of reports kept per cluster. Zero keeps them all.
+	MaxHistory int
+	// StaleAfter is the duration after which a cluster which did not report is
+	// flagged as stale. Zero disables the check.
+	StaleAfter time.Duration
+}
+
+// Collector receives reports and serves the fleet dashboard.
+type Collector struct {
+	tokens     map[string]string
+	staleAfter time.Duration
+	store      *store
+	fleetTmpl  *template.Template
+	now        func() time.Time
+}
+
+// New creates a new Collector.
+func New(cfg Config) (*Collector, error) {
+	for cluster := range cfg.Tokens {
+		if !validClusterName(cluster) {
+			return nil, fmt.Errorf("invalid cluster name %q", cluster)
+		}
+	}
+
+	st, err := newStore(cfg.DataDir, cfg.MaxHistory)
+	if err != nil {
+		return nil, fmt.Errorf("creating store: %w", err)
+	}
+
+	fleetTmpl, err := template.New("fleet").Parse(fleetTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing fleet template: %w", err)
+	}
+
+	return &Collector{
+		tokens:     cfg.Tokens,
+		staleAfter: cfg.StaleAfter,
+		store:      st,
+		fleetTmpl:  fleetTmpl,
+		now:        time.Now,
+	}, nil
+}
+
+// Handler returns the HTTP handler of the collector.
+// The fleet views require the same authentication as the senders.
+func (c *Collector) Handler() http.Handler {
+	router := httprouter.New()
+	router.HandlerFunc(http.MethodPost, "/api/v1/reports", c.ReceiveReport)
+	router.HandlerFunc(http.MethodGet, "/api/v1/fleet", c.authenticated(c.FleetJSON))
+	router.HandlerFunc(http.MethodGet, "/api/v1/clusters/:cluster/reports", c.authenticated(c.ClusterHistory))
+	router.HandlerFunc(http.MethodGet, "/api/v1/clusters/:cluster/reports/latest", c.authenticated(c.ClusterLatest))
+	router.HandlerFunc(http.MethodGet, "/", c.authenticated(c.Dashboard))
+
+	return router
+}
+
+// authenticated rejects the requests which are not authenticated like the senders.
+// Browsers are asked for the cluster name and its token with basic authentication.
+func (c *Collector) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if _, err := c.authenticate(req); err != nil {
+			rw.Header().Set("WWW-Authenticate", `Basic realm="collector"`)
+			handlers.JSONErrorf(rw, http.StatusUnauthorized, "%s", err)
+			return
+		}
+
+		next(rw, req)
+	}
+}
+
+// ReceiveReport stores a report sent by an authenticated tool instance.
+// Both the full analyzer.Report (as output with --format json) and the
+// lightweight handlers.ReportPayload are accepted, the latter being a subset of the former.
+func (c *Collector) ReceiveReport(rw http.ResponseWriter, req *http.Request) {
+	cluster, err := c.authenticate(req)
+	if err != nil {
+		handlers.JSONErrorf(rw, http.StatusUnauthorized, "%s", err)
+		return
+	}
+
+	var report analyzer.Report
+	if err := json.NewDecoder(http.MaxBytesReader(rw, req.Body, maxReportSize)).Decode(&report); err != nil {
+		handlers.JSONErrorf(rw, http.StatusBadRequest, "decoding report: %s", err)
+		return
+	}
+
+	entry := Entry{
+		Cluster:    cluster,
+		ReceivedAt: c.now().UTC(),
+		Report:     report,
+	}
+	if entry.Report.GenerationDate.IsZero() {
+		entry.Report.GenerationDate = entry.ReceivedAt
+	}
+
+	if err := c.store.add(entry); err != nil {
+		log.Err(err).Str("cluster", cluster).Msg("Error while storing the report")
+		handlers.JSONInternalServerError(rw)
+		return
+	}
+
+	log.Info().Str("cluster", cluster).Int("ingresses", report.IngressCount).Msg("Report received")
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// FleetJSON returns the fleet totals, per-cluster status and top blocking annotations.
+// The number of blocking annotations can be changed with the "top" query parameter.
+func (c *Collector) FleetJSON(rw http.ResponseWriter, req *http.Request) {
+	topN := defaultTopBlockingAnnotations
+	if raw := req.URL.Query().Get("top"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			handlers.JSONErrorf(rw, http.StatusBadRequest, "invalid top %q", raw)
+			return
+		}
+		topN = n
+	}
+
+	writeJSON(rw, computeFleet(c.store.latestEntries(), c.now(), c.staleAfter, topN))
+}
+
+// ClusterHistory returns every stored report of a cluster, oldest first.
+func (c *Collector) ClusterHistory(rw http.ResponseWriter, req *http.Request) {
+	cluster := httprouter.ParamsFromContext(req.Context()).ByName("cluster")
+	if !validClusterName(cluster) {
+		handlers.JSONErrorf(rw, http.StatusBadRequest, "invalid cluster name %q", cluster)
+		return
+	}
+
+	entries, err := c.store.history(cluster)
+	if err != nil {
+		log.Err(err).Str("cluster", cluster).Msg("Error while reading the cluster history")
+		handlers.JSONInternalServerError(rw)
+		return
+	}
+	if len(entries) == 0 {
+		handlers.JSONErrorf(rw, http.StatusNotFound, "unknown cluster %q", cluster)
+		return
+	}
+
+	writeJSON(rw, entries)
+}
+
+// ClusterLatest returns the latest report of a cluster.
+func (c *Collector) ClusterLatest(rw http.ResponseWriter, req *http.Request) {
+	cluster := httprouter.ParamsFromContext(req.Context()).ByName("cluster")
+
+	entry, ok := c.store.latestEntry(cluster)
+	if !ok {
+		handlers.JSONErrorf(rw, http.StatusNotFound, "unknown cluster %q", cluster)
+		return
+	}
+
+	writeJSON(rw, entry)
+}
+
+// Dashboard returns the HTML fleet dashboard.
+func (c *Collector) Dashboard(rw http.ResponseWriter, _ *http.Request) {
+	fleet := computeFleet(c.store.latestEntries(), c.now(), c.staleAfter, defaultTopBlockingAnnotations)
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := c.fleetTmpl.Execute(rw, fleet); err != nil {
+		log.Err(err).Msg("Error while executing fleet template")
+	}
+}
+
+// authenticate returns the name of the cluster sending the request.
+// A sender presenting a verified client certificate is identified by the
+// certificate common name. Otherwise, it must present the bearer token of its
+// cluster, or the cluster name and its token with basic authentication.
+// The optional X-Cluster-Name header must match the authenticated cluster.
+func (c *Collector) authenticate(req *http.Request) (string, error) {
+	var cluster string
+
+	switch {
+	case req.TLS != nil && len(req.TLS.VerifiedChains) > 0:
+		cluster = req.TLS.PeerCertificates[0].Subject.CommonName
+	default:
+		var ok bool
+		if cluster, ok = c.tokenCluster(req); !ok {
+			return "", errUnauthorized
+		}
+	}
+
+	if !validClusterName(cluster) {
+		return "", fmt.Errorf("invalid cluster name %q", cluster)
+	}
+
+	if name := req.Header.Get(headerClusterName); name != "" && name != cluster {
+		return "", fmt.Errorf("the credentials of the cluster %q cannot send the reports of the cluster %q", cluster, name)
+	}
+
+	return cluster, nil
+}
+
+// tokenCluster returns the cluster whose token is presented by the request.
+func (c *Collector) tokenCluster(req *http.Request) (string, bool) {
+	if user, password, ok := req.BasicAuth(); ok {
+		token, exists := c.tokens[user]
+		return user, exists && password != "" && subtle.ConstantTimeCompare([]byte(token), []byte(password)) == 1
+	}
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "", false
+	}
+
+	for cluster, t := range c.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return cluster, true
+		}
+	}
+
+	return "", false
+}
+
+func validClusterName(name string) bool {
+	return clusterNameRegexp.MatchString(name)
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Err(err).Msg("Error while encoding the response")
+	}
+}
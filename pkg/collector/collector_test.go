@@ -0,0 +1,223 @@
+package collector
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+)
+
+func newTestCollector(t *testing.T, dir string) *Collector {
+	t.Helper()
+
+	c, err := New(Config{
+		DataDir:    dir,
+		Tokens:     map[string]string{"prod-eu": "secret-eu", "prod-us": "secret-us", "staging": "secret-staging"},
+		MaxHistory: 2,
+		StaleAfter: time.Hour,
+	})
+	require.NoError(t, err)
+
+	return c
+}
+
+func postReport(t *testing.T, handler http.Handler, token, cluster string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/reports", strings.NewReader(string(data)))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if cluster != "" {
+		req.Header.Set(headerClusterName, cluster)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func get(t *testing.T, handler http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.SetBasicAuth("staging", "secret-staging")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestReceiveReport_Authentication(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		token    string
+		cluster  string
+		wantCode int
+	}{
+		{name: "valid token", token: "secret-eu", cluster: "prod-eu", wantCode: http.StatusNoContent},
+		{name: "cluster name of the token", token: "secret-eu", wantCode: http.StatusNoContent},
+		{name: "missing token", cluster: "prod-eu", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", token: "nope", cluster: "prod-eu", wantCode: http.StatusUnauthorized},
+		{name: "token of another cluster", token: "secret-us", cluster: "prod-eu", wantCode: http.StatusUnauthorized},
+		{name: "path traversal in cluster name", token: "secret-eu", cluster: "../etc", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := newTestCollector(t, t.TempDir())
+
+			rec := postReport(t, c.Handler(), tt.token, tt.cluster, handlers.ReportPayload{IngressCount: 1})
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestHandler_Authentication(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCollector(t, t.TempDir())
+
+	for _, path := range []string{"/", "/api/v1/fleet", "/api/v1/clusters/prod-eu/reports", "/api/v1/clusters/prod-eu/reports/latest"} {
+		rec := httptest.NewRecorder()
+		c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+		assert.Equal(t, `Basic realm="collector"`, rec.Header().Get("WWW-Authenticate"), path)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.SetBasicAuth("prod-eu", "secret-us")
+		rec = httptest.NewRecorder()
+		c.Handler().ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/fleet", nil)
+	req.Header.Set("Authorization", "Bearer secret-eu")
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestFleet(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := newTestCollector(t, dir)
+
+	now := time.Date(2026, 5, 27, 10, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	// A lightweight payload, as sent by the share feature.
+	rec := postReport(t, c.Handler(), "secret-eu", "prod-eu", handlers.ReportPayload{
+		IngressCount:            10,
+		CompatibleIngressCount:  8,
+		UnsupportedIngressCount: 2,
+		UnsupportedIngressAnnotations: map[string]int{
+			"nginx.ingress.kubernetes.io/limit-connections": 2,
+		},
+		Version: "v0.3.0",
+	})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// A full report, as output by --format json.
+	rec = postReport(t, c.Handler(), "secret-us", "prod-us", analyzer.Report{
+		IngressCount:            5,
+		CompatibleIngressCount:  4,
+		UnsupportedIngressCount: 1,
+		UnsupportedIngressAnnotations: map[string]int{
+			"nginx.ingress.kubernetes.io/limit-connections": 1,
+		},
+		UnknownIngressAnnotations: map[string]int{
+			"nginx.ingress.kubernetes.io/totally-made-up": 1,
+		},
+		Version: "v0.3.0",
+	})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	// An old report from a compatible cluster.
+	c.now = func() time.Time { return now.Add(-2 * time.Hour) }
+	rec = postReport(t, c.Handler(), "secret-staging", "staging", handlers.ReportPayload{IngressCount: 3, CompatibleIngressCount: 3})
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	c.now = func() time.Time { return now }
+
+	rec = get(t, c.Handler(), "/api/v1/fleet")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var fleet Fleet
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&fleet))
+
+	assert.Equal(t, 3, fleet.ClusterCount)
+	assert.Equal(t, 18, fleet.IngressCount)
+	assert.Equal(t, 15, fleet.CompatibleIngressCount)
+	assert.Equal(t, 3, fleet.UnsupportedIngressCount)
+	assert.Equal(t, 2, fleet.BlockedClusterCount)
+	assert.Equal(t, 1, fleet.StaleClusterCount)
+
+	require.Len(t, fleet.Clusters, 3)
+	assert.Equal(t, "prod-eu", fleet.Clusters[0].Name)
+	assert.Equal(t, StatusBlocked, fleet.Clusters[0].Status)
+	assert.Equal(t, "staging", fleet.Clusters[2].Name)
+	assert.Equal(t, StatusStale, fleet.Clusters[2].Status)
+
+	assert.Equal(t, []analyzer.BlockingAnnotation{
+		{Annotation: "nginx.ingress.kubernetes.io/limit-connections", Kind: analyzer.BlockingUnsupported, IngressCount: 3, ClusterCount: 2},
+		{Annotation: "nginx.ingress.kubernetes.io/totally-made-up", Kind: analyzer.BlockingUnknown, IngressCount: 1, ClusterCount: 1},
+	}, fleet.TopBlockingAnnotations)
+
+	// The dashboard renders.
+	rec = get(t, c.Handler(), "/")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "prod-us")
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	c := newTestCollector(t, dir)
+
+	start := time.Date(2026, 5, 27, 10, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		c.now = func() time.Time { return start.Add(time.Duration(i) * time.Minute) }
+
+		rec := postReport(t, c.Handler(), "secret-eu", "prod-eu", handlers.ReportPayload{IngressCount: i + 1})
+		require.Equal(t, http.StatusNoContent, rec.Code)
+	}
+
+	rec := get(t, c.Handler(), "/api/v1/clusters/prod-eu/reports")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var entries []Entry
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&entries))
+
+	// MaxHistory is 2, so the oldest report is pruned.
+	require.Len(t, entries, 2)
+	assert.Equal(t, 2, entries[0].Report.IngressCount)
+	assert.Equal(t, 3, entries[1].Report.IngressCount)
+
+	// The latest reports are reloaded from disk on restart.
+	restarted := newTestCollector(t, dir)
+
+	entry, ok := restarted.store.latestEntry("prod-eu")
+	require.True(t, ok)
+	assert.Equal(t, 3, entry.Report.IngressCount)
+
+	rec = get(t, restarted.Handler(), "/api/v1/clusters/unknown/reports")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/handlers"
+)
+
+// Cluster statuses reported in the fleet view.
+const (
+	StatusCompatible = "compatible"
+	StatusBlocked    = "blocked"
+	StatusStale      = "stale"
+)
+
+// Fleet aggregates the latest report of every cluster.
+type Fleet struct {
+	GenerationDate time.Time `json:"generationDate"`
+
+	ClusterCount           int `json:"clusterCount"`
+	CompatibleClusterCount int `json:"compatibleClusterCount"`
+	BlockedClusterCount    int `json:"blockedClusterCount"`
+	StaleClusterCount      int `json:"staleClusterCount"`
+
+	analyzer.IngressTotals
+
+	Clusters []ClusterStatus `json:"clusters"`
+
+	// TopBlockingAnnotations lists the annotations preventing the migration
+	// across the fleet, most used first.
+	TopBlockingAnnotations []analyzer.BlockingAnnotation `json:"topBlockingAnnotations"`
+}
+
+// ClusterStatus is the migration status of a single cluster.
+type ClusterStatus struct {
+	Name       string                 `json:"name"`
+	Status     string                 `json:"status"`
+	ReceivedAt time.Time              `json:"receivedAt"`
+	Report     handlers.ReportPayload `json:"report"`
+}
+
+// computeFleet aggregates the given latest entries. Clusters which did not
+// report since staleAfter are flagged as stale, but still counted in the totals.
+// At most topN blocking annotations are kept when topN is greater than zero.
+func computeFleet(entries []Entry, now time.Time, staleAfter time.Duration, topN int) Fleet {
+	fleet := Fleet{
+		GenerationDate: now.UTC(),
+		ClusterCount:   len(entries),
+		Clusters:       make([]ClusterStatus, 0, len(entries)),
+	}
+
+	reports := make([]analyzer.Report, 0, len(entries))
+	for _, entry := range entries {
+		report := entry.Report
+		reports = append(reports, report)
+
+		status := StatusCompatible
+		switch {
+		case staleAfter > 0 && now.Sub(entry.ReceivedAt) > staleAfter:
+			status = StatusStale
+			fleet.StaleClusterCount++
+		case report.UnsupportedIngressCount > 0:
+			status = StatusBlocked
+			fleet.BlockedClusterCount++
+		default:
+			fleet.CompatibleClusterCount++
+		}
+
+		fleet.Clusters = append(fleet.Clusters, ClusterStatus{
+			Name:       entry.Cluster,
+			Status:     status,
+			ReceivedAt: entry.ReceivedAt,
+			Report:     handlers.NewReportPayload(report),
+		})
+	}
+
+	fleet.IngressTotals, fleet.TopBlockingAnnotations = analyzer.AggregateReports(reports)
+	if topN > 0 && len(fleet.TopBlockingAnnotations) > topN {
+		fleet.TopBlockingAnnotations = fleet.TopBlockingAnnotations[:topN]
+	}
+
+	return fleet
+}
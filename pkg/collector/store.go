@@ -0,0 +1,219 @@
+package collector
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// entryFileTimeFormat names the stored report files so that the lexical order
+// of the file names is also their chronological order.
+const entryFileTimeFormat = "20060102T150405.000000000Z"
+
+// Entry is a report received from a tool instance.
+type Entry struct {
+	Cluster    string          `json:"cluster"`
+	ReceivedAt time.Time       `json:"receivedAt"`
+	Report     analyzer.Report `json:"report"`
+}
+
+// store persists the reports of every cluster as one JSON file per report,
+// in a directory per cluster. Only the latest report of each cluster is kept
+// in memory, the history is read from disk on demand.
+type store struct {
+	dir        string
+	maxHistory int
+
+	mu     sync.RWMutex
+	latest map[string]Entry
+}
+
+func newStore(dir string, maxHistory int) (*store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating data directory: %w", err)
+	}
+
+	s := &store{
+		dir:        dir,
+		maxHistory: maxHistory,
+		latest:     make(map[string]Entry),
+	}
+
+	clusterDirs, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading data directory: %w", err)
+	}
+
+	for _, clusterDir := range clusterDirs {
+		if !clusterDir.IsDir() || !validClusterName(clusterDir.Name()) {
+			continue
+		}
+
+		files, err := s.entryFiles(clusterDir.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+
+		entry, err := readEntry(files[len(files)-1])
+		if err != nil {
+			return nil, err
+		}
+
+		s.latest[entry.Cluster] = entry
+	}
+
+	return s, nil
+}
+
+// add persists the entry and makes it the latest one of its cluster.
+func (s *store) add(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clusterDir := filepath.Join(s.dir, entry.Cluster)
+	if err := os.MkdirAll(clusterDir, 0o750); err != nil {
+		return fmt.Errorf("creating cluster directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshaling entry: %w", err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partial
+	// report behind as the latest one.
+	name := filepath.Join(clusterDir, entry.ReceivedAt.UTC().Format(entryFileTimeFormat)+".json")
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("writing entry: %w", err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("renaming entry: %w", err)
+	}
+
+	s.latest[entry.Cluster] = entry
+
+	return s.prune(entry.Cluster)
+}
+
+// prune removes the oldest entries of the cluster beyond maxHistory.
+// A maxHistory lower or equal to zero keeps every entry.
+func (s *store) prune(cluster string) error {
+	if s.maxHistory <= 0 {
+		return nil
+	}
+
+	files, err := s.entryFiles(cluster)
+	if err != nil {
+		return err
+	}
+
+	for len(files) > s.maxHistory {
+		if err := os.Remove(files[0]); err != nil {
+			return fmt.Errorf("pruning entry: %w", err)
+		}
+		files = files[1:]
+	}
+
+	return nil
+}
+
+// latestEntries returns the latest entry of every cluster, sorted by cluster name.
+func (s *store) latestEntries() []Entry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(s.latest))
+	for _, entry := range s.latest {
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return cmp.Compare(a.Cluster, b.Cluster)
+	})
+
+	return entries
+}
+
+// latestEntry returns the latest entry of the cluster.
+func (s *store) latestEntry(cluster string) (Entry, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.latest[cluster]
+	return entry, ok
+}
+
+// history returns every stored entry of the cluster, oldest first.
+func (s *store) history(cluster string) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	files, err := s.entryFiles(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		entry, err := readEntry(file)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// entryFiles returns the paths of the stored entries of the cluster, oldest first.
+func (s *store) entryFiles(cluster string) ([]string, error) {
+	dirEntries, err := os.ReadDir(filepath.Join(s.dir, cluster))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster directory: %w", err)
+	}
+
+	var files []string
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+
+		files = append(files, filepath.Join(s.dir, cluster, dirEntry.Name()))
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+func readEntry(path string) (Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("reading entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("unmarshaling entry %s: %w", path, err)
+	}
+
+	return entry, nil
+}
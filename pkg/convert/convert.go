@@ -0,0 +1,990 @@
+// Package convert converts the Ingresses handled by the NGINX ingress controller
+// to native Traefik configuration.
+package convert
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/utils/ptr"
+)
+
+const annotationPrefix = "nginx.ingress.kubernetes.io/"
+
+// Input holds the cluster resources read by the conversion.
+type Input struct {
+	Ingresses []*netv1.Ingress
+	// Services are used to resolve the ports of the Services referenced by annotations,
+	// and the server URLs of the file provider configuration.
+	Services []*corev1.Service
+	// EndpointSlices are used to resolve the server URLs of the file provider configuration.
+	EndpointSlices []*discoveryv1.EndpointSlice
+}
+
+// Unconverted is an NGINX annotation of an Ingress which could not be converted.
+// For the snippet annotations, it is a directive of the snippet.
+type Unconverted struct {
+	Annotation string `json:"annotation"`
+	// Line is the line of the snippet directive, starting at 1.
+	Line      int    `json:"line,omitempty"`
+	Directive string `json:"directive,omitempty"`
+	Reason    string `json:"reason"`
+}
+
+// Conversion is the conversion of an Ingress.
+type Conversion struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+
+	// Objects are the generated objects, with the traefik-crd target.
+	Objects []Object `json:"objects,omitempty"`
+
+	// Unconverted are the NGINX annotations requiring a manual migration.
+	Unconverted []Unconverted `json:"unconverted,omitempty"`
+	// Warnings are the behavior differences of the converted annotations.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// unconvertedReasons explains why annotations supported by the kubernetesIngressNginx
+// provider are not converted to native Traefik configuration.
+var unconvertedReasons = map[string]string{
+	"affinity-canary-behavior":    "canary releases span several Ingresses, use a weighted TraefikService",
+	"auth-signin":                 "the ForwardAuth middleware does not redirect to a sign-in page",
+	"auth-snippet":                "NGINX configuration snippets are not converted",
+	"canary":                      "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-by-cookie":            "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-by-header":            "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-by-header-pattern":    "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-by-header-value":      "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-weight":               "canary releases span several Ingresses, use a weighted TraefikService",
+	"canary-weight-total":         "canary releases span several Ingresses, use a weighted TraefikService",
+	"custom-headers":              "the headers are read from a ConfigMap, add them to a Headers middleware",
+	"enable-modsecurity":          "requires the Traefik Hub WAF middleware",
+	"enable-owasp-core-rules":     "requires the Traefik Hub WAF middleware",
+	"from-to-www-redirect":        "requires a router on the other host with a RedirectRegex middleware",
+	"modsecurity-snippet":         "requires the Traefik Hub WAF middleware",
+	"modsecurity-transaction-id":  "requires the Traefik Hub WAF middleware",
+	"proxy-buffer-size":           "Traefik streams the responses, the NGINX buffers have no equivalent",
+	"proxy-buffering":             "Traefik streams the responses, the NGINX buffers have no equivalent",
+	"proxy-buffers-number":        "Traefik streams the responses, the NGINX buffers have no equivalent",
+	"proxy-http-version":          "Traefik negotiates the HTTP version with the backends",
+	"proxy-max-temp-file-size":    "Traefik streams the responses, the NGINX buffers have no equivalent",
+	"proxy-next-upstream-timeout": "the Retry middleware has no overall timeout",
+	"proxy-request-buffering":     "Traefik buffers requests only with the Buffering middleware, configure it explicitly",
+	"proxy-send-timeout":          "Traefik has no timeout between two writes to the backends",
+	"upstream-hash-by":            "Traefik has no consistent hashing on request variables",
+}
+
+// ingressModel is the Traefik configuration equivalent to an Ingress,
+// independent of the provider it is rendered for.
+type ingressModel struct {
+	namespace string
+	name      string
+
+	routes []route
+	tls    []netv1.IngressTLS
+
+	// passthrough are the TLS connections passed through to the backends,
+	// with the ssl-passthrough annotation.
+	passthrough []tcpRoute
+
+	// middlewares are applied, in order, to every route.
+	middlewares []namedMiddleware
+
+	scheme     string
+	nativeLB   bool
+	sticky     *sticky
+	transport  *transport
+	clientAuth *clientAuth
+
+	// authMap is whether the authentication Secret maps users to password
+	// hashes, rather than holding htpasswd lines in its auth key.
+	authMap bool
+
+	unconverted []Unconverted
+	warnings    []string
+}
+
+// authSecret returns the Secret of the basic or digest authentication of the Ingress, if any.
+func authSecret(m ingressModel) string {
+	for _, mw := range m.middlewares {
+		switch {
+		case mw.spec.BasicAuth != nil:
+			return mw.spec.BasicAuth.Secret
+		case mw.spec.DigestAuth != nil:
+			return mw.spec.DigestAuth.Secret
+		}
+	}
+	return ""
+}
+
+// namedMiddleware is a middleware named after its purpose, unique in the Ingress.
+type namedMiddleware struct {
+	purpose string
+	spec    middleware
+}
+
+// route is an Ingress path, or the Ingress default backend.
+type route struct {
+	hosts   []string
+	match   string
+	backend netv1.IngressServiceBackend
+
+	// priority is only set for the default backend, matching every request last.
+	priority int
+
+	// middlewares are applied to the route after the Ingress middlewares.
+	middlewares []namedMiddleware
+
+	// redirectHTTPS is whether the HTTP requests are redirected to HTTPS.
+	redirectHTTPS bool
+}
+
+// tcpRoute passes the TLS connections of a host through to a backend.
+type tcpRoute struct {
+	host    string
+	backend netv1.IngressServiceBackend
+}
+
+// transport is the configuration of the connections to the backends.
+type transport struct {
+	serverName         string
+	insecureSkipVerify bool
+	rootCAsSecret      string
+	certificatesSecret string
+	timeouts           forwardingTimeouts
+}
+
+// clientAuth is the TLS client authentication of the Ingress hosts.
+type clientAuth struct {
+	caSecret       string
+	clientAuthType string
+}
+
+// builder builds the model of an Ingress, tracking the handled annotations.
+type builder struct {
+	ing      *netv1.Ingress
+	services map[string]*corev1.Service
+	handled  map[string]struct{}
+	model    ingressModel
+}
+
+func buildModel(ing *netv1.Ingress, services map[string]*corev1.Service) ingressModel {
+	b := &builder{
+		ing:      ing,
+		services: services,
+		handled:  make(map[string]struct{}),
+		model:    ingressModel{namespace: ing.Namespace, name: ing.Name, tls: ing.Spec.TLS},
+	}
+
+	// Middlewares are added in the order NGINX applies the matching directives.
+	b.redirects()
+	// NGINX runs the rewrite phase of the snippets before the access phase.
+	b.snippets()
+	b.allowList()
+	b.rateLimit()
+	b.authentication()
+	b.clientAuthentication()
+	b.headers()
+	b.buffering()
+	b.errorPages()
+	b.retry()
+	b.service()
+	b.serversTransport()
+	b.passthrough()
+	b.routes()
+	b.leftovers()
+
+	return b.model
+}
+
+// get returns the value of the NGINX annotation, marking it as handled.
+func (b *builder) get(name string) (string, bool) {
+	b.handled[annotationPrefix+name] = struct{}{}
+
+	value, ok := b.ing.Annotations[annotationPrefix+name]
+	return strings.TrimSpace(value), ok
+}
+
+func (b *builder) isTrue(name string) bool {
+	value, _ := b.get(name)
+	return value == "true"
+}
+
+func (b *builder) unconverted(name, reason string) {
+	b.model.unconverted = append(b.model.unconverted, Unconverted{Annotation: annotationPrefix + name, Reason: reason})
+}
+
+func (b *builder) warnf(format string, args ...any) {
+	b.model.warnings = append(b.model.warnings, fmt.Sprintf(format, args...))
+}
+
+func (b *builder) addMiddleware(purpose string, spec middleware) {
+	b.model.middlewares = append(b.model.middlewares, namedMiddleware{purpose: purpose, spec: spec})
+}
+
+// secretName returns the name of the Secret referenced as "namespace/name" or
+// "name", warning when it lives in another namespace than the Ingress.
+func (b *builder) secretName(annotation, ref string) string {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return ref
+	}
+
+	if namespace != b.ing.Namespace {
+		b.warnf("%s%s references the Secret %s, which must be copied to the namespace %s", annotationPrefix, annotation, ref, b.ing.Namespace)
+	}
+
+	return name
+}
+
+func (b *builder) redirects() {
+	permanent, hasPermanent := b.get("permanent-redirect")
+	permanentCode, _ := b.get("permanent-redirect-code")
+	temporal, hasTemporal := b.get("temporal-redirect")
+	temporalCode, _ := b.get("temporal-redirect-code")
+
+	switch {
+	case hasTemporal:
+		// NGINX gives precedence to the temporal redirect.
+		b.addMiddleware("redirect", middleware{RedirectRegex: &redirectRegex{Regex: "^.*", Replacement: temporal}})
+		if temporalCode != "" && temporalCode != "302" && temporalCode != "307" {
+			b.warnf("the temporal redirect answers 302 (307 for non-GET requests) instead of %s", temporalCode)
+		}
+		if hasPermanent {
+			b.warnf("%spermanent-redirect is ignored in favor of %stemporal-redirect, as with NGINX", annotationPrefix, annotationPrefix)
+		}
+
+	case hasPermanent:
+		b.addMiddleware("redirect", middleware{RedirectRegex: &redirectRegex{Regex: "^.*", Replacement: permanent, Permanent: true}})
+		if permanentCode != "" && permanentCode != "301" && permanentCode != "308" {
+			b.warnf("the permanent redirect answers 301 (308 for non-GET requests) instead of %s", permanentCode)
+		}
+	}
+
+	if appRoot, ok := b.get("app-root"); ok {
+		b.addMiddleware("app-root", middleware{RedirectRegex: &redirectRegex{
+			Regex:       `^(https?://[^/]+)/$`,
+			Replacement: "${1}" + appRoot,
+		}})
+	}
+}
+
+func (b *builder) allowList() {
+	sourceRange, ok := b.get("allowlist-source-range")
+	if whitelist, hasWhitelist := b.get("whitelist-source-range"); !ok && hasWhitelist {
+		sourceRange, ok = whitelist, true
+	}
+	if !ok {
+		return
+	}
+
+	b.addMiddleware("allowlist", middleware{IPAllowList: &ipAllowList{SourceRange: splitList(sourceRange)}})
+}
+
+func (b *builder) rateLimit() {
+	// NGINX allows bursts of 5 times the rate by default.
+	const burstMultiplier = 5
+
+	rps, hasRPS := b.get("limit-rps")
+	rpm, hasRPM := b.get("limit-rpm")
+
+	switch {
+	case hasRPS:
+		if limit, err := strconv.ParseInt(rps, 10, 64); err == nil && limit > 0 {
+			b.addMiddleware("ratelimit", middleware{RateLimit: &rateLimit{Average: limit, Period: "1s", Burst: limit * burstMultiplier}})
+		} else {
+			b.unconverted("limit-rps", fmt.Sprintf("invalid rate %q", rps))
+		}
+		if hasRPM {
+			b.unconverted("limit-rpm", "a single rate limit is converted, the limit per second is kept")
+		}
+
+	case hasRPM:
+		if limit, err := strconv.ParseInt(rpm, 10, 64); err == nil && limit > 0 {
+			b.addMiddleware("ratelimit", middleware{RateLimit: &rateLimit{Average: limit, Period: "1m", Burst: limit * burstMultiplier}})
+		} else {
+			b.unconverted("limit-rpm", fmt.Sprintf("invalid rate %q", rpm))
+		}
+	}
+}
+
+func (b *builder) authentication() {
+	authType, hasAuthType := b.get("auth-type")
+	secret, _ := b.get("auth-secret")
+	realm, _ := b.get("auth-realm")
+	secretType, _ := b.get("auth-secret-type")
+
+	if hasAuthType {
+		switch {
+		case secret == "":
+			b.unconverted("auth-type", "requires the auth-secret annotation")
+
+		case authType == "basic" || authType == "digest":
+			auth := &basicAuth{Secret: b.secretName("auth-secret", secret), Realm: realm}
+			if authType == "basic" {
+				b.addMiddleware("auth", middleware{BasicAuth: auth})
+			} else {
+				b.addMiddleware("auth", middleware{DigestAuth: auth})
+			}
+
+			// The providers read the users differently, they warn about the Secret content.
+			b.model.authMap = secretType == "auth-map"
+
+		default:
+			b.unconverted("auth-type", fmt.Sprintf("unknown authentication type %q", authType))
+		}
+	}
+
+	authURL, ok := b.get("auth-url")
+	if !ok {
+		return
+	}
+
+	if strings.Contains(authURL, "$") {
+		b.unconverted("auth-url", "NGINX variables in the authentication URL have no equivalent")
+		return
+	}
+
+	if method, _ := b.get("auth-method"); method != "" && !strings.EqualFold(method, "GET") {
+		b.unconverted("auth-method", "the ForwardAuth middleware always sends GET requests")
+	}
+
+	responseHeaders, _ := b.get("auth-response-headers")
+	b.addMiddleware("forwardauth", middleware{ForwardAuth: &forwardAuth{
+		Address:             authURL,
+		AuthResponseHeaders: splitList(responseHeaders),
+	}})
+}
+
+func (b *builder) clientAuthentication() {
+	secret, ok := b.get("auth-tls-secret")
+	verifyClient, _ := b.get("auth-tls-verify-client")
+	passCertificate := b.isTrue("auth-tls-pass-certificate-to-upstream")
+
+	if !ok {
+		return
+	}
+
+	var clientAuthType string
+	switch cmp.Or(verifyClient, "on") {
+	case "on":
+		clientAuthType = "RequireAndVerifyClientCert"
+	case "optional":
+		clientAuthType = "VerifyClientCertIfGiven"
+	case "optional_no_ca":
+		clientAuthType = "RequestClientCert"
+	case "off":
+		return
+	default:
+		b.unconverted("auth-tls-verify-client", fmt.Sprintf("unknown verification %q", verifyClient))
+		return
+	}
+
+	b.model.clientAuth = &clientAuth{caSecret: b.secretName("auth-tls-secret", secret), clientAuthType: clientAuthType}
+
+	if passCertificate {
+		b.addMiddleware("pass-client-cert", middleware{PassTLSClientCert: &passTLSClientCert{PEM: true}})
+		b.warnf("the client certificate is passed in the X-Forwarded-Tls-Client-Cert header instead of ssl-client-cert")
+	}
+}
+
+func (b *builder) headers() {
+	var h headers
+
+	if b.isTrue("enable-cors") {
+		origins, _ := b.get("cors-allow-origin")
+		methods, _ := b.get("cors-allow-methods")
+		allowHeaders, _ := b.get("cors-allow-headers")
+		exposeHeaders, _ := b.get("cors-expose-headers")
+		credentials, _ := b.get("cors-allow-credentials")
+		maxAge, _ := b.get("cors-max-age")
+
+		h.AccessControlAllowOriginList = splitList(cmp.Or(origins, "*"))
+		h.AccessControlAllowMethods = splitList(cmp.Or(methods, "GET, PUT, POST, DELETE, PATCH, OPTIONS"))
+		h.AccessControlAllowHeaders = splitList(cmp.Or(allowHeaders, "DNT,Keep-Alive,User-Agent,X-Requested-With,If-Modified-Since,Cache-Control,Content-Type,Range,Authorization"))
+		h.AccessControlExposeHeaders = splitList(exposeHeaders)
+		h.AccessControlAllowCredentials = cmp.Or(credentials, "true") == "true"
+		h.AccessControlMaxAge, _ = strconv.ParseInt(cmp.Or(maxAge, "1728000"), 10, 64)
+		h.AddVaryHeader = true
+	} else {
+		// The CORS options have no effect without enable-cors.
+		for _, name := range []string{"cors-allow-origin", "cors-allow-methods", "cors-allow-headers", "cors-expose-headers", "cors-allow-credentials", "cors-max-age"} {
+			b.get(name)
+		}
+	}
+
+	if vhost, ok := b.get("upstream-vhost"); ok {
+		h.CustomRequestHeaders = map[string]string{"Host": vhost}
+	}
+
+	if prefix, ok := b.get("x-forwarded-prefix"); ok {
+		if h.CustomRequestHeaders == nil {
+			h.CustomRequestHeaders = make(map[string]string)
+		}
+		h.CustomRequestHeaders["X-Forwarded-Prefix"] = prefix
+	}
+
+	if h.AccessControlAllowOriginList != nil || h.CustomRequestHeaders != nil {
+		b.addMiddleware("headers", middleware{Headers: &h})
+	}
+}
+
+func (b *builder) buffering() {
+	var buf buffering
+
+	if value, ok := b.get("proxy-body-size"); ok {
+		size, err := parseSize(value)
+		if err != nil {
+			b.unconverted("proxy-body-size", err.Error())
+		}
+		// A size of 0 disables the limit.
+		buf.MaxRequestBodyBytes = size
+	}
+
+	if value, ok := b.get("client-body-buffer-size"); ok {
+		size, err := parseSize(value)
+		if err != nil {
+			b.unconverted("client-body-buffer-size", err.Error())
+		}
+		buf.MemRequestBodyBytes = size
+	}
+
+	if buf.MaxRequestBodyBytes > 0 || buf.MemRequestBodyBytes > 0 {
+		b.addMiddleware("buffering", middleware{Buffering: &buf})
+	}
+}
+
+func (b *builder) errorPages() {
+	codes, hasCodes := b.get("custom-http-errors")
+	backend, hasBackend := b.get("default-backend")
+
+	switch {
+	case hasCodes && !hasBackend:
+		b.unconverted("custom-http-errors", "the errors are served by the NGINX default backend, set the default-backend annotation or configure an Errors middleware")
+		return
+	case !hasBackend:
+		return
+	}
+
+	// Without custom errors, the default backend serves the requests when the
+	// Service has no endpoints.
+	status := []string{"503"}
+	if hasCodes {
+		status = splitList(codes)
+	}
+
+	b.addMiddleware("errors", middleware{Errors: &errorPage{
+		Status: status,
+		Service: serviceRef{
+			Name: backend,
+			Port: b.servicePort(backend),
+		},
+		Query: "/",
+	}})
+	b.warnf("the error pages are requested on / without the X-Code and X-Original-URI headers sent by NGINX")
+}
+
+// servicePort returns the first port of the Service of the Ingress namespace,
+// as NGINX does for the default backend.
+func (b *builder) servicePort(name string) int32 {
+	svc, ok := b.services[b.ing.Namespace+"/"+name]
+	if !ok || len(svc.Spec.Ports) == 0 {
+		b.warnf("the Service %s/%s is not found, its port is assumed to be 80", b.ing.Namespace, name)
+		return 80
+	}
+
+	return svc.Spec.Ports[0].Port
+}
+
+func (b *builder) retry() {
+	// NGINX tries 3 upstreams by default.
+	const defaultTries = 3
+
+	nextUpstream, hasNextUpstream := b.get("proxy-next-upstream")
+	tries, hasTries := b.get("proxy-next-upstream-tries")
+
+	if nextUpstream == "off" || (!hasNextUpstream && !hasTries) {
+		return
+	}
+
+	attempts := defaultTries
+	if hasTries {
+		var err error
+		if attempts, err = strconv.Atoi(tries); err != nil || attempts < 1 {
+			b.unconverted("proxy-next-upstream-tries", fmt.Sprintf("invalid tries %q", tries))
+			return
+		}
+	}
+
+	if strings.Contains(nextUpstream, "http_") || strings.Contains(nextUpstream, "invalid_header") {
+		b.warnf("the Retry middleware retries on connection errors only, not on %q", nextUpstream)
+	}
+
+	b.addMiddleware("retry", middleware{Retry: &retry{Attempts: attempts}})
+}
+
+func (b *builder) service() {
+	switch protocol, _ := b.get("backend-protocol"); strings.ToUpper(protocol) {
+	case "", "HTTP", "AUTO_HTTP":
+	case "HTTPS", "GRPCS":
+		b.model.scheme = "https"
+	case "GRPC":
+		b.model.scheme = "h2c"
+	default:
+		b.unconverted("backend-protocol", fmt.Sprintf("the %s protocol is not supported by Traefik", protocol))
+	}
+
+	b.model.nativeLB = b.isTrue("service-upstream")
+
+	affinity, _ := b.get("affinity")
+	name, _ := b.get("session-cookie-name")
+	path, _ := b.get("session-cookie-path")
+	domain, _ := b.get("session-cookie-domain")
+	sameSite, _ := b.get("session-cookie-samesite")
+	maxAge, _ := b.get("session-cookie-max-age")
+	expires, _ := b.get("session-cookie-expires")
+	secure := b.isTrue("session-cookie-secure")
+
+	switch affinity {
+	case "":
+	case "cookie":
+		cookie := stickyCookie{
+			Name:     cmp.Or(name, "INGRESSCOOKIE"),
+			Secure:   secure,
+			HTTPOnly: true,
+			SameSite: strings.ToLower(sameSite),
+			Path:     path,
+			Domain:   domain,
+		}
+		cookie.MaxAge, _ = strconv.Atoi(cmp.Or(maxAge, expires))
+
+		b.model.sticky = &sticky{Cookie: cookie}
+	default:
+		b.unconverted("affinity", fmt.Sprintf("the %s affinity is not supported by Traefik", affinity))
+	}
+}
+
+func (b *builder) serversTransport() {
+	var t transport
+
+	secret, hasSecret := b.get("proxy-ssl-secret")
+	verify, _ := b.get("proxy-ssl-verify")
+	sslName, _ := b.get("proxy-ssl-name")
+	serverNameEnabled, _ := b.get("proxy-ssl-server-name")
+
+	if hasSecret {
+		name := b.secretName("proxy-ssl-secret", secret)
+		t.certificatesSecret = name
+		if verify == "on" {
+			t.rootCAsSecret = name
+		}
+	}
+
+	// Unlike Traefik, NGINX does not verify the backend certificates by default.
+	t.insecureSkipVerify = b.model.scheme == "https" && verify != "on"
+
+	if serverNameEnabled == "on" || sslName != "" {
+		t.serverName = sslName
+	}
+
+	timeouts := []struct {
+		annotation string
+		value      *string
+	}{
+		{annotation: "proxy-connect-timeout", value: &t.timeouts.DialTimeout},
+		{annotation: "proxy-read-timeout", value: &t.timeouts.ResponseHeaderTimeout},
+	}
+	for _, timeout := range timeouts {
+		value, ok := b.get(timeout.annotation)
+		if !ok {
+			continue
+		}
+
+		duration, err := parseDuration(value)
+		if err != nil {
+			b.unconverted(timeout.annotation, err.Error())
+			continue
+		}
+		*timeout.value = duration
+	}
+
+	if t.timeouts.ResponseHeaderTimeout != "" {
+		b.warnf("the proxy read timeout is converted to a response header timeout, NGINX applies it between two reads")
+	}
+
+	// The TLS settings the ServersTransport does not have are flagged with it.
+	for _, name := range []string{"proxy-ssl-ciphers", "proxy-ssl-protocols", "proxy-ssl-verify-depth"} {
+		if _, ok := b.get(name); ok {
+			_, summary, _ := analyzer.RemediationSummary(annotationPrefix + name)
+			b.unconverted(name, summary)
+		}
+	}
+
+	if t != (transport{}) {
+		b.model.transport = &t
+	}
+}
+
+// passthrough passes the TLS connections of the hosts through to the backends of
+// their root path. NGINX routes the connections by SNI before reading any request,
+// it ignores the other paths of the hosts and the annotations over HTTPS.
+func (b *builder) passthrough() {
+	if !b.isTrue("ssl-passthrough") {
+		return
+	}
+
+	for _, rule := range b.ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		if rule.Host == "" {
+			b.warnf("the TLS connections are routed by SNI, the rule without host is not passed through")
+			continue
+		}
+
+		var root *netv1.IngressServiceBackend
+		for _, path := range rule.HTTP.Paths {
+			if root == nil && cmp.Or(path.Path, "/") == "/" && path.Backend.Service != nil {
+				root = path.Backend.Service
+				continue
+			}
+			b.warnf("NGINX ignores the path %q of the host %s over HTTPS, the TLS connections are passed through to the backend of the path \"/\"", path.Path, rule.Host)
+		}
+
+		if root == nil {
+			b.warnf("the host %s has no path \"/\", NGINX does not pass its TLS connections through", rule.Host)
+			continue
+		}
+
+		b.model.passthrough = append(b.model.passthrough, tcpRoute{host: rule.Host, backend: *root})
+	}
+
+	if len(b.ing.Spec.TLS) > 0 {
+		b.warnf("the TLS connections are passed through, the certificates of the Ingress are not served")
+	}
+	if len(b.model.middlewares) > 0 {
+		b.warnf("the TLS connections are passed through, the middlewares only apply to the HTTP requests")
+	}
+}
+
+// passthroughHosts returns the hosts passed through by the Ingresses, with the
+// Ingress passing them through as "namespace/name".
+func passthroughHosts(ingresses []*netv1.Ingress) map[string]string {
+	hosts := make(map[string]string)
+	for _, ing := range sortedIngresses(ingresses) {
+		if strings.TrimSpace(ing.Annotations[annotationPrefix+"ssl-passthrough"]) != "true" {
+			continue
+		}
+		for _, rule := range ing.Spec.Rules {
+			if _, ok := hosts[rule.Host]; rule.Host != "" && !ok {
+				hosts[rule.Host] = ing.Namespace + "/" + ing.Name
+			}
+		}
+	}
+	return hosts
+}
+
+// warnPassthroughHosts warns about the routes of the hosts passed through by
+// another Ingress, which NGINX silently ignores over HTTPS.
+func warnPassthroughHosts(m *ingressModel, hosts map[string]string) {
+	if len(m.passthrough) > 0 {
+		return
+	}
+
+	var warned []string
+	for _, r := range m.routes {
+		if len(r.hosts) == 0 || slices.Contains(warned, r.hosts[0]) {
+			continue
+		}
+		if owner, ok := hosts[r.hosts[0]]; ok {
+			warned = append(warned, r.hosts[0])
+			m.warnings = append(m.warnings, fmt.Sprintf("the Ingress %s passes the TLS connections of the host %s through, NGINX ignores the paths of this Ingress over HTTPS", owner, r.hosts[0]))
+		}
+	}
+}
+
+// nginxCapture matches the NGINX regex captures in rewrite targets, NGINX only
+// has the captures $1 to $9: $10 is the first capture followed by a 0.
+var nginxCapture = regexp.MustCompile(`\$(\d)`)
+
+// rewriteVariable matches the NGINX variables, which have no equivalent in rewrite targets.
+var rewriteVariable = regexp.MustCompile(`\$[a-zA-Z_]`)
+
+func (b *builder) routes() {
+	target, hasRewrite := b.get("rewrite-target")
+	regex := b.isTrue("use-regex") || hasRewrite
+
+	sslRedirect, _ := b.get("ssl-redirect")
+	forceSSLRedirect := b.isTrue("force-ssl-redirect")
+
+	aliases, _ := b.get("server-alias")
+
+	if hasRewrite && rewriteVariable.MatchString(target) {
+		b.unconverted("rewrite-target", "NGINX variables in the rewrite target have no equivalent")
+		hasRewrite = false
+	}
+
+	// NGINX redirects to the absolute rewrite targets.
+	redirect := hasRewrite && (strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"))
+	if _, err := redirectRegexFor("^/", target, 0, false); redirect && err != nil {
+		b.unconverted("rewrite-target", err.Error())
+		hasRewrite = false
+	}
+
+	regexAnnotation := "rewrite-target"
+	if b.isTrue("use-regex") {
+		regexAnnotation = "use-regex"
+	}
+
+	tlsHosts := make(map[string]struct{})
+	for _, tls := range b.ing.Spec.TLS {
+		for _, host := range tls.Hosts {
+			tlsHosts[host] = struct{}{}
+		}
+	}
+
+	// Rewrites are specific to each path, their middlewares are named after the
+	// index of the path when there are several.
+	var rewritePaths []string
+	if hasRewrite {
+		for _, rule := range b.ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if p := cmp.Or(path.Path, "/"); !slices.Contains(rewritePaths, p) {
+					rewritePaths = append(rewritePaths, p)
+				}
+			}
+		}
+	}
+
+	for _, rule := range b.ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+
+		var hosts []string
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+			hosts = append(hosts, splitList(aliases)...)
+		}
+
+		_, hasTLS := tlsHosts[rule.Host]
+		redirectHTTPS := forceSSLRedirect || (hasTLS && sslRedirect != "false")
+
+		for _, path := range rule.HTTP.Paths {
+			if path.Backend.Service == nil {
+				b.warnf("the resource backend of the path %q is not converted", path.Path)
+				continue
+			}
+
+			p := cmp.Or(path.Path, "/")
+			pathType := ptr.Deref(path.PathType, netv1.PathTypeImplementationSpecific)
+
+			if regex && pathType != netv1.PathTypeExact {
+				if err := analyzer.RegexPathError(p); err != nil {
+					b.unconverted(regexAnnotation, fmt.Sprintf("the route of the path %q is not converted: %v", p, err))
+					continue
+				}
+			}
+
+			r := route{
+				hosts:         hosts,
+				match:         joinMatchers(hostMatcher(hosts), pathMatcher(p, pathType, regex)),
+				backend:       *path.Backend.Service,
+				redirectHTTPS: redirectHTTPS,
+			}
+
+			if hasRewrite {
+				purpose := "rewrite"
+				if len(rewritePaths) > 1 {
+					purpose += "-" + strconv.Itoa(slices.Index(rewritePaths, p))
+				}
+
+				spec := middleware{ReplacePathRegex: &replacePathRegex{
+					// NGINX replaces the whole path with the target.
+					Regex:       "(?i)^(?:" + p + ").*",
+					Replacement: nginxCapture.ReplaceAllString(target, "$${$1}"),
+				}}
+				if redirect {
+					redirectRegex, err := redirectRegexFor("^(?i)"+p, target, captures(p), false)
+					if err != nil {
+						b.unconverted("rewrite-target", err.Error())
+						continue
+					}
+					spec = middleware{RedirectRegex: redirectRegex}
+				}
+
+				r.middlewares = []namedMiddleware{{purpose: purpose, spec: spec}}
+			}
+
+			b.model.routes = append(b.model.routes, r)
+		}
+	}
+
+	if backend := b.ing.Spec.DefaultBackend; backend != nil {
+		if backend.Service == nil {
+			b.warnf("the resource default backend is not converted")
+		} else {
+			b.model.routes = append(b.model.routes, route{
+				match:         "PathPrefix(`/`)",
+				backend:       *backend.Service,
+				priority:      1,
+				redirectHTTPS: forceSSLRedirect,
+			})
+		}
+	}
+}
+
+// leftovers reports the NGINX annotations which were not converted.
+func (b *builder) leftovers() {
+	var names []string
+	for annotation := range b.ing.Annotations {
+		if _, ok := b.handled[annotation]; !ok && analyzer.IsNginxAnnotation(annotation) {
+			names = append(names, annotation)
+		}
+	}
+	slices.Sort(names)
+
+	for _, annotation := range names {
+		name := strings.TrimPrefix(annotation, annotationPrefix)
+
+		switch status, _ := analyzer.ClassifyAnnotation(annotation); status {
+		case analyzer.AnnotationSupported:
+			b.unconverted(name, cmp.Or(unconvertedReasons[name], "not converted, the kubernetesIngressNginx provider supports it"))
+		case analyzer.AnnotationUnsupported:
+			b.unconverted(name, "not supported by Traefik")
+		default:
+			b.unconverted(name, "unknown annotation")
+		}
+	}
+
+	// The snippet directives are kept in line order.
+	slices.SortStableFunc(b.model.unconverted, func(a, b Unconverted) int {
+		return cmp.Compare(a.Annotation, b.Annotation)
+	})
+}
+
+// serviceRef references a Kubernetes Service from a Middleware.
+type serviceRef struct {
+	Name string `json:"name"`
+	Port int32  `json:"port"`
+}
+
+// hostMatcher matches any of the hosts, supporting wildcard hosts.
+func hostMatcher(hosts []string) string {
+	var matchers []string
+	for _, host := range hosts {
+		if suffix, ok := strings.CutPrefix(host, "*."); ok {
+			matchers = append(matchers, "HostRegexp(`^[^.]+\\."+regexp.QuoteMeta(suffix)+"$`)")
+			continue
+		}
+		matchers = append(matchers, "Host(`"+host+"`)")
+	}
+
+	if len(matchers) > 1 {
+		return "(" + strings.Join(matchers, " || ") + ")"
+	}
+	return strings.Join(matchers, "")
+}
+
+// captures returns the number of captures of the regex path.
+func captures(path string) int {
+	re, err := regexp.Compile(path)
+	if err != nil {
+		return 0
+	}
+	return re.NumSubexp()
+}
+
+// pathMatcher matches the path as NGINX does.
+func pathMatcher(path string, pathType netv1.PathType, regex bool) string {
+	switch {
+	case pathType == netv1.PathTypeExact:
+		return "Path(`" + path + "`)"
+	case regex:
+		// NGINX regex locations are case-insensitive.
+		return "PathRegexp(`(?i)^" + path + "`)"
+	case pathType == netv1.PathTypePrefix && path != "/":
+		// Prefix paths match on path elements.
+		trimmed := strings.TrimSuffix(path, "/")
+		return "(Path(`" + trimmed + "`) || PathPrefix(`" + trimmed + "/`))"
+	default:
+		return "PathPrefix(`" + path + "`)"
+	}
+}
+
+func joinMatchers(matchers ...string) string {
+	var nonEmpty []string
+	for _, matcher := range matchers {
+		if matcher != "" {
+			nonEmpty = append(nonEmpty, matcher)
+		}
+	}
+	return strings.Join(nonEmpty, " && ")
+}
+
+// splitList splits a comma-separated list.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseSize parses an NGINX size, such as 8m, in bytes.
+func parseSize(value string) (int64, error) {
+	if value == "" {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	multiplier := int64(1)
+
+	switch strings.ToLower(value[len(value)-1:]) {
+	case "k":
+		multiplier = 1 << 10
+	case "m":
+		multiplier = 1 << 20
+	case "g":
+		multiplier = 1 << 30
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+
+	return size * multiplier, nil
+}
+
+// durationRegexp matches the NGINX timeouts with units.
+var durationRegexp = regexp.MustCompile(`^([0-9]+(ms|s|m|h))+$`)
+
+// parseDuration parses an NGINX timeout, in seconds without unit.
+func parseDuration(value string) (string, error) {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return value + "s", nil
+	}
+
+	if durationRegexp.MatchString(value) {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("invalid timeout %q", value)
+}
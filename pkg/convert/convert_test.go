@@ -0,0 +1,322 @@
+package convert
+
+import (
+	"bytes"
+	"cmp"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// testInput covers the converted annotations, the TLS routes and the unconverted annotations.
+func testInput() Input {
+	return Input{
+		Ingresses: []*netv1.Ingress{
+			testutil.Ingress("shop", "web", map[string]string{
+				"nginx.ingress.kubernetes.io/enable-cors":            "true",
+				"nginx.ingress.kubernetes.io/cors-allow-origin":      "https://a.example.com, https://b.example.com",
+				"nginx.ingress.kubernetes.io/whitelist-source-range": "10.0.0.0/8,192.168.0.0/16",
+				"nginx.ingress.kubernetes.io/limit-rps":              "10",
+				"nginx.ingress.kubernetes.io/proxy-body-size":        "8m",
+				"nginx.ingress.kubernetes.io/affinity":               "cookie",
+				"nginx.ingress.kubernetes.io/session-cookie-name":    "route",
+				"nginx.ingress.kubernetes.io/limit-connections":      "5",
+				"nginx.ingress.kubernetes.io/totally-made-up":        "true",
+				"nginx.ingress.kubernetes.io/canary":                 "true",
+				"nginx.ingress.kubernetes.io/configuration-snippet":  "more_set_headers \"X-Frame-Options: DENY\";\nset $tenant shop;\n",
+			}, netv1.IngressSpec{
+				TLS: []netv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}},
+				Rules: []netv1.IngressRule{
+					testutil.Rule("shop.example.com",
+						testutil.Path("/", netv1.PathTypePrefix, "web", 80),
+						testutil.Path("/static", netv1.PathTypePrefix, "static", 8080),
+					),
+				},
+			}),
+			testutil.Ingress("api", "backend", map[string]string{
+				"nginx.ingress.kubernetes.io/rewrite-target":        "/$2",
+				"nginx.ingress.kubernetes.io/backend-protocol":      "HTTPS",
+				"nginx.ingress.kubernetes.io/proxy-connect-timeout": "5",
+				"nginx.ingress.kubernetes.io/auth-type":             "basic",
+				"nginx.ingress.kubernetes.io/auth-secret":           "api-users",
+				"nginx.ingress.kubernetes.io/auth-realm":            "API",
+				"nginx.ingress.kubernetes.io/auth-tls-secret":       "api/client-ca",
+				"nginx.ingress.kubernetes.io/default-backend":       "errors",
+				"nginx.ingress.kubernetes.io/custom-http-errors":    "404,503",
+				"nginx.ingress.kubernetes.io/server-alias":          "api.example.org",
+			}, netv1.IngressSpec{
+				TLS: []netv1.IngressTLS{{Hosts: []string{"api.example.com"}, SecretName: "api-tls"}},
+				Rules: []netv1.IngressRule{
+					testutil.Rule("api.example.com",
+						testutil.Path("/v1(/|$)(.*)", netv1.PathTypeImplementationSpecific, "api-v1", 443),
+						testutil.Path("/v2(/|$)(.*)", netv1.PathTypeImplementationSpecific, "api-v2", 443),
+					),
+				},
+			}),
+		},
+		Services: []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "api", Name: "errors"},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 8080}}},
+			},
+		},
+	}
+}
+
+func TestTraefikCRD(t *testing.T) {
+	t.Parallel()
+
+	conversions := TraefikCRD(testInput())
+
+	// Ingresses are converted in namespace then name order.
+	require.Len(t, conversions, 2)
+	assert.Equal(t, "api", conversions[0].Namespace)
+
+	assert.Equal(t, []Unconverted{
+		{Annotation: "nginx.ingress.kubernetes.io/canary", Reason: "canary releases span several Ingresses, use a weighted TraefikService"},
+		{Annotation: "nginx.ingress.kubernetes.io/configuration-snippet", Line: 2, Directive: "set $tenant shop;", Reason: "the set directive has no Traefik equivalent"},
+		{Annotation: "nginx.ingress.kubernetes.io/limit-connections", Reason: "not supported by Traefik"},
+		{Annotation: "nginx.ingress.kubernetes.io/totally-made-up", Reason: "unknown annotation"},
+	}, conversions[1].Unconverted)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, conversions))
+
+	testutil.AssertGolden(t, "traefik-crd.yaml", buf.Bytes())
+}
+
+func TestTraefikCRD_Collisions(t *testing.T) {
+	t.Parallel()
+
+	tls := netv1.IngressSpec{
+		TLS:   []netv1.IngressTLS{{SecretName: "cert"}},
+		Rules: []netv1.IngressRule{testutil.Rule("app.example.com", testutil.Path("/", netv1.PathTypePrefix, "app", 80))},
+	}
+
+	conversions := TraefikCRD(Input{Ingresses: []*netv1.Ingress{
+		testutil.Ingress("default", "app", nil, tls),
+		testutil.Ingress("default", "app-tls", nil, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("other.example.com", testutil.Path("/", netv1.PathTypePrefix, "other", 80))},
+		}),
+	}})
+
+	require.Len(t, conversions, 2)
+	assert.Equal(t, []string{"the objects are named after app-tls-7d146482, the IngressRoute default/app-tls colliding with the one generated for the Ingress default/app"}, conversions[1].Warnings)
+
+	// Both IngressRoutes are generated, with different names.
+	var routes []string
+	for _, conversion := range conversions {
+		for _, object := range conversion.Objects {
+			if object.Kind == "IngressRoute" {
+				routes = append(routes, object.Metadata.Name)
+			}
+		}
+	}
+	assert.ElementsMatch(t, []string{"app", "app-tls", "app-tls-7d146482"}, routes)
+}
+
+// passthroughInput has an ssl-passthrough Ingress sharing its host with another Ingress.
+func passthroughInput() Input {
+	return Input{Ingresses: []*netv1.Ingress{
+		testutil.Ingress("vault", "server", map[string]string{
+			"nginx.ingress.kubernetes.io/ssl-passthrough":    "true",
+			"nginx.ingress.kubernetes.io/force-ssl-redirect": "true",
+		}, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("vault.example.com",
+				testutil.Path("/", netv1.PathTypePrefix, "vault", 8200),
+				testutil.Path("/ui", netv1.PathTypePrefix, "vault-ui", 8000),
+			)},
+		}),
+		testutil.Ingress("vault", "metrics", nil, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"vault.example.com"}, SecretName: "vault-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("vault.example.com", testutil.Path("/metrics", netv1.PathTypePrefix, "metrics", 9090))},
+		}),
+	}}
+}
+
+func TestTraefikCRD_Passthrough(t *testing.T) {
+	t.Parallel()
+
+	conversions := TraefikCRD(passthroughInput())
+	require.Len(t, conversions, 2)
+
+	metrics, server := conversions[0], conversions[1]
+
+	assert.Equal(t, []string{
+		"the Ingress vault/server passes the TLS connections of the host vault.example.com through, NGINX ignores the paths of this Ingress over HTTPS",
+	}, metrics.Warnings)
+
+	assert.Empty(t, server.Unconverted)
+	assert.Equal(t, []string{
+		`NGINX ignores the path "/ui" of the host vault.example.com over HTTPS, the TLS connections are passed through to the backend of the path "/"`,
+	}, server.Warnings)
+
+	// The HTTP requests are still redirected, the TLS connections are routed by SNI.
+	var kinds []string
+	for _, object := range server.Objects {
+		kinds = append(kinds, object.Kind+" "+object.Metadata.Name)
+	}
+	assert.Equal(t, []string{"Middleware server-redirect-scheme", "IngressRoute server", "IngressRouteTCP server-passthrough"}, kinds)
+
+	assert.Equal(t, ingressRouteTCPSpec{
+		EntryPoints: []string{entryPointWebSecure},
+		Routes: []ingressRouteTCPRoute{{
+			Match:    "HostSNI(`vault.example.com`)",
+			Services: []tcpServiceLB{{Name: "vault", Port: intstr.FromInt32(8200)}},
+		}},
+		TLS: ingressRouteTCPTLS{Passthrough: true},
+	}, server.Objects[2].Spec)
+}
+
+func TestPathMatcher(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path     string
+		pathType netv1.PathType
+		regex    bool
+		want     string
+	}{
+		{path: "/", pathType: netv1.PathTypePrefix, want: "PathPrefix(`/`)"},
+		{path: "/api/", pathType: netv1.PathTypePrefix, want: "(Path(`/api`) || PathPrefix(`/api/`))"},
+		{path: "/api", pathType: netv1.PathTypeExact, regex: true, want: "Path(`/api`)"},
+		{path: "/api", pathType: netv1.PathTypeImplementationSpecific, want: "PathPrefix(`/api`)"},
+		{path: "/api/.*", pathType: netv1.PathTypeImplementationSpecific, regex: true, want: "PathRegexp(`(?i)^/api/.*`)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.pathType)+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, pathMatcher(tt.path, tt.pathType, tt.regex))
+		})
+	}
+}
+
+func TestRewriteTarget(t *testing.T) {
+	t.Parallel()
+
+	// The cases of the rewrite-target e2e suite, the rewrites are applied as Traefik
+	// does: to the path, or to the request URL for the redirects.
+	tests := []struct {
+		desc     string
+		path     string
+		pathType netv1.PathType
+		useRegex string
+		target   string
+		request  string
+		want     string
+	}{
+		{desc: "captures", path: "/app(/|$)(.*)", useRegex: "true", target: "/$2", request: "/app/foo/bar", want: "/foo/bar"},
+		{desc: "empty capture", path: "/api(/|$)(.*)", useRegex: "true", target: "/$2", request: "/API", want: "/"},
+		{desc: "without use-regex", path: "/original", pathType: netv1.PathTypePrefix, useRegex: "false", target: "/rewritten", request: "/original/other", want: "/rewritten"},
+		{desc: "exact path", path: "/original", pathType: netv1.PathTypeExact, target: "/rewritten", request: "/original", want: "/rewritten"},
+		{desc: "single digit captures", path: "/(a)(b)", useRegex: "true", target: "/$10", request: "/ab", want: "/a0"},
+		{desc: "redirect", path: "/original", target: "https://bar.example.org/$1", request: "http://foo.example.com/original/a/b/c", want: "https://bar.example.org/"},
+		{desc: "redirect with query", path: "/original", target: "https://bar.example.org/$1", request: "http://foo.example.com/original?a=b", want: "https://bar.example.org/?a=b"},
+		{desc: "redirect with capture", path: "/original/(.*)", useRegex: "true", target: "https://bar.example.org/$1", request: "https://foo.example.com/original/a/b?c=d", want: "https://bar.example.org/a/b?c=d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			annotations := map[string]string{annotationPrefix + "rewrite-target": tt.target}
+			if tt.useRegex != "" {
+				annotations[annotationPrefix+"use-regex"] = tt.useRegex
+			}
+
+			ing := testutil.Ingress("default", "app", annotations, netv1.IngressSpec{
+				Rules: []netv1.IngressRule{testutil.Rule("foo.example.com", testutil.Path(tt.path, cmp.Or(tt.pathType, netv1.PathTypeImplementationSpecific), "app", 80))},
+			})
+
+			m := buildModel(ing, nil)
+			require.Len(t, m.routes, 1)
+			require.Len(t, m.routes[0].middlewares, 1)
+
+			var re *regexp.Regexp
+			var replacement string
+			switch spec := m.routes[0].middlewares[0].spec; {
+			case spec.ReplacePathRegex != nil:
+				re, replacement = regexp.MustCompile(spec.ReplacePathRegex.Regex), spec.ReplacePathRegex.Replacement
+			case spec.RedirectRegex != nil:
+				assert.False(t, spec.RedirectRegex.Permanent)
+				re, replacement = regexp.MustCompile(spec.RedirectRegex.Regex), spec.RedirectRegex.Replacement
+			default:
+				require.Fail(t, "no rewrite middleware")
+			}
+
+			assert.Equal(t, tt.want, re.ReplaceAllString(tt.request, replacement))
+		})
+	}
+}
+
+func TestRewriteTarget_PCRE(t *testing.T) {
+	t.Parallel()
+
+	ing := testutil.Ingress("default", "app", map[string]string{
+		annotationPrefix + "use-regex": "true",
+	}, netv1.IngressSpec{
+		Rules: []netv1.IngressRule{testutil.Rule("foo.example.com",
+			testutil.Path("/(?!admin).*", netv1.PathTypeImplementationSpecific, "app", 80),
+			testutil.Path("/admin", netv1.PathTypeImplementationSpecific, "admin", 80),
+		)},
+	})
+
+	m := buildModel(ing, nil)
+
+	require.Len(t, m.routes, 1)
+	assert.Equal(t, "Host(`foo.example.com`) && PathRegexp(`(?i)^/admin`)", m.routes[0].match)
+	assert.Equal(t, []Unconverted{{
+		Annotation: annotationPrefix + "use-regex",
+		Reason:     `the route of the path "/(?!admin).*" is not converted: Go regexes do not support the PCRE lookaheads`,
+	}}, m.unconverted)
+}
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	for value, want := range map[string]int64{"0": 0, "512": 512, "1k": 1024, "8m": 8 << 20, "1G": 1 << 30} {
+		size, err := parseSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, size, value)
+	}
+
+	for _, value := range []string{"", "m", "-1", "1t"} {
+		_, err := parseSize(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestServersTransport(t *testing.T) {
+	t.Parallel()
+
+	ing := testutil.Ingress("default", "app", map[string]string{
+		annotationPrefix + "backend-protocol":       "HTTPS",
+		annotationPrefix + "proxy-ssl-secret":       "default/backend-ca",
+		annotationPrefix + "proxy-ssl-verify":       "on",
+		annotationPrefix + "proxy-ssl-name":         "backend.internal",
+		annotationPrefix + "proxy-ssl-verify-depth": "2",
+		annotationPrefix + "proxy-ssl-protocols":    "TLSv1.3",
+	}, netv1.IngressSpec{
+		Rules: []netv1.IngressRule{testutil.Rule("foo.example.com", testutil.Path("/", netv1.PathTypePrefix, "app", 443))},
+	})
+
+	m := buildModel(ing, nil)
+
+	assert.Equal(t, &transport{serverName: "backend.internal", rootCAsSecret: "backend-ca", certificatesSecret: "backend-ca"}, m.transport)
+
+	// The unsupported TLS settings are flagged with the ServersTransport.
+	require.Len(t, m.unconverted, 2)
+	assert.Equal(t, annotationPrefix+"proxy-ssl-protocols", m.unconverted[0].Annotation)
+	assert.Contains(t, m.unconverted[0].Reason, "The ServersTransport does not configure the TLS versions")
+	assert.Equal(t, annotationPrefix+"proxy-ssl-verify-depth", m.unconverted[1].Annotation)
+	assert.Contains(t, m.unconverted[1].Reason, "without depth limit")
+}
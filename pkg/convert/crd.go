@@ -0,0 +1,398 @@
+package convert
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TargetTraefikCRD is the conversion target generating Traefik custom resources.
+const TargetTraefikCRD = "traefik-crd"
+
+const traefikAPIVersion = "traefik.io/v1alpha1"
+
+// Traefik entry points the IngressRoutes are attached to.
+const (
+	entryPointWeb       = "web"
+	entryPointWebSecure = "websecure"
+)
+
+// Object is a Kubernetes object.
+type Object struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       any        `json:"spec"`
+}
+
+// ObjectMeta is the metadata of a generated object.
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type ingressRouteSpec struct {
+	EntryPoints []string            `json:"entryPoints"`
+	Routes      []ingressRouteRoute `json:"routes"`
+	TLS         *ingressRouteTLS    `json:"tls,omitempty"`
+}
+
+type ingressRouteRoute struct {
+	Kind        string          `json:"kind"`
+	Match       string          `json:"match"`
+	Priority    int             `json:"priority,omitempty"`
+	Middlewares []middlewareRef `json:"middlewares,omitempty"`
+	Services    []serviceLB     `json:"services"`
+}
+
+type ingressRouteTCPSpec struct {
+	EntryPoints []string               `json:"entryPoints"`
+	Routes      []ingressRouteTCPRoute `json:"routes"`
+	TLS         ingressRouteTCPTLS     `json:"tls"`
+}
+
+type ingressRouteTCPRoute struct {
+	Match    string         `json:"match"`
+	Services []tcpServiceLB `json:"services"`
+}
+
+type tcpServiceLB struct {
+	Name string             `json:"name"`
+	Port intstr.IntOrString `json:"port"`
+}
+
+type ingressRouteTCPTLS struct {
+	Passthrough bool `json:"passthrough"`
+}
+
+type middlewareRef struct {
+	Name string `json:"name"`
+}
+
+type serviceLB struct {
+	Name             string             `json:"name"`
+	Port             intstr.IntOrString `json:"port"`
+	Scheme           string             `json:"scheme,omitempty"`
+	NativeLB         bool               `json:"nativeLB,omitempty"`
+	ServersTransport string             `json:"serversTransport,omitempty"`
+	Sticky           *sticky            `json:"sticky,omitempty"`
+}
+
+type ingressRouteTLS struct {
+	SecretName string      `json:"secretName,omitempty"`
+	Options    *tlsOptsRef `json:"options,omitempty"`
+}
+
+type tlsOptsRef struct {
+	Name string `json:"name"`
+}
+
+type serversTransportSpec struct {
+	ServerName          string              `json:"serverName,omitempty"`
+	InsecureSkipVerify  bool                `json:"insecureSkipVerify,omitempty"`
+	RootCAs             []rootCA            `json:"rootCAs,omitempty"`
+	CertificatesSecrets []string            `json:"certificatesSecrets,omitempty"`
+	ForwardingTimeouts  *forwardingTimeouts `json:"forwardingTimeouts,omitempty"`
+}
+
+type rootCA struct {
+	Secret    string `json:"secret,omitempty"`
+	ConfigMap string `json:"configMap,omitempty"`
+}
+
+type tlsOptionSpec struct {
+	ClientAuth tlsClientAuth `json:"clientAuth"`
+}
+
+type tlsClientAuth struct {
+	SecretNames    []string `json:"secretNames"`
+	ClientAuthType string   `json:"clientAuthType"`
+}
+
+// TraefikCRD converts the Ingresses to IngressRoutes, with the Middlewares,
+// ServersTransports and TLSOptions their annotations require, and to IngressRouteTCPs
+// passing the TLS connections through with the ssl-passthrough annotation.
+// The objects are named after their Ingress and created in its namespace, with a hash
+// of the Ingress when their names collide with the objects of another Ingress.
+func TraefikCRD(input Input) []Conversion {
+	services := indexServices(input.Services)
+
+	passthrough := passthroughHosts(input.Ingresses)
+
+	ingresses := sortedIngresses(input.Ingresses)
+	models := make([]ingressModel, 0, len(ingresses))
+	objects := make([][]Object, 0, len(ingresses))
+
+	// Objects of different Ingresses can collide, such as the HTTPS IngressRoute
+	// "app-tls" of the Ingress "app" and the HTTP IngressRoute of the Ingress "app-tls":
+	// the first Ingress keeps the name.
+	owners := make(map[string]string)
+	for _, ing := range ingresses {
+		m := buildModel(ing, services)
+		warnPassthroughHosts(&m, passthrough)
+
+		models = append(models, m)
+		objects = append(objects, crdObjects(m, m.name))
+		for _, object := range objects[len(objects)-1] {
+			if _, ok := owners[objectKey(object)]; !ok {
+				owners[objectKey(object)] = m.namespace + "/" + m.name
+			}
+		}
+	}
+
+	conversions := make([]Conversion, 0, len(ingresses))
+	for i, m := range models {
+		ref := m.namespace + "/" + m.name
+
+		conversion := Conversion{
+			Namespace:   m.namespace,
+			Name:        m.name,
+			Objects:     objects[i],
+			Unconverted: m.unconverted,
+			Warnings:    m.warnings,
+		}
+
+		// Traefik reads htpasswd lines from the users key of the Secret.
+		if secret := authSecret(m); secret != "" {
+			if m.authMap {
+				conversion.Warnings = append(conversion.Warnings, fmt.Sprintf("the Secret %s maps users to password hashes, Traefik reads htpasswd lines from its users key", secret))
+			} else {
+				conversion.Warnings = append(conversion.Warnings, fmt.Sprintf("the Secret %s holds the htpasswd content in its auth key, Traefik reads it from its users key", secret))
+			}
+		}
+
+		// The objects colliding with those of another Ingress are renamed with a hash of the Ingress.
+		if j := slices.IndexFunc(conversion.Objects, func(object Object) bool { return owners[objectKey(object)] != ref }); j >= 0 {
+			key := objectKey(conversion.Objects[j])
+			base := m.name + "-" + nameHash(ref)
+			conversion.Warnings = append(conversion.Warnings, fmt.Sprintf("the objects are named after %s, the %s colliding with the one generated for the Ingress %s", base, key, owners[key]))
+			conversion.Objects = crdObjects(m, base)
+
+			// A generated name is never applied twice, which would silently replace the routing of another Ingress.
+			conversion.Objects = slices.DeleteFunc(conversion.Objects, func(object Object) bool {
+				key := objectKey(object)
+				if owner, ok := owners[key]; ok && owner != ref {
+					conversion.Warnings = append(conversion.Warnings, fmt.Sprintf("the %s is not generated, it collides with the one generated for the Ingress %s", key, owner))
+					return true
+				}
+				owners[key] = ref
+				return false
+			})
+		}
+
+		conversions = append(conversions, conversion)
+	}
+
+	return conversions
+}
+
+// objectKey identifies the object as "Kind namespace/name".
+func objectKey(object Object) string {
+	return object.Kind + " " + object.Metadata.Namespace + "/" + object.Metadata.Name
+}
+
+// nameHash returns a short hash of the Ingress reference, to tell its objects apart.
+func nameHash(ref string) string {
+	sum := sha256.Sum256([]byte(ref))
+	return hex.EncodeToString(sum[:4])
+}
+
+// crdObjects returns the objects of the Ingress, named after base.
+func crdObjects(m ingressModel, base string) []Object {
+	newObject := func(kind, name string, spec any) Object {
+		return Object{
+			APIVersion: traefikAPIVersion,
+			Kind:       kind,
+			Metadata:   ObjectMeta{Name: name, Namespace: m.namespace},
+			Spec:       spec,
+		}
+	}
+	objectName := func(purpose string) string {
+		return base + "-" + purpose
+	}
+
+	var objects []Object
+
+	// Middlewares, declared once even when shared by several routes.
+	var declared []string
+	declare := func(mw namedMiddleware) {
+		if slices.Contains(declared, mw.purpose) {
+			return
+		}
+		declared = append(declared, mw.purpose)
+		objects = append(objects, newObject("Middleware", objectName(mw.purpose), mw.spec))
+	}
+
+	for _, mw := range m.middlewares {
+		declare(mw)
+	}
+	for _, r := range m.routes {
+		for _, mw := range r.middlewares {
+			declare(mw)
+		}
+	}
+
+	redirect := namedMiddleware{purpose: "redirect-scheme", spec: middleware{RedirectScheme: &redirectScheme{Scheme: "https", Permanent: true}}}
+	if slices.ContainsFunc(m.routes, func(r route) bool { return r.redirectHTTPS }) {
+		declare(redirect)
+	}
+
+	service := serviceLB{Scheme: m.scheme, NativeLB: m.nativeLB, Sticky: m.sticky}
+
+	if t := m.transport; t != nil {
+		spec := serversTransportSpec{
+			ServerName:         t.serverName,
+			InsecureSkipVerify: t.insecureSkipVerify,
+		}
+		if t.rootCAsSecret != "" {
+			spec.RootCAs = []rootCA{{Secret: t.rootCAsSecret}}
+		}
+		if t.certificatesSecret != "" {
+			spec.CertificatesSecrets = []string{t.certificatesSecret}
+		}
+		if t.timeouts != (forwardingTimeouts{}) {
+			spec.ForwardingTimeouts = &t.timeouts
+		}
+
+		objects = append(objects, newObject("ServersTransport", objectName("transport"), spec))
+		service.ServersTransport = objectName("transport")
+	}
+
+	var tlsOptions *tlsOptsRef
+	if ca := m.clientAuth; ca != nil {
+		objects = append(objects, newObject("TLSOption", objectName("tls-options"), tlsOptionSpec{
+			ClientAuth: tlsClientAuth{SecretNames: []string{ca.caSecret}, ClientAuthType: ca.clientAuthType},
+		}))
+		tlsOptions = &tlsOptsRef{Name: objectName("tls-options")}
+	}
+
+	crdRoute := func(r route, middlewares []namedMiddleware) ingressRouteRoute {
+		svc := service
+		svc.Name = r.backend.Name
+		svc.Port = servicePort(r.backend.Port)
+
+		rte := ingressRouteRoute{Kind: "Rule", Match: r.match, Priority: r.priority, Services: []serviceLB{svc}}
+		for _, mw := range middlewares {
+			rte.Middlewares = append(rte.Middlewares, middlewareRef{Name: objectName(mw.purpose)})
+		}
+		return rte
+	}
+
+	// HTTP routes, redirecting to HTTPS when required.
+	var webRoutes []ingressRouteRoute
+	for _, r := range m.routes {
+		if r.redirectHTTPS {
+			webRoutes = append(webRoutes, crdRoute(r, []namedMiddleware{redirect}))
+			continue
+		}
+		webRoutes = append(webRoutes, crdRoute(r, slices.Concat(m.middlewares, r.middlewares)))
+	}
+
+	if len(webRoutes) > 0 {
+		objects = append(objects, newObject("IngressRoute", base, ingressRouteSpec{
+			EntryPoints: []string{entryPointWeb},
+			Routes:      webRoutes,
+		}))
+	}
+
+	// The TLS connections of the passed through hosts are routed by SNI.
+	if len(m.passthrough) > 0 {
+		var routes []ingressRouteTCPRoute
+		for _, r := range m.passthrough {
+			routes = append(routes, ingressRouteTCPRoute{
+				Match:    "HostSNI(`" + r.host + "`)",
+				Services: []tcpServiceLB{{Name: r.backend.Name, Port: servicePort(r.backend.Port)}},
+			})
+		}
+
+		objects = append(objects, newObject("IngressRouteTCP", objectName("passthrough"), ingressRouteTCPSpec{
+			EntryPoints: []string{entryPointWebSecure},
+			Routes:      routes,
+			TLS:         ingressRouteTCPTLS{Passthrough: true},
+		}))
+
+		return objects
+	}
+
+	// HTTPS routes, one IngressRoute per certificate.
+	for i, group := range tlsGroups(m) {
+		if len(group) == 0 {
+			continue
+		}
+
+		var routes []ingressRouteRoute
+		for _, r := range group {
+			routes = append(routes, crdRoute(r, slices.Concat(m.middlewares, r.middlewares)))
+		}
+
+		name := objectName("tls")
+		if i > 0 {
+			name += "-" + strconv.Itoa(i)
+		}
+
+		objects = append(objects, newObject("IngressRoute", name, ingressRouteSpec{
+			EntryPoints: []string{entryPointWebSecure},
+			Routes:      routes,
+			TLS:         &ingressRouteTLS{SecretName: m.tls[i].SecretName, Options: tlsOptions},
+		}))
+	}
+
+	return objects
+}
+
+// tlsGroups groups the routes by the TLS entry of the Ingress covering their host.
+// The routes of the hosts covered by no entry are served with the first entry,
+// as NGINX serves them over HTTPS with its default certificate.
+func tlsGroups(m ingressModel) [][]route {
+	if len(m.tls) == 0 {
+		return nil
+	}
+
+	groups := make([][]route, len(m.tls))
+	for _, r := range m.routes {
+		index := 0
+		if len(r.hosts) > 0 {
+			for i, tls := range m.tls {
+				if slices.Contains(tls.Hosts, r.hosts[0]) {
+					index = i
+					break
+				}
+			}
+		}
+		groups[index] = append(groups[index], r)
+	}
+
+	return groups
+}
+
+func servicePort(port netv1.ServiceBackendPort) intstr.IntOrString {
+	if port.Name != "" {
+		return intstr.FromString(port.Name)
+	}
+	return intstr.FromInt32(port.Number)
+}
+
+func indexServices(services []*corev1.Service) map[string]*corev1.Service {
+	index := make(map[string]*corev1.Service, len(services))
+	for _, svc := range services {
+		index[svc.Namespace+"/"+svc.Name] = svc
+	}
+	return index
+}
+
+// sortedIngresses returns the Ingresses sorted by namespace then name, for a
+// deterministic output.
+func sortedIngresses(ingresses []*netv1.Ingress) []*netv1.Ingress {
+	sorted := slices.Clone(ingresses)
+	slices.SortFunc(sorted, func(a, b *netv1.Ingress) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+	return sorted
+}
@@ -0,0 +1,116 @@
+package convert
+
+// The types below mirror the Traefik dynamic configuration, shared by the
+// Middleware, ServersTransport and TLSOption resources and the file provider.
+// Only the options the conversion produces are declared.
+
+// middleware is a Traefik middleware configuration. A single option is set.
+type middleware struct {
+	RedirectRegex     *redirectRegex     `json:"redirectRegex,omitempty"`
+	RedirectScheme    *redirectScheme    `json:"redirectScheme,omitempty"`
+	IPAllowList       *ipAllowList       `json:"ipAllowList,omitempty"`
+	RateLimit         *rateLimit         `json:"rateLimit,omitempty"`
+	BasicAuth         *basicAuth         `json:"basicAuth,omitempty"`
+	DigestAuth        *basicAuth         `json:"digestAuth,omitempty"`
+	ForwardAuth       *forwardAuth       `json:"forwardAuth,omitempty"`
+	PassTLSClientCert *passTLSClientCert `json:"passTLSClientCert,omitempty"`
+	Headers           *headers           `json:"headers,omitempty"`
+	Buffering         *buffering         `json:"buffering,omitempty"`
+	Errors            *errorPage         `json:"errors,omitempty"`
+	Retry             *retry             `json:"retry,omitempty"`
+	ReplacePathRegex  *replacePathRegex  `json:"replacePathRegex,omitempty"`
+}
+
+type redirectRegex struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+	Permanent   bool   `json:"permanent,omitempty"`
+}
+
+type redirectScheme struct {
+	Scheme    string `json:"scheme"`
+	Permanent bool   `json:"permanent,omitempty"`
+}
+
+type ipAllowList struct {
+	SourceRange []string `json:"sourceRange"`
+}
+
+type rateLimit struct {
+	Average int64  `json:"average"`
+	Period  string `json:"period,omitempty"`
+	Burst   int64  `json:"burst,omitempty"`
+}
+
+// basicAuth is the configuration of the BasicAuth and DigestAuth middlewares.
+// The users are read from a Secret with the CRD provider and from a file with the file provider.
+type basicAuth struct {
+	Secret    string `json:"secret,omitempty"`
+	UsersFile string `json:"usersFile,omitempty"`
+	Realm     string `json:"realm,omitempty"`
+}
+
+type forwardAuth struct {
+	Address             string   `json:"address"`
+	AuthResponseHeaders []string `json:"authResponseHeaders,omitempty"`
+}
+
+type passTLSClientCert struct {
+	PEM bool `json:"pem"`
+}
+
+type headers struct {
+	CustomRequestHeaders  map[string]string `json:"customRequestHeaders,omitempty"`
+	CustomResponseHeaders map[string]string `json:"customResponseHeaders,omitempty"`
+
+	AccessControlAllowCredentials bool     `json:"accessControlAllowCredentials,omitempty"`
+	AccessControlAllowHeaders     []string `json:"accessControlAllowHeaders,omitempty"`
+	AccessControlAllowMethods     []string `json:"accessControlAllowMethods,omitempty"`
+	AccessControlAllowOriginList  []string `json:"accessControlAllowOriginList,omitempty"`
+	AccessControlExposeHeaders    []string `json:"accessControlExposeHeaders,omitempty"`
+	AccessControlMaxAge           int64    `json:"accessControlMaxAge,omitempty"`
+	AddVaryHeader                 bool     `json:"addVaryHeader,omitempty"`
+}
+
+type buffering struct {
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty"`
+	MemRequestBodyBytes int64 `json:"memRequestBodyBytes,omitempty"`
+}
+
+// errorPage is the configuration of the Errors middleware.
+// The service is a reference with the CRD provider and a service name with the file provider.
+type errorPage struct {
+	Status  []string `json:"status"`
+	Service any      `json:"service"`
+	Query   string   `json:"query"`
+}
+
+type retry struct {
+	Attempts int `json:"attempts"`
+}
+
+type replacePathRegex struct {
+	Regex       string `json:"regex"`
+	Replacement string `json:"replacement"`
+}
+
+// forwardingTimeouts are the timeouts of the requests forwarded to the backends.
+type forwardingTimeouts struct {
+	DialTimeout           string `json:"dialTimeout,omitempty"`
+	ResponseHeaderTimeout string `json:"responseHeaderTimeout,omitempty"`
+}
+
+// sticky is the sticky sessions configuration of a service.
+type sticky struct {
+	Cookie stickyCookie `json:"cookie"`
+}
+
+type stickyCookie struct {
+	Name     string `json:"name"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+	MaxAge   int    `json:"maxAge,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+}
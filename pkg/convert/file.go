@@ -0,0 +1,455 @@
+package convert
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
+	"k8s.io/utils/ptr"
+)
+
+// TargetTraefikFile is the conversion target generating the dynamic configuration
+// of the Traefik file provider.
+const TargetTraefikFile = "traefik-file"
+
+// Formats of the file provider configuration.
+const (
+	FileFormatYAML = "yaml"
+	FileFormatTOML = "toml"
+)
+
+// nameSeparator separates the parts of the file provider object names.
+// It cannot appear in Kubernetes object names, so that the names built from
+// different namespaces, Ingresses and Services never collide.
+const nameSeparator = "_"
+
+// FileConfiguration is the dynamic configuration of the Traefik file provider.
+type FileConfiguration struct {
+	HTTP fileHTTPConfiguration `json:"http"`
+	TCP  *fileTCPConfiguration `json:"tcp,omitempty"`
+	TLS  *fileTLSConfiguration `json:"tls,omitempty"`
+}
+
+type fileHTTPConfiguration struct {
+	Routers           map[string]fileRouter           `json:"routers,omitempty"`
+	Services          map[string]fileService          `json:"services,omitempty"`
+	Middlewares       map[string]middleware           `json:"middlewares,omitempty"`
+	ServersTransports map[string]fileServersTransport `json:"serversTransports,omitempty"`
+}
+
+type fileRouter struct {
+	EntryPoints []string       `json:"entryPoints"`
+	Rule        string         `json:"rule"`
+	Priority    int            `json:"priority,omitempty"`
+	Middlewares []string       `json:"middlewares,omitempty"`
+	Service     string         `json:"service"`
+	TLS         *fileRouterTLS `json:"tls,omitempty"`
+}
+
+type fileRouterTLS struct {
+	Options string `json:"options,omitempty"`
+}
+
+type fileService struct {
+	LoadBalancer fileLoadBalancer `json:"loadBalancer"`
+}
+
+type fileLoadBalancer struct {
+	Servers          []fileServer `json:"servers"`
+	Sticky           *sticky      `json:"sticky,omitempty"`
+	ServersTransport string       `json:"serversTransport,omitempty"`
+}
+
+type fileServer struct {
+	URL string `json:"url"`
+}
+
+type fileServersTransport struct {
+	ServerName         string              `json:"serverName,omitempty"`
+	InsecureSkipVerify bool                `json:"insecureSkipVerify,omitempty"`
+	RootCAs            []string            `json:"rootCAs,omitempty"`
+	Certificates       []fileCertificate   `json:"certificates,omitempty"`
+	ForwardingTimeouts *forwardingTimeouts `json:"forwardingTimeouts,omitempty"`
+}
+
+type fileCertificate struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+}
+
+// fileTCPConfiguration passes the TLS connections through, with the ssl-passthrough annotation.
+type fileTCPConfiguration struct {
+	Routers  map[string]fileTCPRouter  `json:"routers"`
+	Services map[string]fileTCPService `json:"services"`
+}
+
+type fileTCPRouter struct {
+	EntryPoints []string         `json:"entryPoints"`
+	Rule        string           `json:"rule"`
+	Service     string           `json:"service"`
+	TLS         fileTCPRouterTLS `json:"tls"`
+}
+
+type fileTCPRouterTLS struct {
+	Passthrough bool `json:"passthrough"`
+}
+
+type fileTCPService struct {
+	LoadBalancer fileTCPLoadBalancer `json:"loadBalancer"`
+}
+
+type fileTCPLoadBalancer struct {
+	Servers []fileTCPServer `json:"servers"`
+}
+
+type fileTCPServer struct {
+	Address string `json:"address"`
+}
+
+type fileTLSConfiguration struct {
+	Certificates []fileCertificate        `json:"certificates,omitempty"`
+	Options      map[string]fileTLSOption `json:"options,omitempty"`
+}
+
+type fileTLSOption struct {
+	ClientAuth fileClientAuth `json:"clientAuth"`
+}
+
+type fileClientAuth struct {
+	CAFiles        []string `json:"caFiles"`
+	ClientAuthType string   `json:"clientAuthType"`
+}
+
+// TraefikFile converts the Ingresses to the dynamic configuration of the Traefik
+// file provider. The objects are named "<namespace>_<ingress>_<purpose>".
+// The server URLs are the ready endpoints of the Services, or their cluster IP
+// with the service-upstream annotation, as with NGINX.
+// The Secrets are read from files exported under secretsDir, as <namespace>/<name>/<key>.
+func TraefikFile(input Input, secretsDir string) (FileConfiguration, []Conversion) {
+	f := &fileBuilder{
+		services:   indexServices(input.Services),
+		endpoints:  indexEndpointSlices(input.EndpointSlices),
+		secretsDir: secretsDir,
+		config: FileConfiguration{HTTP: fileHTTPConfiguration{
+			Routers:           make(map[string]fileRouter),
+			Services:          make(map[string]fileService),
+			Middlewares:       make(map[string]middleware),
+			ServersTransports: make(map[string]fileServersTransport),
+		}},
+		tls: &fileTLSConfiguration{Options: make(map[string]fileTLSOption)},
+		tcp: &fileTCPConfiguration{
+			Routers:  make(map[string]fileTCPRouter),
+			Services: make(map[string]fileTCPService),
+		},
+	}
+
+	passthrough := passthroughHosts(input.Ingresses)
+
+	conversions := make([]Conversion, 0, len(input.Ingresses))
+	for _, ing := range sortedIngresses(input.Ingresses) {
+		m := buildModel(ing, f.services)
+		warnPassthroughHosts(&m, passthrough)
+		f.warnings = slices.Clone(m.warnings)
+
+		f.add(m)
+
+		conversions = append(conversions, Conversion{
+			Namespace:   m.namespace,
+			Name:        m.name,
+			Unconverted: m.unconverted,
+			Warnings:    f.warnings,
+		})
+	}
+
+	slices.SortFunc(f.tls.Certificates, func(a, b fileCertificate) int {
+		return cmp.Compare(a.CertFile, b.CertFile)
+	})
+	f.tls.Certificates = slices.Compact(f.tls.Certificates)
+
+	if len(f.tls.Certificates) > 0 || len(f.tls.Options) > 0 {
+		f.config.TLS = f.tls
+	}
+	if len(f.tcp.Routers) > 0 {
+		f.config.TCP = f.tcp
+	}
+
+	return f.config, conversions
+}
+
+// fileBuilder adds the models of the Ingresses to the file provider configuration.
+type fileBuilder struct {
+	services   map[string]*corev1.Service
+	endpoints  map[string][]*discoveryv1.EndpointSlice
+	secretsDir string
+
+	config FileConfiguration
+	tls    *fileTLSConfiguration
+	tcp    *fileTCPConfiguration
+
+	// warnings of the Ingress being added.
+	warnings []string
+}
+
+func fileName(m ingressModel, parts ...string) string {
+	return strings.Join(append([]string{m.namespace, m.name}, parts...), nameSeparator)
+}
+
+// secretFile returns the path of the file of the Secret key, warning that the
+// Secret must be exported there.
+func (f *fileBuilder) secretFile(namespace, name, key string) string {
+	dir := filepath.Join(f.secretsDir, namespace, name)
+
+	warning := fmt.Sprintf("the Secret %s/%s must be exported to %s", namespace, name, dir)
+	if !slices.Contains(f.warnings, warning) {
+		f.warnings = append(f.warnings, warning)
+	}
+
+	return filepath.Join(dir, key)
+}
+
+func (f *fileBuilder) add(m ingressModel) {
+	var transportName string
+	if t := m.transport; t != nil {
+		transport := fileServersTransport{
+			ServerName:         t.serverName,
+			InsecureSkipVerify: t.insecureSkipVerify,
+		}
+		if t.rootCAsSecret != "" {
+			transport.RootCAs = []string{f.secretFile(m.namespace, t.rootCAsSecret, "ca.crt")}
+		}
+		if t.certificatesSecret != "" {
+			transport.Certificates = []fileCertificate{{
+				CertFile: f.secretFile(m.namespace, t.certificatesSecret, "tls.crt"),
+				KeyFile:  f.secretFile(m.namespace, t.certificatesSecret, "tls.key"),
+			}}
+		}
+		if t.timeouts != (forwardingTimeouts{}) {
+			transport.ForwardingTimeouts = &t.timeouts
+		}
+
+		transportName = fileName(m, "transport")
+		f.config.HTTP.ServersTransports[transportName] = transport
+	}
+
+	// service returns the name of the load balancer of the Service port, adding it once.
+	service := func(backend netv1.IngressServiceBackend) string {
+		port := cmp.Or(backend.Port.Name, strconv.Itoa(int(backend.Port.Number)))
+		name := fileName(m, "svc", backend.Name, port)
+
+		if _, ok := f.config.HTTP.Services[name]; !ok {
+			f.config.HTTP.Services[name] = fileService{LoadBalancer: fileLoadBalancer{
+				Servers:          f.servers(m, backend, cmp.Or(m.scheme, "http")),
+				Sticky:           m.sticky,
+				ServersTransport: transportName,
+			}}
+		}
+
+		return name
+	}
+
+	// middlewareName returns the name of the middleware, adding it once.
+	middlewareName := func(mw namedMiddleware) string {
+		name := fileName(m, mw.purpose)
+		if _, ok := f.config.HTTP.Middlewares[name]; !ok {
+			f.config.HTTP.Middlewares[name] = f.fileMiddleware(m, mw.spec)
+		}
+		return name
+	}
+
+	var tlsOptions string
+	if ca := m.clientAuth; ca != nil {
+		tlsOptions = fileName(m, "tls-options")
+		f.tls.Options[tlsOptions] = fileTLSOption{ClientAuth: fileClientAuth{
+			CAFiles:        []string{f.secretFile(m.namespace, ca.caSecret, "ca.crt")},
+			ClientAuthType: ca.clientAuthType,
+		}}
+	}
+
+	redirect := namedMiddleware{purpose: "redirect-scheme", spec: middleware{RedirectScheme: &redirectScheme{Scheme: "https", Permanent: true}}}
+
+	// The TLS connections of the passed through hosts are routed by SNI, the
+	// certificates of the Ingress are not served.
+	for i, r := range m.passthrough {
+		port := cmp.Or(r.backend.Port.Name, strconv.Itoa(int(r.backend.Port.Number)))
+		name := fileName(m, "tcp", "svc", r.backend.Name, port)
+
+		if _, ok := f.tcp.Services[name]; !ok {
+			var servers []fileTCPServer
+			for _, address := range f.addresses(m, r.backend) {
+				servers = append(servers, fileTCPServer{Address: address})
+			}
+			f.tcp.Services[name] = fileTCPService{LoadBalancer: fileTCPLoadBalancer{Servers: servers}}
+		}
+
+		f.tcp.Routers[fileName(m, "passthrough", strconv.Itoa(i))] = fileTCPRouter{
+			EntryPoints: []string{entryPointWebSecure},
+			Rule:        "HostSNI(`" + r.host + "`)",
+			Service:     name,
+			TLS:         fileTCPRouterTLS{Passthrough: true},
+		}
+	}
+	passthrough := len(m.passthrough) > 0
+
+	// The certificates are selected by SNI among all the certificates of the
+	// configuration, the HTTPS routers do not reference them.
+	for _, tls := range m.tls {
+		if tls.SecretName != "" && !passthrough {
+			f.tls.Certificates = append(f.tls.Certificates, fileCertificate{
+				CertFile: f.secretFile(m.namespace, tls.SecretName, "tls.crt"),
+				KeyFile:  f.secretFile(m.namespace, tls.SecretName, "tls.key"),
+			})
+		}
+	}
+
+	for i, r := range m.routes {
+		var middlewares []string
+		for _, mw := range slices.Concat(m.middlewares, r.middlewares) {
+			middlewares = append(middlewares, middlewareName(mw))
+		}
+
+		router := fileRouter{
+			EntryPoints: []string{entryPointWeb},
+			Rule:        r.match,
+			Priority:    r.priority,
+			Middlewares: middlewares,
+			Service:     service(r.backend),
+		}
+
+		if len(m.tls) > 0 && !passthrough {
+			httpsRouter := router
+			httpsRouter.EntryPoints = []string{entryPointWebSecure}
+			httpsRouter.TLS = &fileRouterTLS{Options: tlsOptions}
+			f.config.HTTP.Routers[fileName(m, "https", strconv.Itoa(i))] = httpsRouter
+		}
+
+		if r.redirectHTTPS {
+			router.Middlewares = []string{middlewareName(redirect)}
+		}
+		f.config.HTTP.Routers[fileName(m, "http", strconv.Itoa(i))] = router
+	}
+}
+
+// fileMiddleware adapts the middleware references to Secrets and Services to the file provider.
+func (f *fileBuilder) fileMiddleware(m ingressModel, spec middleware) middleware {
+	// The NGINX htpasswd Secrets hold the users in their auth key.
+	usersFile := func(auth *basicAuth) *basicAuth {
+		file := f.secretFile(m.namespace, auth.Secret, "auth")
+		if m.authMap {
+			f.warnings = append(f.warnings, fmt.Sprintf("the Secret %s/%s maps users to password hashes, they must be exported as htpasswd lines to %s", m.namespace, auth.Secret, file))
+		}
+		return &basicAuth{UsersFile: file, Realm: auth.Realm}
+	}
+	if spec.BasicAuth != nil {
+		spec.BasicAuth = usersFile(spec.BasicAuth)
+	}
+	if spec.DigestAuth != nil {
+		spec.DigestAuth = usersFile(spec.DigestAuth)
+	}
+
+	// NGINX requests the error pages over HTTP, without the backend settings of the Ingress.
+	if errors := spec.Errors; errors != nil {
+		ref := errors.Service.(serviceRef)
+		backend := netv1.IngressServiceBackend{Name: ref.Name, Port: netv1.ServiceBackendPort{Number: ref.Port}}
+
+		name := fileName(m, "errors", "svc")
+		f.config.HTTP.Services[name] = fileService{LoadBalancer: fileLoadBalancer{
+			Servers: f.servers(m, backend, "http"),
+		}}
+
+		spec.Errors = &errorPage{Status: errors.Status, Service: name, Query: errors.Query}
+	}
+
+	return spec
+}
+
+// servers resolves the servers of the Service port.
+func (f *fileBuilder) servers(m ingressModel, backend netv1.IngressServiceBackend, scheme string) []fileServer {
+	var servers []fileServer
+	for _, address := range f.addresses(m, backend) {
+		servers = append(servers, fileServer{URL: scheme + "://" + address})
+	}
+	return servers
+}
+
+// addresses resolves the addresses of the Service port, as "host:port".
+func (f *fileBuilder) addresses(m ingressModel, backend netv1.IngressServiceBackend) []string {
+	key := m.namespace + "/" + backend.Name
+
+	svc, ok := f.services[key]
+	if !ok {
+		// The number of a named port is only known from the Service.
+		if backend.Port.Name != "" {
+			f.warnings = append(f.warnings, fmt.Sprintf("the Service %s is not found, its port %s cannot be resolved", key, backend.Port.Name))
+			return nil
+		}
+
+		f.warnings = append(f.warnings, fmt.Sprintf("the Service %s is not found, its cluster DNS name is used", key))
+		return []string{fmt.Sprintf("%s.%s.svc:%d", backend.Name, m.namespace, backend.Port.Number)}
+	}
+
+	index := slices.IndexFunc(svc.Spec.Ports, func(port corev1.ServicePort) bool {
+		if backend.Port.Name != "" {
+			return port.Name == backend.Port.Name
+		}
+		return port.Port == backend.Port.Number
+	})
+	if index < 0 {
+		f.warnings = append(f.warnings, fmt.Sprintf("the Service %s has no port %s", key, cmp.Or(backend.Port.Name, strconv.Itoa(int(backend.Port.Number)))))
+		return nil
+	}
+	svcPort := svc.Spec.Ports[index]
+
+	address := func(host string, port int32) string {
+		return net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+
+	switch {
+	case svc.Spec.Type == corev1.ServiceTypeExternalName:
+		return []string{address(svc.Spec.ExternalName, svcPort.Port)}
+	case m.nativeLB && svc.Spec.ClusterIP != "" && svc.Spec.ClusterIP != corev1.ClusterIPNone:
+		return []string{address(svc.Spec.ClusterIP, svcPort.Port)}
+	}
+
+	var addresses []string
+	for _, slice := range f.endpoints[key] {
+		for _, port := range slice.Ports {
+			if ptr.Deref(port.Name, "") != svcPort.Name || port.Port == nil {
+				continue
+			}
+
+			for _, endpoint := range slice.Endpoints {
+				if !ptr.Deref(endpoint.Conditions.Ready, true) {
+					continue
+				}
+				for _, ip := range endpoint.Addresses {
+					addresses = append(addresses, address(ip, *port.Port))
+				}
+			}
+		}
+	}
+
+	if len(addresses) == 0 {
+		f.warnings = append(f.warnings, fmt.Sprintf("the Service %s has no ready endpoints, its cluster DNS name is used", key))
+		return []string{address(backend.Name+"."+m.namespace+".svc", svcPort.Port)}
+	}
+
+	slices.Sort(addresses)
+	return slices.Compact(addresses)
+}
+
+func indexEndpointSlices(endpointSlices []*discoveryv1.EndpointSlice) map[string][]*discoveryv1.EndpointSlice {
+	index := make(map[string][]*discoveryv1.EndpointSlice)
+	for _, slice := range endpointSlices {
+		if service, ok := slice.Labels[discoveryv1.LabelServiceName]; ok {
+			index[slice.Namespace+"/"+service] = append(index[slice.Namespace+"/"+service], slice)
+		}
+	}
+	return index
+}
@@ -0,0 +1,169 @@
+package convert
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// testFileInput completes the test input with the Services and endpoints of the shop/web Ingress.
+func testFileInput() Input {
+	input := testInput()
+
+	input.Services = append(input.Services,
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.43.0.10", Ports: []corev1.ServicePort{{Name: "http", Port: 80}}},
+		},
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "static"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.43.0.11", Ports: []corev1.ServicePort{{Port: 8080}}},
+		},
+	)
+
+	input.EndpointSlices = []*discoveryv1.EndpointSlice{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "shop",
+				Name:      "web-abcde",
+				Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+			},
+			Ports: []discoveryv1.EndpointPort{{Name: ptr.To("http"), Port: ptr.To[int32](8000)}},
+			Endpoints: []discoveryv1.Endpoint{
+				{Addresses: []string{"10.42.0.5"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)}},
+				{Addresses: []string{"10.42.0.4"}},
+				{Addresses: []string{"10.42.0.6"}, Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(false)}},
+			},
+		},
+	}
+
+	return input
+}
+
+func TestTraefikFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format string
+		golden string
+	}{
+		{format: FileFormatYAML, golden: "traefik-file.yaml"},
+		{format: FileFormatTOML, golden: "traefik-file.toml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			t.Parallel()
+
+			config, conversions := TraefikFile(testFileInput(), "/secrets")
+			require.Len(t, conversions, 2)
+
+			var buf bytes.Buffer
+			require.NoError(t, WriteFileConfiguration(&buf, config, conversions, tt.format))
+
+			testutil.AssertGolden(t, tt.golden, buf.Bytes())
+		})
+	}
+}
+
+func TestTraefikFile_ServerURLs(t *testing.T) {
+	t.Parallel()
+
+	config, conversions := TraefikFile(testFileInput(), "/secrets")
+	require.Len(t, conversions, 2)
+
+	// Only the ready endpoints are servers, with the target port of the EndpointSlice.
+	assert.Equal(t, []fileServer{{URL: "http://10.42.0.4:8000"}, {URL: "http://10.42.0.5:8000"}},
+		config.HTTP.Services["shop_web_svc_web_80"].LoadBalancer.Servers)
+
+	// Without endpoints, the cluster DNS name of the Service is used.
+	assert.Equal(t, []fileServer{{URL: "http://static.shop.svc:8080"}},
+		config.HTTP.Services["shop_web_svc_static_8080"].LoadBalancer.Servers)
+	assert.Contains(t, conversions[1].Warnings, "the Service shop/static has no ready endpoints, its cluster DNS name is used")
+}
+
+func TestTraefikFile_MissingServiceNamedPort(t *testing.T) {
+	t.Parallel()
+
+	backend := testutil.Path("/", netv1.PathTypePrefix, "missing", 0)
+	backend.Backend.Service.Port = netv1.ServiceBackendPort{Name: "http"}
+
+	config, conversions := TraefikFile(Input{Ingresses: []*netv1.Ingress{
+		testutil.Ingress("shop", "web", nil, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("shop.example.com", backend)}}),
+	}}, "/secrets")
+	require.Len(t, conversions, 1)
+
+	// The number of the named port is unknown without the Service.
+	require.NotEmpty(t, config.HTTP.Services)
+	for name, service := range config.HTTP.Services {
+		assert.Empty(t, service.LoadBalancer.Servers, name)
+	}
+	assert.Contains(t, conversions[0].Warnings, "the Service shop/missing is not found, its port http cannot be resolved")
+}
+
+func TestTraefikFile_Passthrough(t *testing.T) {
+	t.Parallel()
+
+	config, conversions := TraefikFile(passthroughInput(), "/secrets")
+	require.Len(t, conversions, 2)
+
+	require.NotNil(t, config.TCP)
+	assert.Equal(t, map[string]fileTCPRouter{
+		"vault_server_passthrough_0": {
+			EntryPoints: []string{entryPointWebSecure},
+			Rule:        "HostSNI(`vault.example.com`)",
+			Service:     "vault_server_tcp_svc_vault_8200",
+			TLS:         fileTCPRouterTLS{Passthrough: true},
+		},
+	}, config.TCP.Routers)
+	assert.Equal(t, []fileTCPServer{{Address: "vault.vault.svc:8200"}},
+		config.TCP.Services["vault_server_tcp_svc_vault_8200"].LoadBalancer.Servers)
+
+	// The passed through Ingress has no HTTPS routers, the other Ingress of the host keeps them.
+	assert.NotContains(t, config.HTTP.Routers, "vault_server_https_0")
+	assert.Contains(t, config.HTTP.Routers, "vault_metrics_https_0")
+}
+
+func TestMarshalTOML(t *testing.T) {
+	t.Parallel()
+
+	value := map[string]any{
+		"http": map[string]any{
+			"routers": map[string]any{
+				"a_b": map[string]any{"rule": "Host(`a.example.com`) && PathRegexp(`^/\\d+`)", "priority": 10},
+			},
+			"services": map[string]any{
+				"svc": map[string]any{"loadBalancer": map[string]any{
+					"servers": []any{map[string]any{"url": "http://10.0.0.1"}, map[string]any{"url": "http://10.0.0.2"}},
+				}},
+			},
+		},
+		"tls": map[string]any{"options": map[string]any{"my.option": map[string]any{"caFiles": []string{"/ca.crt"}, "skip": true}}},
+	}
+
+	data, err := marshalTOML(value)
+	require.NoError(t, err)
+
+	assert.Equal(t, `[http.routers.a_b]
+priority = 10
+rule = "Host(`+"`a.example.com`"+`) && PathRegexp(`+"`^/\\\\d+`"+`)"
+
+[[http.services.svc.loadBalancer.servers]]
+url = "http://10.0.0.1"
+
+[[http.services.svc.loadBalancer.servers]]
+url = "http://10.0.0.2"
+
+[tls.options."my.option"]
+caFiles = ["/ca.crt"]
+skip = true
+`, string(data))
+}
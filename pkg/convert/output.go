@@ -0,0 +1,92 @@
+package convert
+
+import (
+	"fmt"
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+// WriteManifests writes the objects of the conversions as a multi-document YAML
+// stream. Each Ingress is introduced by comments listing its unconverted
+// annotations and the behavior differences of the conversion.
+func WriteManifests(w io.Writer, conversions []Conversion) error {
+	for _, conversion := range conversions {
+		if err := writeComments(w, conversion); err != nil {
+			return fmt.Errorf("writing manifests: %w", err)
+		}
+
+		for _, object := range conversion.Objects {
+			data, err := yaml.Marshal(object)
+			if err != nil {
+				return fmt.Errorf("marshaling %s %s/%s: %w", object.Kind, object.Metadata.Namespace, object.Metadata.Name, err)
+			}
+
+			if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+				return fmt.Errorf("writing manifests: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteFileConfiguration writes the file provider configuration in the format,
+// introduced by the comments of each Ingress conversion.
+func WriteFileConfiguration(w io.Writer, config FileConfiguration, conversions []Conversion, format string) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case FileFormatYAML:
+		data, err = yaml.Marshal(config)
+	case FileFormatTOML:
+		data, err = marshalTOML(config)
+	default:
+		return fmt.Errorf("unsupported file format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("marshaling file configuration: %w", err)
+	}
+
+	for _, conversion := range conversions {
+		if err := writeComments(w, conversion); err != nil {
+			return fmt.Errorf("writing file configuration: %w", err)
+		}
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing file configuration: %w", err)
+	}
+
+	return nil
+}
+
+// writeComments writes the Ingress of the conversion, its unconverted annotations
+// and its warnings as comments, valid in both YAML and TOML.
+func writeComments(w io.Writer, conversion Conversion) error {
+	if _, err := fmt.Fprintf(w, "# Ingress %s/%s\n", conversion.Namespace, conversion.Name); err != nil {
+		return err
+	}
+
+	for _, unconverted := range conversion.Unconverted {
+		var err error
+		if unconverted.Line > 0 {
+			_, err = fmt.Fprintf(w, "# Unconverted %s line %d `%s`: %s\n", unconverted.Annotation, unconverted.Line, unconverted.Directive, unconverted.Reason)
+		} else {
+			_, err = fmt.Fprintf(w, "# Unconverted %s: %s\n", unconverted.Annotation, unconverted.Reason)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range conversion.Warnings {
+		if _, err := fmt.Fprintf(w, "# Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,636 @@
+package convert
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// snippetDirective is a directive of an NGINX configuration snippet.
+type snippetDirective struct {
+	// line is the line of the directive in the snippet, starting at 1.
+	line int
+	// args are the directive name followed by its arguments, unquoted.
+	args []string
+	// text is the directive as written, blocks being abbreviated.
+	text  string
+	block bool
+}
+
+func (d snippetDirective) name() string {
+	return d.args[0]
+}
+
+// parseSnippet parses the directives of an NGINX configuration snippet.
+// The blocks, such as location or if, are parsed as a single directive.
+func parseSnippet(snippet string) ([]snippetDirective, error) {
+	var (
+		directives []snippetDirective
+		current    snippetDirective
+		start      int
+		depth      int
+	)
+
+	line := 1
+	for i := 0; i < len(snippet); {
+		c := snippet[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case c == '#':
+			for i < len(snippet) && snippet[i] != '\n' {
+				i++
+			}
+
+		case c == ';':
+			if depth == 0 {
+				if len(current.args) == 0 {
+					return nil, fmt.Errorf("line %d: unexpected \";\"", line)
+				}
+				current.text = collapseSpaces(snippet[start : i+1])
+				directives = append(directives, current)
+				current = snippetDirective{}
+			}
+			i++
+
+		case c == '{':
+			if depth == 0 {
+				if len(current.args) == 0 {
+					return nil, fmt.Errorf("line %d: unexpected \"{\"", line)
+				}
+				current.text = collapseSpaces(snippet[start:i]) + " { … }"
+				current.block = true
+			}
+			depth++
+			i++
+
+		case c == '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unexpected \"}\"", line)
+			}
+			depth--
+			if depth == 0 {
+				directives = append(directives, current)
+				current = snippetDirective{}
+			}
+			i++
+
+		default:
+			tokenStart := i
+
+			token, next, err := readToken(snippet, i)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			line += strings.Count(snippet[i:next], "\n")
+			i = next
+
+			// The content of the blocks is not kept.
+			if depth > 0 {
+				continue
+			}
+			if len(current.args) == 0 {
+				current.line = line
+				start = tokenStart
+			}
+			current.args = append(current.args, token)
+		}
+	}
+
+	switch {
+	case depth > 0:
+		return nil, fmt.Errorf("line %d: unclosed block", current.line)
+	case len(current.args) > 0:
+		return nil, fmt.Errorf("line %d: missing \";\"", current.line)
+	}
+
+	return directives, nil
+}
+
+// readToken reads the quoted or bare token starting at i,
+// and returns it unquoted with the index following it.
+func readToken(snippet string, i int) (string, int, error) {
+	if quote := snippet[i]; quote == '"' || quote == '\'' {
+		var token strings.Builder
+		for i++; i < len(snippet); i++ {
+			switch c := snippet[i]; {
+			case c == quote:
+				return token.String(), i + 1, nil
+			case c == '\\' && i+1 < len(snippet) && (snippet[i+1] == quote || snippet[i+1] == '\\'):
+				i++
+				token.WriteByte(snippet[i])
+			default:
+				token.WriteByte(c)
+			}
+		}
+		return "", 0, errors.New("unterminated quoted string")
+	}
+
+	start := i
+	for i < len(snippet) {
+		switch c := snippet[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == ';' || c == '{' || c == '}':
+			return snippet[start:i], i, nil
+		case c == '$' && i+1 < len(snippet) && snippet[i+1] == '{':
+			// ${variable} is part of the token.
+			end := strings.IndexByte(snippet[i:], '}')
+			if end < 0 {
+				return "", 0, errors.New("unterminated variable")
+			}
+			i += end + 1
+		default:
+			i++
+		}
+	}
+	return snippet[start:i], i, nil
+}
+
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// forwardedHeaders are the request headers set by proxy_set_header which Traefik
+// already sends to the backends with the same value.
+var forwardedHeaders = map[string][]string{
+	"host":              {"$host", "$http_host", "$best_http_host"},
+	"x-real-ip":         {"$remote_addr"},
+	"x-forwarded-for":   {"$proxy_add_x_forwarded_for", "$remote_addr"},
+	"x-forwarded-proto": {"$scheme", "$pass_access_scheme"},
+	"x-forwarded-host":  {"$host", "$http_host", "$best_http_host"},
+}
+
+// redirectURL matches the request URL of a RedirectRegex middleware, capturing
+// the parts the NGINX variables of the return directive refer to.
+const redirectURL = `^(https?)://([^/:]+)(:[0-9]+)?(([^?]*).*)$`
+
+// redirectVariables are the replacements of the NGINX variables in a return URL.
+var redirectVariables = map[string]string{
+	"scheme":      "${1}",
+	"host":        "${2}",
+	"http_host":   "${2}${3}",
+	"request_uri": "${4}",
+	"uri":         "${5}",
+}
+
+// nginxVariable matches the NGINX variables, as $name or ${name}.
+var nginxVariable = regexp.MustCompile(`\$(?:\{(\w+)\}|([a-zA-Z_]\w*))`)
+
+// snippetTranslator translates the directives of a snippet annotation to middlewares.
+type snippetTranslator struct {
+	b          *builder
+	annotation string
+	// server is whether the snippet is inserted in the NGINX server block,
+	// rather than in the location blocks of the Ingress paths.
+	server bool
+
+	middlewares     []namedMiddleware
+	requestHeaders  map[string]string
+	responseHeaders map[string]string
+	access          []snippetDirective
+
+	// returned is the line of the return directive ending the rewrite phase.
+	returned int
+}
+
+// snippets translates the directives of the snippets to middlewares, and reports
+// those without equivalent line by line.
+func (b *builder) snippets() {
+	for _, annotation := range []string{"server-snippet", "configuration-snippet"} {
+		snippet, ok := b.get(annotation)
+		if !ok {
+			continue
+		}
+
+		directives, err := parseSnippet(snippet)
+		if err != nil {
+			b.unconverted(annotation, fmt.Sprintf("invalid snippet: %v", err))
+			continue
+		}
+
+		t := &snippetTranslator{
+			b:               b,
+			annotation:      annotation,
+			server:          annotation == "server-snippet",
+			requestHeaders:  make(map[string]string),
+			responseHeaders: make(map[string]string),
+		}
+		for _, d := range directives {
+			t.translate(d)
+		}
+		t.translateAccess()
+
+		if len(t.requestHeaders) > 0 || len(t.responseHeaders) > 0 {
+			h := &headers{}
+			if len(t.requestHeaders) > 0 {
+				h.CustomRequestHeaders = t.requestHeaders
+			}
+			if len(t.responseHeaders) > 0 {
+				h.CustomResponseHeaders = t.responseHeaders
+			}
+			t.addMiddleware("headers", middleware{Headers: h})
+		}
+
+		if t.server && len(t.middlewares) > 0 {
+			b.warnf("%s%s applies to every Ingress of the hosts, the middlewares translated from it only to the routes of this Ingress", annotationPrefix, annotation)
+		}
+		b.model.middlewares = append(b.model.middlewares, t.middlewares...)
+	}
+}
+
+func (t *snippetTranslator) addMiddleware(purpose string, spec middleware) {
+	// The middlewares of both snippets are named after their annotation.
+	purpose = t.annotation + "-" + purpose
+
+	if slices.ContainsFunc(t.middlewares, func(mw namedMiddleware) bool { return mw.purpose == purpose }) {
+		purpose += "-" + strconv.Itoa(len(t.middlewares))
+	}
+	t.middlewares = append(t.middlewares, namedMiddleware{purpose: purpose, spec: spec})
+}
+
+func (t *snippetTranslator) unconverted(d snippetDirective, reason string) {
+	t.b.model.unconverted = append(t.b.model.unconverted, Unconverted{
+		Annotation: annotationPrefix + t.annotation,
+		Line:       d.line,
+		Directive:  d.text,
+		Reason:     reason,
+	})
+}
+
+func (t *snippetTranslator) warnf(d snippetDirective, format string, args ...any) {
+	t.b.warnf("%s%s line %d: %s", annotationPrefix, t.annotation, d.line, fmt.Sprintf(format, args...))
+}
+
+func (t *snippetTranslator) translate(d snippetDirective) {
+	if d.block {
+		t.unconverted(d, fmt.Sprintf("%s blocks are not translated, their conditions have no middleware equivalent", d.name()))
+		return
+	}
+
+	switch d.name() {
+	case "add_header":
+		t.addHeader(d)
+	case "more_set_headers", "more_clear_headers":
+		t.moreHeaders(d, t.responseHeaders)
+	case "more_set_input_headers", "more_clear_input_headers":
+		t.moreHeaders(d, t.requestHeaders)
+	case "proxy_set_header":
+		t.proxySetHeader(d)
+	case "return":
+		t.returnDirective(d)
+	case "rewrite":
+		t.rewrite(d)
+	case "allow", "deny":
+		t.access = append(t.access, d)
+	default:
+		t.unconverted(d, fmt.Sprintf("the %s directive has no Traefik equivalent", d.name()))
+	}
+}
+
+func (t *snippetTranslator) addHeader(d snippetDirective) {
+	args := d.args[1:]
+
+	always := len(args) == 3 && args[2] == "always"
+	if len(args) != 2 && !always {
+		t.unconverted(d, "invalid arguments")
+		return
+	}
+	if nginxVariable.MatchString(args[1]) {
+		t.unconverted(d, "NGINX variables in header values have no equivalent")
+		return
+	}
+
+	t.responseHeaders[args[0]] = args[1]
+	if !always {
+		t.warnf(d, "the %s header is added to every response, NGINX adds it to the 2xx and 3xx responses only", args[0])
+	}
+}
+
+// moreHeaders translates the directives of the headers-more module,
+// which set headers or clear them with an empty value, as Traefik does.
+func (t *snippetTranslator) moreHeaders(d snippetDirective, headers map[string]string) {
+	clearing := strings.HasPrefix(d.name(), "more_clear_")
+
+	var values []string
+	for _, arg := range d.args[1:] {
+		switch arg {
+		case "-s", "-t":
+			t.unconverted(d, "filtering on the status or content type has no equivalent")
+			return
+		case "-a":
+			t.unconverted(d, "Traefik replaces the headers and cannot append to them")
+			return
+		case "-r":
+			t.unconverted(d, "Traefik cannot set a header only when the request already has it")
+			return
+		}
+		values = append(values, arg)
+	}
+	if len(values) == 0 {
+		t.unconverted(d, "invalid arguments")
+		return
+	}
+
+	translated := make(map[string]string, len(values))
+	for _, value := range values {
+		name, value, _ := strings.Cut(value, ":")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+
+		switch {
+		case clearing && strings.Contains(name, "*"):
+			t.unconverted(d, "clearing headers by wildcard has no equivalent")
+			return
+		case clearing:
+			value = ""
+		case nginxVariable.MatchString(value):
+			t.unconverted(d, "NGINX variables in header values have no equivalent")
+			return
+		}
+		translated[name] = value
+	}
+
+	for name, value := range translated {
+		headers[name] = value
+	}
+}
+
+func (t *snippetTranslator) proxySetHeader(d snippetDirective) {
+	if len(d.args) != 3 {
+		t.unconverted(d, "invalid arguments")
+		return
+	}
+	name, value := d.args[1], d.args[2]
+
+	if !nginxVariable.MatchString(value) {
+		// An empty value removes the header with both NGINX and Traefik.
+		t.requestHeaders[name] = value
+		return
+	}
+
+	if slices.Contains(forwardedHeaders[strings.ToLower(name)], value) {
+		// Traefik already forwards the header.
+		return
+	}
+
+	t.unconverted(d, "NGINX variables in header values have no equivalent")
+}
+
+func (t *snippetTranslator) returnDirective(d snippetDirective) {
+	if t.returned > 0 {
+		t.unconverted(d, fmt.Sprintf("unreachable after the return directive on line %d", t.returned))
+		return
+	}
+	t.returned = d.line
+
+	var code, target string
+	switch args := d.args[1:]; len(args) {
+	case 1:
+		code = args[0]
+	case 2:
+		code, target = args[0], args[1]
+	default:
+		t.unconverted(d, "invalid arguments")
+		return
+	}
+
+	if target == "" || !slices.Contains([]string{"301", "302", "303", "307", "308"}, code) {
+		t.unconverted(d, "returning a response has no middleware equivalent, serve it from a backend")
+		return
+	}
+
+	var unknown string
+	replacement := nginxVariable.ReplaceAllStringFunc(target, func(variable string) string {
+		match := nginxVariable.FindStringSubmatch(variable)
+		name := match[1] + match[2]
+		if value, ok := redirectVariables[name]; ok {
+			return value
+		}
+		unknown = variable
+		return variable
+	})
+	if unknown != "" {
+		t.unconverted(d, fmt.Sprintf("the NGINX variable %s has no equivalent", unknown))
+		return
+	}
+	if strings.HasPrefix(replacement, "/") {
+		replacement = "${1}://${2}${3}" + replacement
+	}
+
+	permanent := code == "301" || code == "308"
+	t.addMiddleware("redirect", middleware{RedirectRegex: &redirectRegex{Regex: redirectURL, Replacement: replacement, Permanent: permanent}})
+
+	if code != "301" && code != "302" {
+		if permanent {
+			t.warnf(d, "the redirect answers 301 (308 for non-GET requests) instead of %s", code)
+		} else {
+			t.warnf(d, "the redirect answers 302 (307 for non-GET requests) instead of %s", code)
+		}
+	}
+}
+
+func (t *snippetTranslator) rewrite(d snippetDirective) {
+	if t.returned > 0 {
+		t.unconverted(d, fmt.Sprintf("unreachable after the return directive on line %d", t.returned))
+		return
+	}
+
+	args := d.args[1:]
+	if len(args) != 2 && len(args) != 3 {
+		t.unconverted(d, "invalid arguments")
+		return
+	}
+	pattern, replacement := args[0], args[1]
+
+	var flag string
+	if len(args) == 3 {
+		flag = args[2]
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		t.unconverted(d, fmt.Sprintf("the regex is not supported by Traefik: %v", err))
+		return
+	}
+
+	redirect := flag == "redirect" || flag == "permanent" ||
+		strings.HasPrefix(replacement, "http://") || strings.HasPrefix(replacement, "https://") || strings.HasPrefix(replacement, "$scheme://")
+
+	// Only the captures, and the scheme of the redirects, are translated.
+	variables := strings.TrimPrefix(replacement, "$scheme://")
+	if match := nginxVariable.FindString(variables); match != "" {
+		t.unconverted(d, fmt.Sprintf("the NGINX variable %s has no equivalent", match))
+		return
+	}
+
+	switch {
+	case redirect:
+		t.rewriteRedirect(d, pattern, replacement, re.NumSubexp(), flag == "permanent")
+	case flag == "break" && t.server:
+		t.unconverted(d, "in the server block, the rewritten path selects the location, Traefik routers are not matched again")
+	case flag == "break":
+		t.rewritePath(d, pattern, replacement)
+	case flag == "last" || flag == "":
+		t.unconverted(d, "the rewritten path selects the location again, Traefik routers are not matched again")
+	default:
+		t.unconverted(d, fmt.Sprintf("unknown flag %q", flag))
+	}
+}
+
+// rewritePath replaces the path with a ReplacePathRegex middleware.
+// NGINX replaces the whole path with the replacement, not only the matched part.
+func (t *snippetTranslator) rewritePath(d snippetDirective, pattern, replacement string) {
+	if strings.Contains(replacement, "?") {
+		t.unconverted(d, "the ReplacePathRegex middleware does not change the query arguments")
+		return
+	}
+
+	regex := "^(?:" + pattern + ").*"
+	if !strings.HasPrefix(pattern, "^") {
+		regex = "^.*?(?:" + pattern + ").*"
+	}
+
+	t.addMiddleware("rewrite", middleware{ReplacePathRegex: &replacePathRegex{
+		Regex:       regex,
+		Replacement: nginxCapture.ReplaceAllString(replacement, "$${$1}"),
+	}})
+}
+
+// rewriteRedirect redirects with a RedirectRegex middleware.
+func (t *snippetTranslator) rewriteRedirect(d snippetDirective, pattern, replacement string, captures int, permanent bool) {
+	redirect, err := redirectRegexFor(pattern, replacement, captures, permanent)
+	if err != nil {
+		t.unconverted(d, err.Error())
+		return
+	}
+
+	t.addMiddleware("redirect", middleware{RedirectRegex: redirect})
+}
+
+// redirectRegexFor returns the RedirectRegex of an NGINX redirection, which matches
+// the whole URL rather than the path. The captures of the pattern follow the one of
+// the origin, and the query arguments are kept as NGINX does.
+func redirectRegexFor(pattern, replacement string, captures int, permanent bool) (*redirectRegex, error) {
+	// The scheme and the host are the first captures.
+	const origin = 2
+
+	path := excludeQuery(strings.TrimPrefix(pattern, "^"))
+	if !strings.HasPrefix(pattern, "^") {
+		path = "[^?]*?" + path
+	}
+
+	// The end of the path is followed by the query arguments.
+	rest := "[^?]*"
+	if trimmed, ok := strings.CutSuffix(path, "$"); ok && !strings.HasSuffix(trimmed, `\`) {
+		path, rest = trimmed, ""
+	}
+
+	query := "${" + strconv.Itoa(origin+captures+1) + "}"
+	switch {
+	case strings.HasSuffix(replacement, "?"):
+		// A trailing ? drops the query arguments.
+		replacement, query = strings.TrimSuffix(replacement, "?"), ""
+	case strings.Contains(replacement, "?"):
+		return nil, errors.New("the query arguments of the replacement cannot be merged with those of the request")
+	}
+
+	replacement = nginxCapture.ReplaceAllStringFunc(replacement, func(capture string) string {
+		n, _ := strconv.Atoi(capture[1:])
+		if n > captures {
+			// NGINX replaces the missing captures with nothing.
+			return ""
+		}
+		return "${" + strconv.Itoa(n+origin) + "}"
+	})
+	if rest, ok := strings.CutPrefix(replacement, "$scheme://"); ok {
+		replacement = "${1}://" + rest
+	}
+	if strings.HasPrefix(replacement, "/") {
+		replacement = "${1}://${2}" + replacement
+	}
+
+	return &redirectRegex{
+		Regex:       `^(https?)://([^/]+)(?:` + path + ")" + rest + `(\?.*)?$`,
+		Replacement: replacement + query,
+		Permanent:   permanent,
+	}, nil
+}
+
+// excludeQuery makes the wildcards and the negated classes of the path pattern
+// exclude the ?, for the matches to stop before the query arguments.
+func excludeQuery(pattern string) string {
+	var b strings.Builder
+
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(pattern[i : i+2])
+			i++
+			continue
+		case inClass && c == ']':
+			inClass = false
+		case !inClass && c == '[':
+			inClass = true
+			if strings.HasPrefix(pattern[i:], "[^") {
+				b.WriteString("[^?")
+				i++
+				continue
+			}
+		case !inClass && c == '.':
+			b.WriteString("[^?]")
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// translateAccess translates the allow and deny directives to an IPAllowList middleware,
+// when they allow addresses and deny all the others.
+func (t *snippetTranslator) translateAccess() {
+	if len(t.access) == 0 {
+		return
+	}
+
+	last := t.access[len(t.access)-1]
+	if len(t.access) == 1 && slices.Equal(last.args, []string{"allow", "all"}) {
+		return
+	}
+
+	var sourceRange []string
+	for _, d := range t.access[:len(t.access)-1] {
+		if d.name() != "allow" || len(d.args) != 2 || !isAddressRange(d.args[1]) {
+			sourceRange = nil
+			break
+		}
+		sourceRange = append(sourceRange, d.args[1])
+	}
+
+	if len(sourceRange) == 0 || !slices.Equal(last.args, []string{"deny", "all"}) {
+		for _, d := range t.access {
+			t.unconverted(d, "the IPAllowList middleware only translates allow directives followed by deny all")
+		}
+		return
+	}
+
+	t.addMiddleware("allowlist", middleware{IPAllowList: &ipAllowList{SourceRange: sourceRange}})
+}
+
+func isAddressRange(value string) bool {
+	if _, err := netip.ParsePrefix(value); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(value)
+	return err == nil
+}
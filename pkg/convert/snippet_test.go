@@ -0,0 +1,317 @@
+package convert
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func TestParseSnippet(t *testing.T) {
+	t.Parallel()
+
+	directives, err := parseSnippet(`# comment
+add_header X-Level "root value";
+location /loc-headers {
+    add_header X-Loc "a;b";
+    return 200 "with-headers";
+}
+
+more_set_headers   'X-Quoted: it\'s'
+    "X-Other: b";
+`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []snippetDirective{
+		{line: 2, args: []string{"add_header", "X-Level", "root value"}, text: `add_header X-Level "root value";`},
+		{line: 3, args: []string{"location", "/loc-headers"}, text: "location /loc-headers { … }", block: true},
+		{line: 8, args: []string{"more_set_headers", "X-Quoted: it's", "X-Other: b"}, text: `more_set_headers 'X-Quoted: it\'s' "X-Other: b";`},
+	}, directives)
+
+	for snippet, want := range map[string]string{
+		"add_header X-A a":           `line 1: missing ";"`,
+		"location / {\n return 200;": "line 1: unclosed block",
+		"}":                          `line 1: unexpected "}"`,
+		"\n;":                        `line 2: unexpected ";"`,
+		`add_header X-A "a;`:         "line 1: unterminated quoted string",
+	} {
+		_, err := parseSnippet(snippet)
+		assert.EqualError(t, err, want, snippet)
+	}
+}
+
+func TestSnippets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc        string
+		annotation  string
+		snippet     string
+		middlewares []namedMiddleware
+		unconverted []Unconverted
+		warnings    []string
+	}{
+		{
+			desc:       "headers",
+			annotation: "configuration-snippet",
+			snippet: `
+add_header X-Frame-Options "DENY" always;
+more_set_headers "X-Multi-A: a-val" "X-Multi-B: b-val";
+more_set_headers "X-NoColon-Clear";
+more_clear_headers "X-Powered-By" "Server";
+more_set_input_headers "X-Custom-Input:input-value";
+more_clear_input_headers "X-Secret";
+proxy_set_header X-Tenant acme;
+proxy_set_header X-Forwarded-For $proxy_add_x_forwarded_for;
+proxy_set_header Host $host;
+`,
+			middlewares: []namedMiddleware{{purpose: "configuration-snippet-headers", spec: middleware{Headers: &headers{
+				CustomRequestHeaders: map[string]string{"X-Custom-Input": "input-value", "X-Secret": "", "X-Tenant": "acme"},
+				CustomResponseHeaders: map[string]string{
+					"X-Frame-Options": "DENY",
+					"X-Multi-A":       "a-val",
+					"X-Multi-B":       "b-val",
+					"X-NoColon-Clear": "",
+					"X-Powered-By":    "",
+					"Server":          "",
+				},
+			}}}},
+		},
+		{
+			desc:       "untranslatable headers",
+			annotation: "configuration-snippet",
+			snippet: `
+add_header X-Method $request_method;
+more_set_headers -a "X-Append: second";
+more_set_input_headers -r "X-Restrict: restricted-value";
+more_set_headers -s 404 "X-Status: missing";
+more_clear_headers "X-Wild-*";
+proxy_set_header X-Backend-Uri $request_uri;
+add_header X-Level "location";
+`,
+			middlewares: []namedMiddleware{{purpose: "configuration-snippet-headers", spec: middleware{Headers: &headers{
+				CustomResponseHeaders: map[string]string{"X-Level": "location"},
+			}}}},
+			unconverted: []Unconverted{
+				{Line: 1, Directive: "add_header X-Method $request_method;", Reason: "NGINX variables in header values have no equivalent"},
+				{Line: 2, Directive: `more_set_headers -a "X-Append: second";`, Reason: "Traefik replaces the headers and cannot append to them"},
+				{Line: 3, Directive: `more_set_input_headers -r "X-Restrict: restricted-value";`, Reason: "Traefik cannot set a header only when the request already has it"},
+				{Line: 4, Directive: `more_set_headers -s 404 "X-Status: missing";`, Reason: "filtering on the status or content type has no equivalent"},
+				{Line: 5, Directive: `more_clear_headers "X-Wild-*";`, Reason: "clearing headers by wildcard has no equivalent"},
+				{Line: 6, Directive: "proxy_set_header X-Backend-Uri $request_uri;", Reason: "NGINX variables in header values have no equivalent"},
+			},
+			warnings: []string{"nginx.ingress.kubernetes.io/configuration-snippet line 7: the X-Level header is added to every response, NGINX adds it to the 2xx and 3xx responses only"},
+		},
+		{
+			desc:       "return redirect",
+			annotation: "configuration-snippet",
+			snippet: `return 301 https://www.example.com$request_uri;
+rewrite ^/old$ /new break;`,
+			middlewares: []namedMiddleware{{purpose: "configuration-snippet-redirect", spec: middleware{RedirectRegex: &redirectRegex{
+				Regex:       redirectURL,
+				Replacement: "https://www.example.com${4}",
+				Permanent:   true,
+			}}}},
+			unconverted: []Unconverted{
+				{Line: 2, Directive: "rewrite ^/old$ /new break;", Reason: "unreachable after the return directive on line 1"},
+			},
+		},
+		{
+			desc:       "relative return redirect",
+			annotation: "configuration-snippet",
+			snippet:    `return 307 /maintenance;`,
+			middlewares: []namedMiddleware{{purpose: "configuration-snippet-redirect", spec: middleware{RedirectRegex: &redirectRegex{
+				Regex:       redirectURL,
+				Replacement: "${1}://${2}${3}/maintenance",
+			}}}},
+			warnings: []string{"nginx.ingress.kubernetes.io/configuration-snippet line 1: the redirect answers 302 (307 for non-GET requests) instead of 307"},
+		},
+		{
+			desc:       "untranslatable returns",
+			annotation: "server-snippet",
+			snippet: `
+return 403 "Forbidden";
+`,
+			unconverted: []Unconverted{
+				{Line: 1, Directive: `return 403 "Forbidden";`, Reason: "returning a response has no middleware equivalent, serve it from a backend"},
+			},
+		},
+		{
+			desc:       "rewrites",
+			annotation: "configuration-snippet",
+			snippet: `
+rewrite ^/rw-break/(.*)$ /rw-dest/$1 break;
+rewrite /legacy/(\d+) /items/$1 break;
+rewrite ^/rw-cfg/(.*)$ /rw-cfg-dest/$1 last;
+rewrite ^/rw-noquery$ /rw-dest? break;
+rewrite ^/rw-var$ /$host break;
+rewrite ^/rw-permanent/(.*)$ /rw-perm-dest/$1 permanent;
+rewrite ^/rw-url-redir https://other.example.com/new?;
+`,
+			middlewares: []namedMiddleware{
+				{purpose: "configuration-snippet-rewrite", spec: middleware{ReplacePathRegex: &replacePathRegex{
+					Regex:       "^(?:^/rw-break/(.*)$).*",
+					Replacement: "/rw-dest/${1}",
+				}}},
+				{purpose: "configuration-snippet-rewrite-1", spec: middleware{ReplacePathRegex: &replacePathRegex{
+					Regex:       `^.*?(?:/legacy/(\d+)).*`,
+					Replacement: "/items/${1}",
+				}}},
+				{purpose: "configuration-snippet-redirect", spec: middleware{RedirectRegex: &redirectRegex{
+					Regex:       `^(https?)://([^/]+)(?:/rw-permanent/([^?]*))(\?.*)?$`,
+					Replacement: "${1}://${2}/rw-perm-dest/${3}${4}",
+					Permanent:   true,
+				}}},
+				{purpose: "configuration-snippet-redirect-3", spec: middleware{RedirectRegex: &redirectRegex{
+					Regex:       `^(https?)://([^/]+)(?:/rw-url-redir)[^?]*(\?.*)?$`,
+					Replacement: "https://other.example.com/new",
+				}}},
+			},
+			unconverted: []Unconverted{
+				{Line: 3, Directive: "rewrite ^/rw-cfg/(.*)$ /rw-cfg-dest/$1 last;", Reason: "the rewritten path selects the location again, Traefik routers are not matched again"},
+				{Line: 4, Directive: "rewrite ^/rw-noquery$ /rw-dest? break;", Reason: "the ReplacePathRegex middleware does not change the query arguments"},
+				{Line: 5, Directive: "rewrite ^/rw-var$ /$host break;", Reason: "the NGINX variable $host has no equivalent"},
+			},
+		},
+		{
+			desc:       "server rewrites",
+			annotation: "server-snippet",
+			snippet: `rewrite ^/rw-last/(.*)$ /rw-dest/$1 break;
+rewrite ^/rw-redirect$ $scheme://other.example.com/rw-redir-dest redirect;`,
+			middlewares: []namedMiddleware{
+				{purpose: "server-snippet-redirect", spec: middleware{RedirectRegex: &redirectRegex{
+					Regex:       `^(https?)://([^/]+)(?:/rw-redirect)(\?.*)?$`,
+					Replacement: "${1}://other.example.com/rw-redir-dest${3}",
+				}}},
+			},
+			unconverted: []Unconverted{
+				{Line: 1, Directive: "rewrite ^/rw-last/(.*)$ /rw-dest/$1 break;", Reason: "in the server block, the rewritten path selects the location, Traefik routers are not matched again"},
+			},
+			warnings: []string{"nginx.ingress.kubernetes.io/server-snippet applies to every Ingress of the hosts, the middlewares translated from it only to the routes of this Ingress"},
+		},
+		{
+			desc:       "allow list",
+			annotation: "configuration-snippet",
+			snippet: `
+allow 10.0.0.0/8;
+allow 192.168.1.1;
+deny all;
+`,
+			middlewares: []namedMiddleware{{purpose: "configuration-snippet-allowlist", spec: middleware{IPAllowList: &ipAllowList{
+				SourceRange: []string{"10.0.0.0/8", "192.168.1.1"},
+			}}}},
+		},
+		{
+			desc:       "deny all",
+			annotation: "configuration-snippet",
+			snippet:    "deny all;",
+			unconverted: []Unconverted{
+				{Line: 1, Directive: "deny all;", Reason: "the IPAllowList middleware only translates allow directives followed by deny all"},
+			},
+		},
+		{
+			desc:       "deny list",
+			annotation: "configuration-snippet",
+			snippet: `deny 10.0.0.1;
+allow all;`,
+			unconverted: []Unconverted{
+				{Line: 1, Directive: "deny 10.0.0.1;", Reason: "the IPAllowList middleware only translates allow directives followed by deny all"},
+				{Line: 2, Directive: "allow all;", Reason: "the IPAllowList middleware only translates allow directives followed by deny all"},
+			},
+		},
+		{
+			desc:       "blocks and other directives",
+			annotation: "server-snippet",
+			snippet: `
+location = /exact {
+    return 200 "exact-match";
+}
+set $my_var "hello";
+if ($request_method = POST) {
+    return 405 "Method Not Allowed";
+}
+`,
+			unconverted: []Unconverted{
+				{Line: 1, Directive: "location = /exact { … }", Reason: "location blocks are not translated, their conditions have no middleware equivalent"},
+				{Line: 4, Directive: `set $my_var "hello";`, Reason: "the set directive has no Traefik equivalent"},
+				{Line: 5, Directive: "if ($request_method = POST) { … }", Reason: "if blocks are not translated, their conditions have no middleware equivalent"},
+			},
+		},
+		{
+			desc:        "invalid snippet",
+			annotation:  "configuration-snippet",
+			snippet:     `add_header X-A "a`,
+			unconverted: []Unconverted{{Reason: "invalid snippet: line 1: unterminated quoted string"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ing := testutil.Ingress("default", "app", map[string]string{
+				annotationPrefix + tt.annotation: tt.snippet,
+			}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("app.example.com", testutil.Path("/", netv1.PathTypePrefix, "app", 80))}})
+
+			m := buildModel(ing, nil)
+
+			for i := range tt.unconverted {
+				tt.unconverted[i].Annotation = annotationPrefix + tt.annotation
+			}
+
+			assert.Equal(t, tt.middlewares, m.middlewares)
+			assert.Equal(t, tt.unconverted, m.unconverted)
+			assert.Equal(t, tt.warnings, m.warnings)
+
+			// The generated regexes are valid Go regexes.
+			for _, mw := range m.middlewares {
+				if mw.spec.RedirectRegex != nil {
+					_, err := regexp.Compile(mw.spec.RedirectRegex.Regex)
+					assert.NoError(t, err)
+				}
+				if mw.spec.ReplacePathRegex != nil {
+					_, err := regexp.Compile(mw.spec.ReplacePathRegex.Regex)
+					assert.NoError(t, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSnippets_Redirects(t *testing.T) {
+	t.Parallel()
+
+	// The redirects are applied as Traefik does, replacing the matches in the request URL.
+	tests := []struct {
+		snippet string
+		url     string
+		want    string
+	}{
+		{snippet: "return 301 https://$host$request_uri;", url: "http://app.example.com:8080/a/b?c=d", want: "https://app.example.com/a/b?c=d"},
+		{snippet: "return 302 $scheme://$http_host/login;", url: "https://app.example.com:8443/a", want: "https://app.example.com:8443/login"},
+		{snippet: "return 302 https://$host$uri;", url: "http://app.example.com/a?b=c", want: "https://app.example.com/a"},
+		{snippet: "rewrite ^/old/(.*)$ /new/$1 permanent;", url: "http://app.example.com/old/x?y=z", want: "http://app.example.com/new/x?y=z"},
+		{snippet: "rewrite ^/old/(.*)$ /new/$1? permanent;", url: "http://app.example.com/old/x?y=z", want: "http://app.example.com/new/x"},
+		{snippet: "rewrite ^/old /new redirect;", url: "http://app.example.com/old/x", want: "http://app.example.com/new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.snippet, func(t *testing.T) {
+			t.Parallel()
+
+			ing := testutil.Ingress("default", "app", map[string]string{annotationPrefix + "configuration-snippet": tt.snippet}, netv1.IngressSpec{})
+
+			m := buildModel(ing, nil)
+			require.Len(t, m.middlewares, 1)
+
+			redirect := m.middlewares[0].spec.RedirectRegex
+			require.NotNil(t, redirect)
+
+			got := regexp.MustCompile(redirect.Regex).ReplaceAllString(tt.url, redirect.Replacement)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
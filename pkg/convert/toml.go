@@ -0,0 +1,186 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// marshalTOML encodes the value as TOML, following its JSON encoding.
+// Keys are sorted, the values of a table come before its sub-tables, and
+// arrays of objects are written as arrays of tables.
+func marshalTOML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var table map[string]any
+	if err := decoder.Decode(&table); err != nil {
+		return nil, fmt.Errorf("only objects can be encoded as TOML: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, table, false); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTOMLTable writes the table, introduced by an array of tables header when
+// it is an element of an array of tables.
+func writeTOMLTable(buf *bytes.Buffer, keys []string, table map[string]any, element bool) error {
+	var values, tables, arrays []string
+	for key, value := range table {
+		switch value := value.(type) {
+		case nil:
+		case map[string]any:
+			tables = append(tables, key)
+		case []any:
+			if isTOMLArrayOfTables(value) {
+				arrays = append(arrays, key)
+			} else {
+				values = append(values, key)
+			}
+		default:
+			values = append(values, key)
+		}
+	}
+	slices.Sort(values)
+	slices.Sort(tables)
+	slices.Sort(arrays)
+
+	switch {
+	case element:
+		writeTOMLHeader(buf, "[["+tomlKeyPath(keys)+"]]")
+	case len(keys) > 0 && (len(values) > 0 || len(table) == 0):
+		// A table holding only sub-tables is implicitly defined by their headers.
+		writeTOMLHeader(buf, "["+tomlKeyPath(keys)+"]")
+	}
+
+	for _, key := range values {
+		value, err := tomlValue(table[key])
+		if err != nil {
+			return fmt.Errorf("encoding %s: %w", tomlKeyPath(append(keys, key)), err)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), value)
+	}
+
+	for _, key := range tables {
+		if err := writeTOMLTable(buf, append(slices.Clone(keys), key), table[key].(map[string]any), false); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range arrays {
+		for _, item := range table[key].([]any) {
+			if err := writeTOMLTable(buf, append(slices.Clone(keys), key), item.(map[string]any), true); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeTOMLHeader(buf *bytes.Buffer, header string) {
+	if buf.Len() > 0 {
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(header)
+	buf.WriteByte('\n')
+}
+
+func isTOMLArrayOfTables(array []any) bool {
+	if len(array) == 0 {
+		return false
+	}
+	for _, item := range array {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func tomlValue(value any) (string, error) {
+	switch value := value.(type) {
+	case string:
+		return tomlString(value), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	case json.Number:
+		return value.String(), nil
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			encoded, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, encoded)
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
+
+func tomlKeyPath(keys []string) string {
+	quoted := make([]string, 0, len(keys))
+	for _, key := range keys {
+		quoted = append(quoted, tomlKey(key))
+	}
+	return strings.Join(quoted, ".")
+}
+
+// tomlKey quotes the key unless it is a bare key.
+func tomlKey(key string) string {
+	if key == "" {
+		return `""`
+	}
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' && r != '-' {
+			return tomlString(key)
+		}
+	}
+	return key
+}
+
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
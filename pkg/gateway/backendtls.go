@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	annotationBackendProtocol     = "nginx.ingress.kubernetes.io/backend-protocol"
+	annotationProxySSLSecret      = "nginx.ingress.kubernetes.io/proxy-ssl-secret"
+	annotationProxySSLVerify      = "nginx.ingress.kubernetes.io/proxy-ssl-verify"
+	annotationProxySSLName        = "nginx.ingress.kubernetes.io/proxy-ssl-name"
+	annotationProxySSLVerifyDepth = "nginx.ingress.kubernetes.io/proxy-ssl-verify-depth"
+	annotationProxySSLProtocols   = "nginx.ingress.kubernetes.io/proxy-ssl-protocols"
+	annotationProxySSLCiphers     = "nginx.ingress.kubernetes.io/proxy-ssl-ciphers"
+
+	// caKey is the key of the CA certificate, in the proxy-ssl Secrets and in the
+	// ConfigMaps of the BackendTLSPolicies.
+	caKey = "ca.crt"
+)
+
+// CABundle is a ConfigMap holding the CA certificate of a proxy-ssl Secret, which
+// the BackendTLSPolicies reference instead of the Secret.
+type CABundle struct {
+	Namespace string
+	ConfigMap string
+	// Secret is the proxy-ssl Secret holding the CA certificate, as "namespace/name".
+	Secret string
+}
+
+type backendTLSPolicySpec struct {
+	TargetRefs []localPolicyTargetRef `json:"targetRefs"`
+	Validation backendTLSValidation   `json:"validation"`
+}
+
+type localPolicyTargetRef struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+type backendTLSValidation struct {
+	CACertificateRefs       []localObjectRef `json:"caCertificateRefs,omitempty"`
+	WellKnownCACertificates string           `json:"wellKnownCACertificates,omitempty"`
+	Hostname                string           `json:"hostname"`
+}
+
+type localObjectRef struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+// backendTLSPolicy is the verification of the certificates of a Service, and the
+// Ingress it comes from.
+type backendTLSPolicy struct {
+	ingress    string
+	validation backendTLSValidation
+}
+
+// addBackendTLSPolicies adds the BackendTLSPolicies verifying the certificates of the
+// Services NGINX proxies to over HTTPS with proxy-ssl-verify, and the CA bundles they reference.
+// A BackendTLSPolicy applies to every route of its Service, the first Ingress verifying
+// a Service sets its policy.
+func addBackendTLSPolicies(ingresses []*netv1.Ingress, topology *Topology) {
+	sorted := slices.Clone(ingresses)
+	slices.SortFunc(sorted, func(a, b *netv1.Ingress) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	policies := make(map[string]*backendTLSPolicy)
+	var services []string
+
+	for _, ing := range sorted {
+		protocol := strings.ToUpper(ing.Annotations[annotationBackendProtocol])
+		if protocol != "HTTPS" && protocol != "GRPCS" {
+			continue
+		}
+
+		ref := ing.Namespace + "/" + ing.Name
+		secret := ing.Annotations[annotationProxySSLSecret]
+
+		if ing.Annotations[annotationProxySSLVerify] != "on" {
+			topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s proxies to its backends over HTTPS without verifying their certificates, the Gateway API cannot skip the verification: a BackendTLSPolicy with their CA is required", ref))
+			continue
+		}
+
+		if secret != "" {
+			topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s presents the client certificate of the Secret %s to its backends, a BackendTLSPolicy cannot present one: set it on the Gateway with spec.tls.backend.clientCertificateRef", ref, secret))
+		}
+		for _, annotation := range []string{annotationProxySSLCiphers, annotationProxySSLProtocols, annotationProxySSLVerifyDepth} {
+			if _, ok := ing.Annotations[annotation]; ok {
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("the annotation %s of the Ingress %s has no BackendTLSPolicy equivalent", annotation, ref))
+			}
+		}
+
+		for _, service := range backendServices(ing) {
+			validation := backendTLSValidation{Hostname: ing.Annotations[annotationProxySSLName]}
+			if validation.Hostname == "" {
+				// NGINX verifies the certificates against the name of its upstream, which no certificate has.
+				validation.Hostname = service + "." + ing.Namespace + ".svc.cluster.local"
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s verifies the certificate of the Service %s without proxy-ssl-name, the BackendTLSPolicy verifies it against %s", ref, service, validation.Hostname))
+			}
+
+			if secret == "" {
+				validation.WellKnownCACertificates = "System"
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s verifies the certificate of the Service %s without proxy-ssl-secret, the BackendTLSPolicy trusts the system CAs", ref, service))
+			} else {
+				bundle := caBundle(ing.Namespace, secret)
+				validation.CACertificateRefs = []localObjectRef{{Group: "", Kind: "ConfigMap", Name: bundle.ConfigMap}}
+				if !slices.Contains(topology.CABundles, bundle) {
+					topology.CABundles = append(topology.CABundles, bundle)
+				}
+			}
+
+			key := ing.Namespace + "/" + service
+			if policy, ok := policies[key]; ok {
+				if !slices.Equal(policy.validation.CACertificateRefs, validation.CACertificateRefs) || policy.validation.Hostname != validation.Hostname || policy.validation.WellKnownCACertificates != validation.WellKnownCACertificates {
+					topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingresses %s and %s verify the certificate of the Service %s differently, its BackendTLSPolicy applies to every route and uses the settings of %s", policy.ingress, ref, key, policy.ingress))
+				}
+				continue
+			}
+
+			policies[key] = &backendTLSPolicy{ingress: ref, validation: validation}
+			services = append(services, key)
+		}
+	}
+
+	for _, key := range services {
+		namespace, service, _ := strings.Cut(key, "/")
+
+		topology.BackendTLSPolicies = append(topology.BackendTLSPolicies, Object{
+			APIVersion: gatewayAPIVersion,
+			Kind:       "BackendTLSPolicy",
+			Metadata:   ObjectMeta{Name: service + "-backend-tls", Namespace: namespace},
+			Spec: backendTLSPolicySpec{
+				TargetRefs: []localPolicyTargetRef{{Group: "", Kind: "Service", Name: service}},
+				Validation: policies[key].validation,
+			},
+		})
+	}
+
+	slices.SortFunc(topology.BackendTLSPolicies, func(a, b Object) int {
+		return cmp.Or(cmp.Compare(a.Metadata.Namespace, b.Metadata.Namespace), cmp.Compare(a.Metadata.Name, b.Metadata.Name))
+	})
+	slices.SortFunc(topology.CABundles, func(a, b CABundle) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.ConfigMap, b.ConfigMap))
+	})
+}
+
+// caBundle returns the ConfigMap of the namespace holding the CA certificate of the
+// proxy-ssl Secret, given as "namespace/name" or "name".
+func caBundle(namespace, secret string) CABundle {
+	secretNamespace, secretName, ok := strings.Cut(secret, "/")
+	if !ok {
+		secretNamespace, secretName = namespace, secret
+	}
+
+	configMap := secretName + "-ca"
+	if secretNamespace != namespace {
+		configMap = secretNamespace + "-" + configMap
+	}
+
+	return CABundle{Namespace: namespace, ConfigMap: configMap, Secret: secretNamespace + "/" + secretName}
+}
+
+// backendServices returns the Services the Ingress proxies to, in order of appearance.
+func backendServices(ing *netv1.Ingress) []string {
+	var services []string
+	add := func(backend *netv1.IngressBackend) {
+		if backend != nil && backend.Service != nil && !slices.Contains(services, backend.Service.Name) {
+			services = append(services, backend.Service.Name)
+		}
+	}
+
+	add(ing.Spec.DefaultBackend)
+	for _, rule := range ing.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			add(&path.Backend)
+		}
+	}
+
+	return services
+}
@@ -0,0 +1,66 @@
+package gateway
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// WriteManifests writes the Gateways, ReferenceGrants, Certificates, TLSRoutes and BackendTLSPolicies
+// of the topology as a multi-document YAML stream, introduced by comments mapping the hosts to
+// their listeners and certificates, creating the CA bundles, and listing the warnings.
+func WriteManifests(w io.Writer, topology *Topology) error {
+	if err := writeComments(w, topology); err != nil {
+		return fmt.Errorf("writing manifests: %w", err)
+	}
+
+	for _, object := range slices.Concat(topology.Gateways, topology.ReferenceGrants, topology.Certificates, topology.TLSRoutes, topology.BackendTLSPolicies) {
+		data, err := yaml.Marshal(object)
+		if err != nil {
+			return fmt.Errorf("marshaling %s %s/%s: %w", object.Kind, object.Metadata.Namespace, object.Metadata.Name, err)
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return fmt.Errorf("writing manifests: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func writeComments(w io.Writer, topology *Topology) error {
+	for _, mapping := range topology.Hosts {
+		listeners := mapping.HTTPListener
+		switch {
+		case mapping.PassthroughListener != "":
+			listeners += ", " + mapping.PassthroughListener + " passing the TLS connections through"
+		case mapping.HTTPSListener != "":
+			listeners += ", " + mapping.HTTPSListener + " with the Secret " + mapping.Secret
+		}
+
+		if _, err := fmt.Fprintf(w, "# Host %s: Gateway %s, listeners %s (Ingresses %s)\n", mapping.Host, mapping.Gateway, listeners, strings.Join(mapping.Ingresses, ", ")); err != nil {
+			return err
+		}
+	}
+
+	// The BackendTLSPolicies only reference ConfigMaps, the CA certificates are copied
+	// from the proxy-ssl Secrets, which are not read.
+	for _, bundle := range topology.CABundles {
+		secretNamespace, secretName, _ := strings.Cut(bundle.Secret, "/")
+		if _, err := fmt.Fprintf(w, "# ConfigMap %s/%s: CA certificate of the Secret %s, created with:\n#   kubectl -n %s get secret %s -o jsonpath='{.data.%s}' | base64 -d | kubectl -n %s create configmap %s --from-file=%s=/dev/stdin\n",
+			bundle.Namespace, bundle.ConfigMap, bundle.Secret, secretNamespace, secretName, strings.ReplaceAll(caKey, ".", `\.`), bundle.Namespace, bundle.ConfigMap, caKey); err != nil {
+			return err
+		}
+	}
+
+	for _, warning := range topology.Warnings {
+		if _, err := fmt.Fprintf(w, "# Warning: %s\n", warning); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,605 @@
+// Package gateway plans the Gateway API topology replacing the NGINX ingress controller:
+// the Gateways and their listeners, the namespaces allowed to attach routes to them,
+// the grants and certificates their TLS listeners require, the TLSRoutes of the
+// TLS passthrough hosts, and the BackendTLSPolicies of the Services verified over HTTPS.
+package gateway
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaxListeners is the maximum number of listeners of a Gateway, enforced by the Gateway API.
+const MaxListeners = 64
+
+const (
+	gatewayAPIVersion        = "gateway.networking.k8s.io/v1"
+	tlsRouteAPIVersion       = "gateway.networking.k8s.io/v1alpha2"
+	referenceGrantAPIVersion = "gateway.networking.k8s.io/v1beta1"
+	gatewayGroup             = "gateway.networking.k8s.io"
+	certificateAPIVersion    = "cert-manager.io/v1"
+
+	// labelNamespaceName is set by Kubernetes on every namespace.
+	labelNamespaceName = "kubernetes.io/metadata.name"
+
+	annotationClusterIssuer = "cert-manager.io/cluster-issuer"
+	annotationIssuer        = "cert-manager.io/issuer"
+	annotationIssuerKind    = "cert-manager.io/issuer-kind"
+	annotationIssuerGroup   = "cert-manager.io/issuer-group"
+
+	annotationSSLPassthrough = "nginx.ingress.kubernetes.io/ssl-passthrough"
+
+	listenerHTTP = "http"
+	portHTTP     = 80
+	portHTTPS    = 443
+)
+
+// Options configures the planned Gateways.
+type Options struct {
+	// Name of the Gateway. When the listeners are split across several Gateways,
+	// they are suffixed with their number.
+	Name      string
+	Namespace string
+	// ClassName is the GatewayClass of the Traefik Gateway controller.
+	ClassName string
+	// MaxListeners is the maximum number of listeners per Gateway, at most MaxListeners.
+	MaxListeners int
+}
+
+// Topology is the planned Gateway API topology.
+type Topology struct {
+	Gateways        []Object
+	ReferenceGrants []Object
+	// Certificates are the cert-manager Certificates of the Secrets issued for the Ingresses,
+	// which cert-manager deletes with the Ingresses.
+	Certificates []Object
+	// TLSRoutes pass the TLS connections of the hosts of the ssl-passthrough Ingresses
+	// through to their backends. The TLSRoute is part of the experimental channel.
+	TLSRoutes []Object
+	// BackendTLSPolicies verify the certificates of the Services of the proxy-ssl-verify
+	// Ingresses, against the CA certificates of the CABundles.
+	BackendTLSPolicies []Object
+	CABundles          []CABundle
+
+	// Hosts map the hosts of the Ingresses to their Gateway listener and certificate.
+	Hosts    []HostMapping
+	Warnings []string
+}
+
+// HostMapping is the Gateway listener serving a host.
+type HostMapping struct {
+	Host string
+	// Gateway is the Gateway serving the host, HTTPListener and HTTPSListener its listeners.
+	Gateway       string
+	HTTPListener  string
+	HTTPSListener string
+	// PassthroughListener is the TLS listener passing the connections of the host
+	// through, replacing its HTTPS listener.
+	PassthroughListener string
+	// Secret is the certificate of the HTTPS listener, as "namespace/name".
+	Secret    string
+	Ingresses []string
+}
+
+// Object is a generated Kubernetes object.
+type Object struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Metadata   ObjectMeta `json:"metadata"`
+	Spec       any        `json:"spec"`
+}
+
+// ObjectMeta is the metadata of a generated object.
+type ObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type gatewaySpec struct {
+	GatewayClassName string     `json:"gatewayClassName"`
+	Listeners        []listener `json:"listeners"`
+}
+
+type listener struct {
+	Name          string        `json:"name"`
+	Hostname      string        `json:"hostname,omitempty"`
+	Port          int32         `json:"port"`
+	Protocol      string        `json:"protocol"`
+	TLS           *listenerTLS  `json:"tls,omitempty"`
+	AllowedRoutes allowedRoutes `json:"allowedRoutes"`
+}
+
+type listenerTLS struct {
+	Mode            string         `json:"mode"`
+	CertificateRefs []secretObjRef `json:"certificateRefs,omitempty"`
+}
+
+type secretObjRef struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type allowedRoutes struct {
+	Namespaces routeNamespaces `json:"namespaces"`
+}
+
+type routeNamespaces struct {
+	From     string                `json:"from"`
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type tlsRouteSpec struct {
+	ParentRefs []parentRef    `json:"parentRefs"`
+	Hostnames  []string       `json:"hostnames"`
+	Rules      []tlsRouteRule `json:"rules"`
+}
+
+type parentRef struct {
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	SectionName string `json:"sectionName"`
+}
+
+type tlsRouteRule struct {
+	BackendRefs []backendRef `json:"backendRefs"`
+}
+
+type backendRef struct {
+	Name string `json:"name"`
+	Port *int32 `json:"port,omitempty"`
+}
+
+type referenceGrantSpec struct {
+	From []referenceGrantFrom `json:"from"`
+	To   []referenceGrantTo   `json:"to"`
+}
+
+type referenceGrantFrom struct {
+	Group     string `json:"group"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+}
+
+type referenceGrantTo struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name,omitempty"`
+}
+
+type certificateSpec struct {
+	SecretName string    `json:"secretName"`
+	DNSNames   []string  `json:"dnsNames"`
+	IssuerRef  issuerRef `json:"issuerRef"`
+}
+
+type issuerRef struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Group string `json:"group,omitempty"`
+}
+
+// host is a host of the Ingresses.
+type host struct {
+	name string
+	// secret is the Secret of the first TLS entry covering the host, as "namespace/name".
+	secret     string
+	namespaces []string
+	ingresses  []string
+
+	// passthrough is the backend the TLS connections of the host are passed through to.
+	passthrough *passthrough
+}
+
+// passthrough is the root path backend of a host of an ssl-passthrough Ingress.
+type passthrough struct {
+	namespace string
+	ingress   string
+	// route is the name of the TLSRoute of the host.
+	route   string
+	backend netv1.IngressServiceBackend
+}
+
+// certificate is a Secret issued by cert-manager for Ingresses.
+type certificate struct {
+	namespace string
+	name      string
+	dnsNames  []string
+	issuer    issuerRef
+}
+
+// New plans the Gateways serving the hosts of the Ingresses.
+// Every Gateway has an HTTP listener, and an HTTPS listener per host covered by a
+// TLS entry. The Gateways are split when their listeners exceed the maximum.
+func New(ingresses []*netv1.Ingress, opts Options) (*Topology, error) {
+	switch {
+	case opts.Name == "":
+		return nil, fmt.Errorf("the Gateway name is required")
+	case opts.Namespace == "":
+		return nil, fmt.Errorf("the Gateway namespace is required")
+	case opts.ClassName == "":
+		return nil, fmt.Errorf("the GatewayClass is required")
+	case opts.MaxListeners < 2 || opts.MaxListeners > MaxListeners:
+		return nil, fmt.Errorf("the maximum number of listeners must be between 2 and %d, got %d", MaxListeners, opts.MaxListeners)
+	}
+
+	topology := &Topology{}
+
+	hosts, certificates := collectHosts(ingresses, topology)
+
+	var plain, secure []*host
+	for _, h := range hosts {
+		if h.secret == "" && h.passthrough == nil {
+			plain = append(plain, h)
+			continue
+		}
+		secure = append(secure, h)
+	}
+
+	// The HTTPS listeners are grouped by namespace, for the Gateways to allow few namespaces.
+	slices.SortFunc(secure, func(a, b *host) int {
+		return cmp.Or(cmp.Compare(a.namespaces[0], b.namespaces[0]), cmp.Compare(a.name, b.name))
+	})
+
+	// Each Gateway has an HTTP listener, the HTTP hosts are served by the first one.
+	perGateway := opts.MaxListeners - 1
+	count := max(1, (len(secure)+perGateway-1)/perGateway)
+
+	if count > 1 {
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the listeners are split across %d Gateways, the hosts must resolve to the address of their Gateway", count))
+	}
+
+	secretNamespaces := make(map[string][]string)
+
+	for i := range count {
+		name := opts.Name
+		if count > 1 {
+			name += "-" + strconv.Itoa(i+1)
+		}
+
+		served := secure[min(i*perGateway, len(secure)):min((i+1)*perGateway, len(secure))]
+		if i == 0 {
+			served = slices.Concat(plain, served)
+		}
+
+		var namespaces []string
+		for _, h := range served {
+			namespaces = append(namespaces, h.namespaces...)
+		}
+
+		spec := gatewaySpec{
+			GatewayClassName: opts.ClassName,
+			Listeners: []listener{{
+				Name:          listenerHTTP,
+				Port:          portHTTP,
+				Protocol:      "HTTP",
+				AllowedRoutes: allowNamespaces(namespaces, opts.Namespace),
+			}},
+		}
+
+		for _, h := range served {
+			mapping := HostMapping{Host: h.name, Gateway: opts.Namespace + "/" + name, HTTPListener: listenerHTTP, Secret: h.secret, Ingresses: h.ingresses}
+
+			switch {
+			case h.passthrough != nil:
+				l := listener{
+					Name:          listenerName("tls", h.name, spec.Listeners),
+					Hostname:      h.name,
+					Port:          portHTTPS,
+					Protocol:      "TLS",
+					TLS:           &listenerTLS{Mode: "Passthrough"},
+					AllowedRoutes: allowNamespaces(h.namespaces, opts.Namespace),
+				}
+				spec.Listeners = append(spec.Listeners, l)
+				mapping.PassthroughListener = l.Name
+
+				topology.TLSRoutes = append(topology.TLSRoutes, tlsRoute(h, parentRef{Name: name, Namespace: opts.Namespace, SectionName: l.Name}, topology))
+
+			case h.secret != "":
+				secretNamespace, secretName, _ := strings.Cut(h.secret, "/")
+
+				ref := secretObjRef{Kind: "Secret", Name: secretName}
+				if secretNamespace != opts.Namespace {
+					ref.Namespace = secretNamespace
+					if !slices.Contains(secretNamespaces[secretNamespace], secretName) {
+						secretNamespaces[secretNamespace] = append(secretNamespaces[secretNamespace], secretName)
+					}
+				}
+
+				l := listener{
+					Name:          listenerName("https", h.name, spec.Listeners),
+					Hostname:      h.name,
+					Port:          portHTTPS,
+					Protocol:      "HTTPS",
+					TLS:           &listenerTLS{Mode: "Terminate", CertificateRefs: []secretObjRef{ref}},
+					AllowedRoutes: allowNamespaces(h.namespaces, opts.Namespace),
+				}
+				spec.Listeners = append(spec.Listeners, l)
+				mapping.HTTPSListener = l.Name
+			}
+
+			topology.Hosts = append(topology.Hosts, mapping)
+		}
+
+		topology.Gateways = append(topology.Gateways, Object{
+			APIVersion: gatewayAPIVersion,
+			Kind:       "Gateway",
+			Metadata:   ObjectMeta{Name: name, Namespace: opts.Namespace},
+			Spec:       spec,
+		})
+	}
+
+	slices.SortFunc(topology.Hosts, func(a, b HostMapping) int {
+		return cmp.Compare(a.Host, b.Host)
+	})
+	slices.SortFunc(topology.TLSRoutes, func(a, b Object) int {
+		return cmp.Or(cmp.Compare(a.Metadata.Namespace, b.Metadata.Namespace), cmp.Compare(a.Metadata.Name, b.Metadata.Name))
+	})
+
+	// The Gateways reference the Secrets of other namespaces through a grant in each of them.
+	for _, namespace := range slices.Sorted(maps.Keys(secretNamespaces)) {
+		spec := referenceGrantSpec{
+			From: []referenceGrantFrom{{Group: gatewayGroup, Kind: "Gateway", Namespace: opts.Namespace}},
+		}
+		for _, secret := range slices.Sorted(slices.Values(secretNamespaces[namespace])) {
+			spec.To = append(spec.To, referenceGrantTo{Group: "", Kind: "Secret", Name: secret})
+		}
+
+		topology.ReferenceGrants = append(topology.ReferenceGrants, Object{
+			APIVersion: referenceGrantAPIVersion,
+			Kind:       "ReferenceGrant",
+			Metadata:   ObjectMeta{Name: opts.Name + "-certificates", Namespace: namespace},
+			Spec:       spec,
+		})
+	}
+
+	addBackendTLSPolicies(ingresses, topology)
+
+	for _, c := range certificates {
+		topology.Certificates = append(topology.Certificates, Object{
+			APIVersion: certificateAPIVersion,
+			Kind:       "Certificate",
+			Metadata:   ObjectMeta{Name: c.name, Namespace: c.namespace},
+			Spec:       certificateSpec{SecretName: c.name, DNSNames: c.dnsNames, IssuerRef: c.issuer},
+		})
+	}
+
+	return topology, nil
+}
+
+// collectHosts returns the hosts of the Ingresses sorted by name, and the certificates
+// cert-manager issued for them sorted by namespace and name.
+func collectHosts(ingresses []*netv1.Ingress, topology *Topology) ([]*host, []*certificate) {
+	sorted := slices.Clone(ingresses)
+	slices.SortFunc(sorted, func(a, b *netv1.Ingress) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	hosts := make(map[string]*host)
+	certificates := make(map[string]*certificate)
+
+	addHost := func(ing *netv1.Ingress, name string) *host {
+		h, ok := hosts[name]
+		if !ok {
+			h = &host{name: name}
+			hosts[name] = h
+		}
+		if !slices.Contains(h.namespaces, ing.Namespace) {
+			h.namespaces = append(h.namespaces, ing.Namespace)
+		}
+		if ref := ing.Namespace + "/" + ing.Name; !slices.Contains(h.ingresses, ref) {
+			h.ingresses = append(h.ingresses, ref)
+		}
+		return h
+	}
+
+	for _, ing := range sorted {
+		ref := ing.Namespace + "/" + ing.Name
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" {
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s has a rule without host, its HTTPRoute must not set hostnames to match every host", ref))
+				continue
+			}
+			h := addHost(ing, rule.Host)
+
+			if strings.TrimSpace(ing.Annotations[annotationSSLPassthrough]) == "true" {
+				addPassthrough(ing, rule, h, topology)
+			}
+		}
+
+		issuer, issued := certificateIssuer(ing)
+
+		for _, tls := range ing.Spec.TLS {
+			switch {
+			case len(tls.Hosts) == 0:
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("a TLS entry of the Ingress %s has no hosts, NGINX serves its certificate by default, a listener requires a hostname", ref))
+				continue
+			case tls.SecretName == "":
+				topology.Warnings = append(topology.Warnings, fmt.Sprintf("a TLS entry of the Ingress %s has no Secret, NGINX serves its default certificate for %s", ref, strings.Join(tls.Hosts, ", ")))
+				continue
+			}
+
+			secret := ing.Namespace + "/" + tls.SecretName
+
+			for _, name := range tls.Hosts {
+				h := addHost(ing, name)
+				switch {
+				case h.secret == "":
+					h.secret = secret
+				case h.secret != secret:
+					topology.Warnings = append(topology.Warnings, fmt.Sprintf("the host %s is also covered by the Secret %s of the Ingress %s, the listener uses the Secret %s", name, secret, ref, h.secret))
+				}
+			}
+
+			if !issued {
+				continue
+			}
+
+			c, ok := certificates[secret]
+			if !ok {
+				c = &certificate{namespace: ing.Namespace, name: tls.SecretName, issuer: issuer}
+				certificates[secret] = c
+			}
+			for _, name := range tls.Hosts {
+				if !slices.Contains(c.dnsNames, name) {
+					c.dnsNames = append(c.dnsNames, name)
+				}
+			}
+		}
+	}
+
+	var sortedHosts []*host
+	routes := make(map[string][]*passthrough)
+	for _, name := range slices.Sorted(maps.Keys(hosts)) {
+		h := hosts[name]
+		sortedHosts = append(sortedHosts, h)
+
+		if p := h.passthrough; p != nil {
+			warnPassthrough(h, topology)
+			routes[p.namespace+"/"+p.ingress] = append(routes[p.namespace+"/"+p.ingress], p)
+		}
+	}
+
+	// The TLSRoutes are named after their Ingress, numbered when it passes several hosts through.
+	for _, passthroughs := range routes {
+		for i, p := range passthroughs {
+			p.route = p.ingress
+			if len(passthroughs) > 1 {
+				p.route += "-" + strconv.Itoa(i+1)
+			}
+		}
+	}
+
+	var sortedCertificates []*certificate
+	for _, secret := range slices.Sorted(maps.Keys(certificates)) {
+		sortedCertificates = append(sortedCertificates, certificates[secret])
+	}
+
+	return sortedHosts, sortedCertificates
+}
+
+// addPassthrough passes the TLS connections of the host of the rule through to the
+// backend of its root path. NGINX routes the connections by SNI before reading any
+// request, it ignores the other paths of the host over HTTPS.
+func addPassthrough(ing *netv1.Ingress, rule netv1.IngressRule, h *host, topology *Topology) {
+	ref := ing.Namespace + "/" + ing.Name
+
+	var root *netv1.IngressServiceBackend
+	if rule.HTTP != nil {
+		for _, path := range rule.HTTP.Paths {
+			if root == nil && cmp.Or(path.Path, "/") == "/" && path.Backend.Service != nil {
+				root = path.Backend.Service
+				continue
+			}
+			topology.Warnings = append(topology.Warnings, fmt.Sprintf("NGINX ignores the path %q of the host %s over HTTPS, the Ingress %s passes the TLS connections through to the backend of the path \"/\"", path.Path, rule.Host, ref))
+		}
+	}
+
+	switch {
+	case root == nil:
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the host %s of the Ingress %s has no path \"/\", NGINX does not pass its TLS connections through", rule.Host, ref))
+	case h.passthrough != nil:
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the TLS connections of the host %s are already passed through by the Ingress %s/%s, the Ingress %s is ignored", rule.Host, h.passthrough.namespace, h.passthrough.ingress, ref))
+	default:
+		h.passthrough = &passthrough{namespace: ing.Namespace, ingress: ing.Name, backend: *root}
+	}
+}
+
+// warnPassthrough warns about the routes and the certificate of the passed through
+// host, which NGINX silently ignores over HTTPS.
+func warnPassthrough(h *host, topology *Topology) {
+	ref := h.passthrough.namespace + "/" + h.passthrough.ingress
+
+	var others []string
+	for _, ingress := range h.ingresses {
+		if ingress != ref {
+			others = append(others, ingress)
+		}
+	}
+	if len(others) > 0 {
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the Ingress %s passes the TLS connections of the host %s through, NGINX ignores the paths of the Ingresses %s over HTTPS", ref, h.name, strings.Join(others, ", ")))
+	}
+
+	if h.secret != "" {
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the TLS connections of the host %s are passed through, the Secret %s is not served", h.name, h.secret))
+		h.secret = ""
+	}
+}
+
+// tlsRoute returns the TLSRoute passing the TLS connections of the host through.
+func tlsRoute(h *host, parent parentRef, topology *Topology) Object {
+	p := h.passthrough
+
+	backend := backendRef{Name: p.backend.Name}
+	if p.backend.Port.Name != "" {
+		topology.Warnings = append(topology.Warnings, fmt.Sprintf("the TLSRoute %s/%s references the Service %s by port name %q, set its port number", p.namespace, p.route, p.backend.Name, p.backend.Port.Name))
+	} else {
+		backend.Port = &p.backend.Port.Number
+	}
+
+	return Object{
+		APIVersion: tlsRouteAPIVersion,
+		Kind:       "TLSRoute",
+		Metadata:   ObjectMeta{Name: p.route, Namespace: p.namespace},
+		Spec: tlsRouteSpec{
+			ParentRefs: []parentRef{parent},
+			Hostnames:  []string{h.name},
+			Rules:      []tlsRouteRule{{BackendRefs: []backendRef{backend}}},
+		},
+	}
+}
+
+// certificateIssuer returns the cert-manager issuer of the certificates of the Ingress, if any.
+func certificateIssuer(ing *netv1.Ingress) (issuerRef, bool) {
+	if name := ing.Annotations[annotationClusterIssuer]; name != "" {
+		return issuerRef{Name: name, Kind: "ClusterIssuer"}, true
+	}
+
+	if name := ing.Annotations[annotationIssuer]; name != "" {
+		return issuerRef{
+			Name:  name,
+			Kind:  cmp.Or(ing.Annotations[annotationIssuerKind], "Issuer"),
+			Group: ing.Annotations[annotationIssuerGroup],
+		}, true
+	}
+
+	return issuerRef{}, false
+}
+
+// allowNamespaces allows the routes of the namespaces to attach to a listener.
+func allowNamespaces(namespaces []string, gatewayNamespace string) allowedRoutes {
+	namespaces = slices.Compact(slices.Sorted(slices.Values(namespaces)))
+
+	if len(namespaces) == 0 || (len(namespaces) == 1 && namespaces[0] == gatewayNamespace) {
+		return allowedRoutes{Namespaces: routeNamespaces{From: "Same"}}
+	}
+
+	return allowedRoutes{Namespaces: routeNamespaces{
+		From: "Selector",
+		Selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      labelNamespaceName,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   namespaces,
+		}}},
+	}}
+}
+
+// listenerName names the HTTPS or TLS listener of the host after it, as a valid
+// section name unique in the listeners of the Gateway.
+func listenerName(prefix, hostname string, listeners []listener) string {
+	name := prefix + "-" + strings.NewReplacer("*", "wildcard", ".", "-").Replace(strings.ToLower(hostname))
+
+	unique := name
+	for i := 2; slices.ContainsFunc(listeners, func(l listener) bool { return l.Name == unique }); i++ {
+		unique = name + "-" + strconv.Itoa(i)
+	}
+	return unique
+}
@@ -0,0 +1,246 @@
+package gateway
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func testOptions() Options {
+	return Options{Name: "traefik", Namespace: "traefik", ClassName: "traefik", MaxListeners: MaxListeners}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ingresses := []*netv1.Ingress{
+		testutil.Ingress("shop", "web", map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"}, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"shop.example.com", "www.shop.example.com"}, SecretName: "shop-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("shop.example.com"), testutil.Rule("www.shop.example.com")},
+		}),
+		testutil.Ingress("shop", "static", nil, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "static-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("shop.example.com")},
+		}),
+		testutil.Ingress("api", "backend", map[string]string{"cert-manager.io/issuer": "internal-ca"}, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"*.api.example.com"}, SecretName: "api-wildcard"}, {SecretName: "default-cert"}},
+			Rules: []netv1.IngressRule{testutil.Rule("*.api.example.com")},
+		}),
+		testutil.Ingress("traefik", "dashboard", nil, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"dashboard.example.com"}, SecretName: "dashboard-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("dashboard.example.com")},
+		}),
+		testutil.Ingress("blog", "web", nil, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("blog.example.com"), testutil.Rule("")},
+		}),
+	}
+
+	topology, err := New(ingresses, testOptions())
+	require.NoError(t, err)
+
+	require.Len(t, topology.Gateways, 1)
+	assert.Equal(t, []string{
+		"a TLS entry of the Ingress api/backend has no hosts, NGINX serves its certificate by default, a listener requires a hostname",
+		"the Ingress blog/web has a rule without host, its HTTPRoute must not set hostnames to match every host",
+		"the host shop.example.com is also covered by the Secret shop/shop-tls of the Ingress shop/web, the listener uses the Secret shop/static-tls",
+	}, topology.Warnings)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, topology))
+
+	testutil.AssertGolden(t, "gateway.yaml", buf.Bytes())
+}
+
+func TestNew_Split(t *testing.T) {
+	t.Parallel()
+
+	var ingresses []*netv1.Ingress
+	for i := range 5 {
+		host := fmt.Sprintf("app%d.example.com", i)
+		ingresses = append(ingresses, testutil.Ingress("apps", fmt.Sprintf("app%d", i), nil, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{host}, SecretName: "tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule(host)},
+		}))
+	}
+	ingresses = append(ingresses, testutil.Ingress("apps", "plain", nil, netv1.IngressSpec{
+		Rules: []netv1.IngressRule{testutil.Rule("plain.example.com")},
+	}))
+
+	opts := testOptions()
+	opts.MaxListeners = 3
+
+	topology, err := New(ingresses, opts)
+	require.NoError(t, err)
+
+	// Each Gateway has an HTTP listener and at most 2 HTTPS listeners.
+	require.Len(t, topology.Gateways, 3)
+	for i, gateway := range topology.Gateways {
+		assert.Equal(t, fmt.Sprintf("traefik-%d", i+1), gateway.Metadata.Name)
+		assert.LessOrEqual(t, len(gateway.Spec.(gatewaySpec).Listeners), opts.MaxListeners)
+	}
+	assert.Equal(t, []string{"the listeners are split across 3 Gateways, the hosts must resolve to the address of their Gateway"}, topology.Warnings)
+
+	gateways := make(map[string]string)
+	for _, mapping := range topology.Hosts {
+		gateways[mapping.Host] = mapping.Gateway
+	}
+	assert.Equal(t, map[string]string{
+		"app0.example.com":  "traefik/traefik-1",
+		"app1.example.com":  "traefik/traefik-1",
+		"app2.example.com":  "traefik/traefik-2",
+		"app3.example.com":  "traefik/traefik-2",
+		"app4.example.com":  "traefik/traefik-3",
+		"plain.example.com": "traefik/traefik-1",
+	}, gateways)
+
+	// The Secrets of a namespace are granted once.
+	require.Len(t, topology.ReferenceGrants, 1)
+	assert.Equal(t, []referenceGrantTo{{Kind: "Secret", Name: "tls"}}, topology.ReferenceGrants[0].Spec.(referenceGrantSpec).To)
+}
+
+func TestNew_Passthrough(t *testing.T) {
+	t.Parallel()
+
+	paths := func(ing *netv1.Ingress, paths ...string) *netv1.Ingress {
+		for i := range ing.Spec.Rules {
+			var httpPaths []netv1.HTTPIngressPath
+			for _, p := range paths {
+				httpPaths = append(httpPaths, netv1.HTTPIngressPath{
+					Path: p,
+					Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+						Name: ing.Name,
+						Port: netv1.ServiceBackendPort{Number: 8200},
+					}},
+				})
+			}
+			ing.Spec.Rules[i].HTTP = &netv1.HTTPIngressRuleValue{Paths: httpPaths}
+		}
+		return ing
+	}
+
+	passthrough := map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}
+
+	ingresses := []*netv1.Ingress{
+		paths(testutil.Ingress("vault", "server", passthrough, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("vault.example.com"), testutil.Rule("vault.example.org")},
+		}), "/", "/ui"),
+		paths(testutil.Ingress("vault", "metrics", nil, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"vault.example.com"}, SecretName: "vault-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("vault.example.com")},
+		}), "/metrics"),
+		paths(testutil.Ingress("git", "ssh", passthrough, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("git.example.com")},
+		}), "/git"),
+	}
+
+	topology, err := New(ingresses, testOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		`NGINX ignores the path "/git" of the host git.example.com over HTTPS, the Ingress git/ssh passes the TLS connections through to the backend of the path "/"`,
+		`the host git.example.com of the Ingress git/ssh has no path "/", NGINX does not pass its TLS connections through`,
+		`NGINX ignores the path "/ui" of the host vault.example.com over HTTPS, the Ingress vault/server passes the TLS connections through to the backend of the path "/"`,
+		`NGINX ignores the path "/ui" of the host vault.example.org over HTTPS, the Ingress vault/server passes the TLS connections through to the backend of the path "/"`,
+		"the Ingress vault/server passes the TLS connections of the host vault.example.com through, NGINX ignores the paths of the Ingresses vault/metrics over HTTPS",
+		"the TLS connections of the host vault.example.com are passed through, the Secret vault/vault-tls is not served",
+	}, topology.Warnings)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, topology))
+
+	testutil.AssertGolden(t, "passthrough.yaml", buf.Bytes())
+}
+
+func TestNew_BackendTLS(t *testing.T) {
+	t.Parallel()
+
+	backends := func(ing *netv1.Ingress, services ...string) *netv1.Ingress {
+		var httpPaths []netv1.HTTPIngressPath
+		for _, service := range services {
+			httpPaths = append(httpPaths, netv1.HTTPIngressPath{
+				Path: "/" + service,
+				Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+					Name: service,
+					Port: netv1.ServiceBackendPort{Number: 443},
+				}},
+			})
+		}
+		ing.Spec.Rules[0].HTTP = &netv1.HTTPIngressRuleValue{Paths: httpPaths}
+		return ing
+	}
+
+	ingresses := []*netv1.Ingress{
+		backends(testutil.Ingress("default", "verify-on", map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol":       "HTTPS",
+			"nginx.ingress.kubernetes.io/proxy-ssl-secret":       "default/proxy-ssl",
+			"nginx.ingress.kubernetes.io/proxy-ssl-verify":       "on",
+			"nginx.ingress.kubernetes.io/proxy-ssl-name":         "https-backend.default.svc.cluster.local",
+			"nginx.ingress.kubernetes.io/proxy-ssl-verify-depth": "2",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("verify-on.example.com")}}), "https-backend"),
+		backends(testutil.Ingress("default", "other-name", map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+			"nginx.ingress.kubernetes.io/proxy-ssl-secret": "certs/proxy-ssl",
+			"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("other.example.com")}}), "https-backend", "grpc-backend"),
+		backends(testutil.Ingress("default", "verify-off", map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("verify-off.example.com")}}), "https-backend"),
+		backends(testutil.Ingress("apps", "system", map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "GRPCS",
+			"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on",
+			"nginx.ingress.kubernetes.io/proxy-ssl-name":   "grpc.example.com",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("grpc.example.com")}}), "grpc"),
+		backends(testutil.Ingress("apps", "plain", map[string]string{
+			"nginx.ingress.kubernetes.io/proxy-ssl-verify": "on",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{testutil.Rule("plain.example.com")}}), "plain"),
+	}
+
+	topology, err := New(ingresses, testOptions())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"the Ingress apps/system verifies the certificate of the Service grpc without proxy-ssl-secret, the BackendTLSPolicy trusts the system CAs",
+		"the Ingress default/other-name presents the client certificate of the Secret certs/proxy-ssl to its backends, a BackendTLSPolicy cannot present one: set it on the Gateway with spec.tls.backend.clientCertificateRef",
+		"the Ingress default/other-name verifies the certificate of the Service https-backend without proxy-ssl-name, the BackendTLSPolicy verifies it against https-backend.default.svc.cluster.local",
+		"the Ingress default/other-name verifies the certificate of the Service grpc-backend without proxy-ssl-name, the BackendTLSPolicy verifies it against grpc-backend.default.svc.cluster.local",
+		"the Ingress default/verify-off proxies to its backends over HTTPS without verifying their certificates, the Gateway API cannot skip the verification: a BackendTLSPolicy with their CA is required",
+		"the Ingress default/verify-on presents the client certificate of the Secret default/proxy-ssl to its backends, a BackendTLSPolicy cannot present one: set it on the Gateway with spec.tls.backend.clientCertificateRef",
+		"the annotation nginx.ingress.kubernetes.io/proxy-ssl-verify-depth of the Ingress default/verify-on has no BackendTLSPolicy equivalent",
+		"the Ingresses default/other-name and default/verify-on verify the certificate of the Service default/https-backend differently, its BackendTLSPolicy applies to every route and uses the settings of default/other-name",
+	}, topology.Warnings)
+
+	assert.Equal(t, []CABundle{
+		{Namespace: "default", ConfigMap: "certs-proxy-ssl-ca", Secret: "certs/proxy-ssl"},
+		{Namespace: "default", ConfigMap: "proxy-ssl-ca", Secret: "default/proxy-ssl"},
+	}, topology.CABundles)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, topology))
+
+	testutil.AssertGolden(t, "backend-tls.yaml", buf.Bytes())
+}
+
+func TestNew_Options(t *testing.T) {
+	t.Parallel()
+
+	for _, maxListeners := range []int{0, 1, MaxListeners + 1} {
+		opts := testOptions()
+		opts.MaxListeners = maxListeners
+
+		_, err := New(nil, opts)
+		assert.Error(t, err, maxListeners)
+	}
+}
+
+func TestListenerName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https-wildcard-api-example-com", listenerName("https", "*.API.example.com", nil))
+	assert.Equal(t, "https-a-b-2", listenerName("https", "a-b", []listener{{Name: "https-a-b"}}))
+	assert.Equal(t, "tls-a-b", listenerName("tls", "a-b", []listener{{Name: "https-a-b"}}))
+}
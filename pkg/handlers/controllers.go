@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+//go:embed controllers.html
+var htmlControllersTemplate string
+
+// ControllerLink is an NGINX ingress controller whose report is served under Path.
+type ControllerLink struct {
+	analyzer.Controller
+
+	Path     string
+	Analyzer Analyzer
+}
+
+// controllerRow is a line of the controllers page.
+type controllerRow struct {
+	ControllerLink
+
+	Report analyzer.Report
+}
+
+// Controllers returns the page linking to the reports of several NGINX ingress
+// controllers, with their totals. With a scope, the totals only count the Ingresses
+// of the namespaces the viewer is allowed to see, like the reports.
+func Controllers(controllers []ControllerLink, scope *NamespaceScope) (http.HandlerFunc, error) {
+	tmpl, err := template.New("controllers").Parse(htmlControllersTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing controllers template: %w", err)
+	}
+
+	return func(rw http.ResponseWriter, req *http.Request) {
+		rows := make([]controllerRow, 0, len(controllers))
+		for _, controller := range controllers {
+			report, ok := scope.scopedReport(rw, req, controller.Analyzer.Report())
+			if !ok {
+				return
+			}
+
+			rows = append(rows, controllerRow{ControllerLink: controller, Report: report})
+		}
+
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+
+		if err := tmpl.Execute(rw, rows); err != nil {
+			log.Err(err).Msg("Error while executing controllers template")
+			JSONInternalServerError(rw)
+			return
+		}
+	}, nil
+}
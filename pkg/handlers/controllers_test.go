@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+func TestControllers(t *testing.T) {
+	t.Parallel()
+
+	index, err := Controllers([]ControllerLink{
+		{
+			Controller: analyzer.Controller{ControllerClass: "k8s.io/ingress-nginx", IngressClass: "nginx"},
+			Path:       "/controllers/0/",
+			Analyzer:   fakeAnalyzer{report: analyzer.Report{IngressCount: 4, CompatibleIngressCount: 3, CompatibleIngressPercentage: 75}},
+		},
+		{
+			Controller: analyzer.Controller{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "nginx-internal"},
+			Path:       "/controllers/1/",
+			Analyzer:   fakeAnalyzer{report: analyzer.Report{IngressCount: 2, UnsupportedIngressCount: 2, UnsupportedIngressPercentage: 100}},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	index(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body := rec.Body.String()
+	assert.Contains(t, body, `<a href="/controllers/0/">k8s.io/ingress-nginx</a>`)
+	assert.Contains(t, body, `<a href="/controllers/1/">k8s.io/ingress-nginx-internal</a>`)
+	assert.Contains(t, body, "3 (75.0%)")
+	assert.Contains(t, body, "2 (100.0%)")
+}
+
+func TestControllers_Scoped(t *testing.T) {
+	t.Parallel()
+
+	scope := &NamespaceScope{Authorizer: &fakeAuthorizer{allowed: map[string][]string{"alice": {"staging"}}}, UserHeader: "X-Forwarded-User"}
+	index, err := Controllers([]ControllerLink{
+		{
+			Controller: analyzer.Controller{ControllerClass: "k8s.io/ingress-nginx", IngressClass: "nginx"},
+			Path:       "/controllers/0/",
+			Analyzer:   fakeAnalyzer{report: queryReport()},
+		},
+	}, scope)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	index(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+
+	rec = httptest.NewRecorder()
+	index(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Only the Ingress web of the namespace staging is counted.
+	body := rec.Body.String()
+	assert.Contains(t, body, "<td>1</td>")
+	assert.Contains(t, body, "0 (0.0%)")
+	assert.Contains(t, body, "1 (100.0%)")
+	assert.NotContains(t, body, "<td>4</td>")
+}
@@ -11,11 +11,15 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/history"
 )
 
 //go:embed report.html
 var htmlReportTemplate string
 
+//go:embed trend.html
+var htmlTrendTemplate string
+
 // Client is the interface for sending reports.
 type Client interface {
 	SendReport(reportPayload ReportPayload) error
@@ -27,34 +31,59 @@ type Analyzer interface {
 	Report() analyzer.Report
 }
 
+// History is the interface for storing and reading past reports.
+type History interface {
+	Save(report analyzer.Report) (bool, error)
+	List() []history.Summary
+	Get(id string) (analyzer.Report, error)
+	Trend() history.Trend
+	FilteredTrend(filter func(analyzer.Report) (analyzer.Report, error)) (history.Trend, error)
+}
+
 // Handlers holds handler configuration.
 type Handlers struct {
 	client     Client
 	analyzer   Analyzer
+	history    History
+	scope      *NamespaceScope
 	reportTmpl *template.Template
+	trendTmpl  *template.Template
 }
 
 // New creates HTTP handlers.
-func New(analyzr *analyzer.Analyzer, client Client) (*Handlers, error) {
+// The history is optional, when nil the past reports are not stored.
+// The scope is optional, when set the views are restricted to the namespaces of the viewer.
+func New(analyzr *analyzer.Analyzer, client Client, hist History, scope *NamespaceScope) (*Handlers, error) {
 	reportTmpl, err := template.New("report").Funcs(template.FuncMap{
-		"hasPrefix": strings.HasPrefix,
+		"hasPrefix":   strings.HasPrefix,
+		"remediation": remediationSummary,
 	}).Parse(htmlReportTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("parsing report template: %w", err)
 	}
 
+	trendTmpl, err := template.New("trend").Parse(htmlTrendTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing trend template: %w", err)
+	}
+
 	return &Handlers{
 		client:     client,
 		analyzer:   analyzr,
+		history:    hist,
+		scope:      scope,
 		reportTmpl: reportTmpl,
+		trendTmpl:  trendTmpl,
 	}, nil
 }
 
 type reportVariables struct {
 	analyzer.Report
 
-	ReportJSON template.JS
-	ReportHash string
+	ReportJSON     template.JS
+	ReportHash     string
+	HistoryEnabled bool
+	Ingresses      ingressView
 }
 
 // ReportPayload is a lightweight version of analyzer.Report for API transmission.
@@ -71,11 +100,9 @@ type ReportPayload struct {
 	Version string `json:"version"`
 }
 
-// Report returns the HTML report for the Ingress NGINX migration.
-func (h *Handlers) Report(rw http.ResponseWriter, _ *http.Request) {
-	report := h.analyzer.Report()
-
-	reportPayload := ReportPayload{
+// NewReportPayload builds the anonymized payload transmitted for the given report.
+func NewReportPayload(report analyzer.Report) ReportPayload {
+	return ReportPayload{
 		GenerationDate:                report.GenerationDate,
 		IngressCount:                  report.IngressCount,
 		CompatibleIngressCount:        report.CompatibleIngressCount,
@@ -85,6 +112,33 @@ func (h *Handlers) Report(rw http.ResponseWriter, _ *http.Request) {
 		UnsupportedIngressAnnotations: report.UnsupportedIngressAnnotations,
 		Version:                       report.Version,
 	}
+}
+
+// remediationSummary describes the Traefik alternative to an unsupported annotation,
+// or returns an empty string when there is none.
+func remediationSummary(annotation string) string {
+	kind, summary, ok := analyzer.RemediationSummary(annotation)
+	if !ok {
+		return ""
+	}
+	return kind + ": " + summary
+}
+
+// Report returns the HTML report for the Ingress NGINX migration.
+// The listed ingresses are filtered, sorted and paginated from the URL query parameters.
+func (h *Handlers) Report(rw http.ResponseWriter, req *http.Request) {
+	query, err := parseIngressQuery(req.URL.Query())
+	if err != nil {
+		JSONErrorf(rw, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	report, ok := h.scope.scopedReport(rw, req, h.analyzer.Report())
+	if !ok {
+		return
+	}
+
+	reportPayload := NewReportPayload(report)
 
 	reportJSON, err := json.Marshal(reportPayload)
 	if err != nil {
@@ -94,9 +148,11 @@ func (h *Handlers) Report(rw http.ResponseWriter, _ *http.Request) {
 	}
 
 	reportVars := reportVariables{
-		Report:     report,
-		ReportJSON: template.JS(reportJSON),
-		ReportHash: report.Hash,
+		Report:         report,
+		ReportJSON:     template.JS(reportJSON),
+		ReportHash:     report.Hash,
+		HistoryEnabled: h.history != nil,
+		Ingresses:      buildIngressView(report, query),
 	}
 
 	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -109,6 +165,38 @@ func (h *Handlers) Report(rw http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// ingressPage is a page of the ingresses returned by the API.
+type ingressPage struct {
+	Ingresses []ingressRow `json:"ingresses"`
+	Total     int          `json:"total"`
+	Page      int          `json:"page"`
+	PageCount int          `json:"pageCount"`
+}
+
+// Ingresses returns the analyzed ingresses, filtered, sorted and paginated
+// with the same URL query parameters as the HTML report.
+func (h *Handlers) Ingresses(rw http.ResponseWriter, req *http.Request) {
+	query, err := parseIngressQuery(req.URL.Query())
+	if err != nil {
+		JSONErrorf(rw, http.StatusBadRequest, "%s", err)
+		return
+	}
+
+	report, ok := h.scope.scopedReport(rw, req, h.analyzer.Report())
+	if !ok {
+		return
+	}
+
+	view := buildIngressView(report, query)
+
+	writeJSON(rw, ingressPage{
+		Ingresses: append([]ingressRow{}, view.Rows...),
+		Total:     view.Total,
+		Page:      view.Page,
+		PageCount: view.PageCount,
+	})
+}
+
 // UpdateReport updates the analysis report.
 func (h *Handlers) UpdateReport(rw http.ResponseWriter, _ *http.Request) {
 	if err := h.analyzer.GenerateReport(); err != nil {
@@ -117,24 +205,26 @@ func (h *Handlers) UpdateReport(rw http.ResponseWriter, _ *http.Request) {
 		return
 	}
 
+	if h.history != nil {
+		if _, err := h.history.Save(h.analyzer.Report()); err != nil {
+			log.Err(err).Msg("Error while storing the report in the history")
+			JSONInternalServerError(rw)
+			return
+		}
+	}
+
 	rw.WriteHeader(http.StatusNoContent)
 }
 
 // SendReport sends the HTML report for the Ingress NGINX migration to Traefik Labs.
-func (h *Handlers) SendReport(rw http.ResponseWriter, _ *http.Request) {
-	report := h.analyzer.Report()
-
-	reportPayload := ReportPayload{
-		GenerationDate:                report.GenerationDate,
-		IngressCount:                  report.IngressCount,
-		CompatibleIngressCount:        report.CompatibleIngressCount,
-		VanillaIngressCount:           report.VanillaIngressCount,
-		SupportedIngressCount:         report.SupportedIngressCount,
-		UnsupportedIngressCount:       report.UnsupportedIngressCount,
-		UnsupportedIngressAnnotations: report.UnsupportedIngressAnnotations,
-		Version:                       report.Version,
+func (h *Handlers) SendReport(rw http.ResponseWriter, req *http.Request) {
+	report, ok := h.scope.scopedReport(rw, req, h.analyzer.Report())
+	if !ok {
+		return
 	}
 
+	reportPayload := NewReportPayload(report)
+
 	if err := h.client.SendReport(reportPayload); err != nil {
 		log.Err(err).Msg("Error while sending the report")
 		JSONInternalServerError(rw)
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/history"
+)
+
+// Dimensions of the trend chart, in SVG user units.
+const (
+	chartWidth   = 800
+	chartHeight  = 240
+	chartPadding = 30
+)
+
+// trendChart holds the SVG polylines of the trend chart.
+type trendChart struct {
+	Width    int
+	Height   int
+	MaxCount int
+
+	Compatible  string
+	Unsupported string
+	Unknown     string
+}
+
+type trendVariables struct {
+	history.Trend
+
+	Chart trendChart
+}
+
+// Trend returns the HTML page showing the evolution of the reports over time.
+func (h *Handlers) Trend(rw http.ResponseWriter, req *http.Request) {
+	if h.history == nil {
+		JSONError(rw, http.StatusNotFound, "history is disabled")
+		return
+	}
+
+	trend, ok := h.scopedTrend(rw, req)
+	if !ok {
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := h.trendTmpl.Execute(rw, trendVariables{Trend: trend, Chart: buildTrendChart(trend.Reports)}); err != nil {
+		log.Err(err).Msg("Error while executing trend template")
+		JSONInternalServerError(rw)
+		return
+	}
+}
+
+// Reports returns the summaries of the past reports, oldest first.
+func (h *Handlers) Reports(rw http.ResponseWriter, req *http.Request) {
+	if h.history == nil {
+		JSONError(rw, http.StatusNotFound, "history is disabled")
+		return
+	}
+
+	if h.scope == nil {
+		writeJSON(rw, h.history.List())
+		return
+	}
+
+	trend, ok := h.scopedTrend(rw, req)
+	if !ok {
+		return
+	}
+
+	writeJSON(rw, trend.Reports)
+}
+
+// scopedTrend returns the trend of the past reports restricted to the namespaces the
+// viewer of the request is allowed to see, the whole trend without scope.
+// When the trend cannot be scoped, the error is written and false is returned.
+func (h *Handlers) scopedTrend(rw http.ResponseWriter, req *http.Request) (history.Trend, bool) {
+	if h.scope == nil {
+		return h.history.Trend(), true
+	}
+
+	v, ok := h.scope.viewer(rw, req)
+	if !ok {
+		return history.Trend{}, false
+	}
+
+	// The viewer reviews the access to each namespace once for all the past reports.
+	trend, err := h.history.FilteredTrend(v.filter)
+	if err != nil {
+		log.Err(err).Str("user", v.user).Msg("Error while scoping the history")
+		JSONInternalServerError(rw)
+		return history.Trend{}, false
+	}
+
+	return trend, true
+}
+
+// PastReport returns a past report by ID.
+func (h *Handlers) PastReport(rw http.ResponseWriter, req *http.Request) {
+	if h.history == nil {
+		JSONError(rw, http.StatusNotFound, "history is disabled")
+		return
+	}
+
+	id := httprouter.ParamsFromContext(req.Context()).ByName("id")
+
+	report, err := h.history.Get(id)
+	if errors.Is(err, history.ErrNotFound) {
+		JSONErrorf(rw, http.StatusNotFound, "report %q not found", id)
+		return
+	}
+	if err != nil {
+		log.Err(err).Str("id", id).Msg("Error while reading a past report")
+		JSONInternalServerError(rw)
+		return
+	}
+
+	report, ok := h.scope.scopedReport(rw, req, report)
+	if !ok {
+		return
+	}
+
+	writeJSON(rw, report)
+}
+
+// buildTrendChart places the reports on the chart, proportionally to their
+// generation date on the X axis and to their counts on the Y axis.
+func buildTrendChart(reports []history.Summary) trendChart {
+	chart := trendChart{Width: chartWidth, Height: chartHeight}
+	if len(reports) == 0 {
+		return chart
+	}
+
+	for _, r := range reports {
+		chart.MaxCount = max(chart.MaxCount, r.IngressCount, r.CompatibleIngressCount, r.UnsupportedIngressCount)
+	}
+
+	start := reports[0].GenerationDate
+	span := reports[len(reports)-1].GenerationDate.Sub(start)
+
+	x := func(i int) float64 {
+		if span <= 0 {
+			return chartWidth / 2
+		}
+		return chartPadding + float64(reports[i].GenerationDate.Sub(start))/float64(span)*(chartWidth-2*chartPadding)
+	}
+	y := func(count int) float64 {
+		if chart.MaxCount == 0 {
+			return chartHeight - chartPadding
+		}
+		return chartHeight - chartPadding - float64(count)/float64(chart.MaxCount)*(chartHeight-2*chartPadding)
+	}
+
+	var compatible, unsupported, unknown []string
+	for i, r := range reports {
+		compatible = append(compatible, fmt.Sprintf("%.1f,%.1f", x(i), y(r.CompatibleIngressCount)))
+		unsupported = append(unsupported, fmt.Sprintf("%.1f,%.1f", x(i), y(r.UnsupportedIngressCount)))
+		unknown = append(unknown, fmt.Sprintf("%.1f,%.1f", x(i), y(r.UnknownIngressCount)))
+	}
+
+	chart.Compatible = strings.Join(compatible, " ")
+	chart.Unsupported = strings.Join(unsupported, " ")
+	chart.Unknown = strings.Join(unknown, " ")
+
+	return chart
+}
+
+func writeJSON(rw http.ResponseWriter, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(rw).Encode(v); err != nil {
+		log.Err(err).Msg("Error while encoding the response")
+	}
+}
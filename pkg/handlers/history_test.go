@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/ingress-nginx-migration/pkg/history"
+)
+
+func TestBuildTrendChart(t *testing.T) {
+	t.Parallel()
+
+	day1 := time.Date(2026, 5, 25, 10, 0, 0, 0, time.UTC)
+
+	chart := buildTrendChart([]history.Summary{
+		{GenerationDate: day1, IngressCount: 4, CompatibleIngressCount: 1, UnsupportedIngressCount: 3, UnknownIngressCount: 1},
+		{GenerationDate: day1.Add(24 * time.Hour), IngressCount: 4, CompatibleIngressCount: 2, UnsupportedIngressCount: 2},
+		{GenerationDate: day1.Add(72 * time.Hour), IngressCount: 4, CompatibleIngressCount: 4},
+	})
+
+	assert.Equal(t, 4, chart.MaxCount)
+	// Points are placed proportionally to the generation date.
+	assert.Equal(t, "30.0,165.0 276.7,120.0 770.0,30.0", chart.Compatible)
+	assert.Equal(t, "30.0,75.0 276.7,120.0 770.0,210.0", chart.Unsupported)
+	assert.Equal(t, "30.0,165.0 276.7,210.0 770.0,210.0", chart.Unknown)
+}
+
+func TestBuildTrendChart_SingleReport(t *testing.T) {
+	t.Parallel()
+
+	chart := buildTrendChart([]history.Summary{{GenerationDate: time.Now(), IngressCount: 2, CompatibleIngressCount: 2}})
+
+	assert.Equal(t, "400.0,30.0", chart.Compatible)
+	assert.Equal(t, "400.0,210.0", chart.Unsupported)
+}
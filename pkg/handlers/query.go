@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// Query parameters driving the ingress view of the report.
+const (
+	paramNamespace  = "namespace"
+	paramClass      = "class"
+	paramAnnotation = "annotation"
+	paramVerdict    = "verdict"
+	paramSearch     = "q"
+	paramSort       = "sort"
+	paramOrder      = "order"
+	paramPage       = "page"
+	paramPerPage    = "per_page"
+)
+
+// Sort keys of the ingress view.
+const (
+	sortNamespace   = "namespace"
+	sortName        = "name"
+	sortClass       = "class"
+	sortVerdict     = "verdict"
+	sortAnnotations = "annotations"
+)
+
+const (
+	orderAsc  = "asc"
+	orderDesc = "desc"
+
+	// verdictAll lists ingresses of every verdict. Without verdict parameter,
+	// only the ingresses requiring attention are listed.
+	verdictAll = "all"
+
+	defaultPerPage = 50
+	maxPerPage     = 500
+)
+
+// ingressQuery is the filtering, sorting and pagination of the ingress view,
+// read from the URL query parameters so that filtered views can be shared as links.
+type ingressQuery struct {
+	Namespace  string
+	Class      string
+	Annotation string
+	Verdict    string
+	Search     string
+	Sort       string
+	Order      string
+	Page       int
+	PerPage    int
+}
+
+// ingressRow is an Ingress of the view with its verdict.
+type ingressRow struct {
+	analyzer.IngressReport
+
+	Verdict string `json:"verdict"`
+}
+
+// ingressView is a page of the filtered and sorted ingresses.
+type ingressView struct {
+	Query ingressQuery
+
+	Rows      []ingressRow
+	Total     int
+	Page      int
+	PageCount int
+
+	PrevURL  string
+	NextURL  string
+	SortURLs map[string]string
+	ResetURL string
+
+	// Filter options, built from all the ingresses of the report.
+	Namespaces  []string
+	Classes     []string
+	Annotations []string
+	Verdicts    []string
+}
+
+func parseIngressQuery(values url.Values) (ingressQuery, error) {
+	q := ingressQuery{
+		Namespace:  values.Get(paramNamespace),
+		Class:      values.Get(paramClass),
+		Annotation: values.Get(paramAnnotation),
+		Verdict:    values.Get(paramVerdict),
+		Search:     strings.TrimSpace(values.Get(paramSearch)),
+		Sort:       cmp.Or(values.Get(paramSort), sortNamespace),
+		Order:      cmp.Or(values.Get(paramOrder), orderAsc),
+		Page:       1,
+		PerPage:    defaultPerPage,
+	}
+
+	switch q.Verdict {
+	case "", verdictAll, analyzer.VerdictVanilla, analyzer.VerdictSupported, analyzer.VerdictUnsupported:
+	default:
+		return ingressQuery{}, fmt.Errorf("invalid %s %q", paramVerdict, q.Verdict)
+	}
+
+	switch q.Sort {
+	case sortNamespace, sortName, sortClass, sortVerdict, sortAnnotations:
+	default:
+		return ingressQuery{}, fmt.Errorf("invalid %s %q", paramSort, q.Sort)
+	}
+
+	if q.Order != orderAsc && q.Order != orderDesc {
+		return ingressQuery{}, fmt.Errorf("invalid %s %q", paramOrder, q.Order)
+	}
+
+	if raw := values.Get(paramPage); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return ingressQuery{}, fmt.Errorf("invalid %s %q", paramPage, raw)
+		}
+		q.Page = page
+	}
+
+	if raw := values.Get(paramPerPage); raw != "" {
+		perPage, err := strconv.Atoi(raw)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return ingressQuery{}, fmt.Errorf("invalid %s %q (must be between 1 and %d)", paramPerPage, raw, maxPerPage)
+		}
+		q.PerPage = perPage
+	}
+
+	return q, nil
+}
+
+// values encodes the query, omitting default values to keep links short.
+func (q ingressQuery) values() url.Values {
+	values := url.Values{}
+	set := func(key, value, defaultValue string) {
+		if value != "" && value != defaultValue {
+			values.Set(key, value)
+		}
+	}
+
+	set(paramNamespace, q.Namespace, "")
+	set(paramClass, q.Class, "")
+	set(paramAnnotation, q.Annotation, "")
+	set(paramVerdict, q.Verdict, "")
+	set(paramSearch, q.Search, "")
+	set(paramSort, q.Sort, sortNamespace)
+	set(paramOrder, q.Order, orderAsc)
+	set(paramPage, strconv.Itoa(q.Page), "1")
+	set(paramPerPage, strconv.Itoa(q.PerPage), strconv.Itoa(defaultPerPage))
+
+	return values
+}
+
+func (q ingressQuery) url() string {
+	if encoded := q.values().Encode(); encoded != "" {
+		return "?" + encoded
+	}
+	return "?"
+}
+
+// buildIngressView filters, sorts and paginates all the ingresses of the report.
+func buildIngressView(report analyzer.Report, q ingressQuery) ingressView {
+	all := make([]ingressRow, 0, len(report.UnsupportedIngresses)+len(report.CompatibleIngresses))
+	for _, ing := range report.UnsupportedIngresses {
+		all = append(all, ingressRow{IngressReport: ing, Verdict: ing.Verdict()})
+	}
+	for _, ing := range report.CompatibleIngresses {
+		all = append(all, ingressRow{IngressReport: ing, Verdict: ing.Verdict()})
+	}
+
+	view := ingressView{
+		Query:    q,
+		Verdicts: []string{analyzer.VerdictUnsupported, analyzer.VerdictSupported, analyzer.VerdictVanilla},
+		SortURLs: make(map[string]string),
+	}
+
+	namespaces := make(map[string]struct{})
+	classes := make(map[string]struct{})
+	annotations := make(map[string]struct{})
+
+	var rows []ingressRow
+	for _, row := range all {
+		namespaces[row.Namespace] = struct{}{}
+		classes[rowClass(row)] = struct{}{}
+		for _, ann := range rowAnnotations(row) {
+			annotations[ann] = struct{}{}
+		}
+
+		if q.matches(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	view.Namespaces = sortedKeys(namespaces)
+	view.Classes = sortedKeys(classes)
+	view.Annotations = sortedKeys(annotations)
+
+	slices.SortStableFunc(rows, q.compare)
+
+	view.Total = len(rows)
+	view.PageCount = max(1, (view.Total+q.PerPage-1)/q.PerPage)
+	view.Page = min(q.Page, view.PageCount)
+
+	start := (view.Page - 1) * q.PerPage
+	view.Rows = rows[start:min(start+q.PerPage, view.Total)]
+
+	if view.Page > 1 {
+		prev := q
+		prev.Page = view.Page - 1
+		view.PrevURL = prev.url()
+	}
+	if view.Page < view.PageCount {
+		next := q
+		next.Page = view.Page + 1
+		view.NextURL = next.url()
+	}
+
+	for _, key := range []string{sortNamespace, sortName, sortClass, sortVerdict, sortAnnotations} {
+		sorted := q
+		sorted.Sort = key
+		sorted.Page = 1
+		sorted.Order = orderAsc
+		if q.Sort == key && q.Order == orderAsc {
+			sorted.Order = orderDesc
+		}
+		view.SortURLs[key] = sorted.url()
+	}
+
+	view.ResetURL = ingressQuery{Sort: sortNamespace, Order: orderAsc, Page: 1, PerPage: defaultPerPage}.url()
+
+	return view
+}
+
+func (q ingressQuery) matches(row ingressRow) bool {
+	switch q.Verdict {
+	case verdictAll:
+	case "":
+		if row.Verdict != analyzer.VerdictUnsupported {
+			return false
+		}
+	default:
+		if row.Verdict != q.Verdict {
+			return false
+		}
+	}
+
+	if q.Namespace != "" && row.Namespace != q.Namespace {
+		return false
+	}
+
+	if q.Class != "" && rowClass(row) != q.Class {
+		return false
+	}
+
+	annotations := rowAnnotations(row)
+
+	if q.Annotation != "" && !slices.ContainsFunc(annotations, func(ann string) bool { return matchAnnotation(ann, q.Annotation) }) {
+		return false
+	}
+
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		fields := append([]string{row.Namespace, row.Name, rowClass(row)}, annotations...)
+
+		return slices.ContainsFunc(fields, func(field string) bool {
+			return strings.Contains(strings.ToLower(field), search)
+		})
+	}
+
+	return true
+}
+
+func (q ingressQuery) compare(a, b ingressRow) int {
+	c := q.compareBy(a, b)
+	if q.Order == orderDesc {
+		return -c
+	}
+	return c
+}
+
+func (q ingressQuery) compareBy(a, b ingressRow) int {
+	byNamespaceName := func() int {
+		if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	}
+
+	var c int
+	switch q.Sort {
+	case sortName:
+		c = cmp.Compare(a.Name, b.Name)
+	case sortClass:
+		c = cmp.Compare(rowClass(a), rowClass(b))
+	case sortVerdict:
+		c = cmp.Compare(verdictRank(a.Verdict), verdictRank(b.Verdict))
+	case sortAnnotations:
+		c = cmp.Compare(len(a.UnsupportedAnnotations)+len(a.UnknownAnnotations), len(b.UnsupportedAnnotations)+len(b.UnknownAnnotations))
+	}
+
+	if c != 0 {
+		return c
+	}
+	return byNamespaceName()
+}
+
+// verdictRank orders the verdicts from the most to the least problematic.
+func verdictRank(verdict string) int {
+	switch verdict {
+	case analyzer.VerdictUnsupported:
+		return 0
+	case analyzer.VerdictSupported:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// rowClass returns the class the Ingress is counted in, falling back to its
+// IngressClassName for reports generated before the class was recorded.
+func rowClass(row ingressRow) string {
+	return cmp.Or(row.Class, row.IngressClassName)
+}
+
+func rowAnnotations(row ingressRow) []string {
+	annotations := make([]string, 0, len(row.SupportedAnnotations)+len(row.UnsupportedAnnotations)+len(row.UnknownAnnotations))
+	for _, ann := range row.SupportedAnnotations {
+		annotations = append(annotations, ann.Name)
+	}
+	annotations = append(annotations, row.UnsupportedAnnotations...)
+	annotations = append(annotations, row.UnknownAnnotations...)
+
+	return annotations
+}
+
+// matchAnnotation matches the annotation against the filter, which can omit
+// the nginx.ingress.kubernetes.io/ prefix.
+func matchAnnotation(annotation, filter string) bool {
+	if annotation == filter {
+		return true
+	}
+
+	_, name, ok := strings.Cut(annotation, "/")
+	return ok && !strings.Contains(filter, "/") && name == filter
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
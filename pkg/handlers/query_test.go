@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+type fakeAnalyzer struct {
+	report analyzer.Report
+}
+
+func (f fakeAnalyzer) GenerateReport() error { return nil }
+
+func (f fakeAnalyzer) Report() analyzer.Report { return f.report }
+
+func queryReport() analyzer.Report {
+	return analyzer.Report{
+		IngressCount:            4,
+		IngressCountByClass:     map[string]int{"nginx": 3, "without-class": 1},
+		UnsupportedIngressCount: 2,
+		UnsupportedIngresses: []analyzer.IngressReport{
+			{
+				Name:                   "api",
+				Namespace:              "prod",
+				IngressClassName:       "nginx",
+				Class:                  "nginx",
+				UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/limit-connections"},
+			},
+			{
+				Name:                   "web",
+				Namespace:              "staging",
+				Class:                  "without-class",
+				UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/limit-connections"},
+				UnknownAnnotations:     []string{"nginx.ingress.kubernetes.io/totally-made-up"},
+			},
+		},
+		CompatibleIngressCount: 2,
+		CompatibleIngresses: []analyzer.IngressReport{
+			{
+				Name:                 "shop",
+				Namespace:            "prod",
+				IngressClassName:     "nginx",
+				Class:                "nginx",
+				SupportedAnnotations: []analyzer.AnnotationInfo{{Name: "nginx.ingress.kubernetes.io/ssl-redirect", Version: "v3.6"}},
+			},
+			{
+				Name:             "static",
+				Namespace:        "prod",
+				IngressClassName: "nginx",
+				Class:            "nginx",
+			},
+		},
+	}
+}
+
+func TestBuildIngressView(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		query     string
+		wantNames []string
+		wantTotal int
+	}{
+		{name: "defaults to ingresses needing attention", query: "", wantNames: []string{"api", "web"}, wantTotal: 2},
+		{name: "all verdicts", query: "verdict=all", wantNames: []string{"api", "shop", "static", "web"}, wantTotal: 4},
+		{name: "single verdict", query: "verdict=vanilla", wantNames: []string{"static"}, wantTotal: 1},
+		{name: "namespace", query: "verdict=all&namespace=staging", wantNames: []string{"web"}, wantTotal: 1},
+		{name: "class", query: "verdict=all&class=without-class", wantNames: []string{"web"}, wantTotal: 1},
+		{name: "annotation without prefix", query: "verdict=all&annotation=ssl-redirect", wantNames: []string{"shop"}, wantTotal: 1},
+		{name: "annotation with prefix", query: "annotation=nginx.ingress.kubernetes.io/totally-made-up", wantNames: []string{"web"}, wantTotal: 1},
+		{name: "free text search is case insensitive", query: "verdict=all&q=SHO", wantNames: []string{"shop"}, wantTotal: 1},
+		{name: "free text search on annotations", query: "q=made-up", wantNames: []string{"web"}, wantTotal: 1},
+		{name: "sort by name descending", query: "verdict=all&sort=name&order=desc", wantNames: []string{"web", "static", "shop", "api"}, wantTotal: 4},
+		{name: "sort by verdict", query: "verdict=all&sort=verdict", wantNames: []string{"api", "web", "shop", "static"}, wantTotal: 4},
+		{name: "sort by annotations to fix", query: "sort=annotations&order=desc", wantNames: []string{"web", "api"}, wantTotal: 2},
+		{name: "pagination", query: "verdict=all&per_page=3&page=2", wantNames: []string{"web"}, wantTotal: 4},
+		{name: "page beyond the last one", query: "verdict=all&per_page=3&page=10", wantNames: []string{"web"}, wantTotal: 4},
+		{name: "no match", query: "namespace=unknown", wantTotal: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			q, err := parseIngressQuery(values)
+			require.NoError(t, err)
+
+			view := buildIngressView(queryReport(), q)
+
+			var names []string
+			for _, row := range view.Rows {
+				names = append(names, row.Name)
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantTotal, view.Total)
+		})
+	}
+}
+
+func TestBuildIngressView_Links(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery("verdict=all&namespace=prod&per_page=1&page=2")
+	require.NoError(t, err)
+
+	q, err := parseIngressQuery(values)
+	require.NoError(t, err)
+
+	view := buildIngressView(queryReport(), q)
+
+	assert.Equal(t, 3, view.PageCount)
+	assert.Equal(t, "?namespace=prod&per_page=1&verdict=all", view.PrevURL)
+	assert.Equal(t, "?namespace=prod&page=3&per_page=1&verdict=all", view.NextURL)
+	assert.Equal(t, "?namespace=prod&order=desc&per_page=1&verdict=all", view.SortURLs[sortNamespace])
+	assert.Equal(t, "?namespace=prod&per_page=1&sort=name&verdict=all", view.SortURLs[sortName])
+	assert.Equal(t, []string{"prod", "staging"}, view.Namespaces)
+	assert.Equal(t, []string{"nginx", "without-class"}, view.Classes)
+}
+
+func TestParseIngressQuery_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, query := range []string{"verdict=bad", "sort=bad", "order=up", "page=0", "per_page=1000", "page=abc"} {
+		values, err := url.ParseQuery(query)
+		require.NoError(t, err)
+
+		_, err = parseIngressQuery(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestReport_Filtered(t *testing.T) {
+	t.Parallel()
+
+	h, err := New(nil, nil, nil, nil)
+	require.NoError(t, err)
+	h.analyzer = fakeAnalyzer{report: queryReport()}
+
+	rec := httptest.NewRecorder()
+	h.Report(rec, httptest.NewRequest(http.MethodGet, "/?verdict=all&namespace=prod&q=sta", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<strong>static</strong>")
+	assert.NotContains(t, rec.Body.String(), "<strong>api</strong>")
+	// The form stays relative, the report being also served under /controllers/<n>/.
+	assert.Contains(t, rec.Body.String(), `<form class="filters" method="get" action=".">`)
+
+	rec = httptest.NewRecorder()
+	h.Report(rec, httptest.NewRequest(http.MethodGet, "/?sort=bad", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
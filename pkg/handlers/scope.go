@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// NamespaceAuthorizer is the interface for checking the namespaces in which a
+// user is allowed to list Ingresses.
+type NamespaceAuthorizer interface {
+	AllowedNamespaces(ctx context.Context, user string, groups, namespaces []string) (map[string]bool, error)
+}
+
+// NamespaceScope restricts the report views to the namespaces the viewer is
+// allowed to list Ingresses in.
+// The viewer identity is read from headers set by a trusted authenticating proxy,
+// which must strip these headers from the incoming requests.
+type NamespaceScope struct {
+	Authorizer NamespaceAuthorizer
+
+	// UserHeader is the header holding the user name.
+	UserHeader string
+	// GroupsHeader is the optional header holding the comma-separated user groups.
+	GroupsHeader string
+}
+
+// scopedReport returns the report restricted to the namespaces the viewer of
+// the request is allowed to see, with its totals recomputed, the report as is
+// without scope.
+// When the report cannot be scoped, the error is written and false is returned.
+func (s *NamespaceScope) scopedReport(rw http.ResponseWriter, req *http.Request, report analyzer.Report) (analyzer.Report, bool) {
+	if s == nil {
+		return report, true
+	}
+
+	v, ok := s.viewer(rw, req)
+	if !ok {
+		return analyzer.Report{}, false
+	}
+
+	scoped, err := v.filter(report)
+	if err != nil {
+		log.Err(err).Str("user", v.user).Msg("Error while reviewing the namespaces access")
+		JSONInternalServerError(rw)
+		return analyzer.Report{}, false
+	}
+
+	return scoped, true
+}
+
+// viewer returns the viewer of the request.
+// When the request has no user identity, the error is written and false is returned.
+func (s *NamespaceScope) viewer(rw http.ResponseWriter, req *http.Request) (*viewer, bool) {
+	user := strings.TrimSpace(req.Header.Get(s.UserHeader))
+	if user == "" {
+		JSONErrorf(rw, http.StatusUnauthorized, "missing user identity in header %q", s.UserHeader)
+		return nil, false
+	}
+
+	var groups []string
+	if s.GroupsHeader != "" {
+		for _, value := range req.Header.Values(s.GroupsHeader) {
+			for group := range strings.SplitSeq(value, ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					groups = append(groups, group)
+				}
+			}
+		}
+	}
+
+	return &viewer{ctx: req.Context(), authorizer: s.Authorizer, user: user, groups: groups, allowed: make(map[string]bool)}, true
+}
+
+// viewer is the user of a request, whose access to each namespace is reviewed once, so
+// that several reports can be filtered.
+type viewer struct {
+	ctx        context.Context
+	authorizer NamespaceAuthorizer
+	user       string
+	groups     []string
+	allowed    map[string]bool
+}
+
+// filter returns the report restricted to the namespaces the viewer is allowed to see.
+func (v *viewer) filter(report analyzer.Report) (analyzer.Report, error) {
+	var unreviewed []string
+	for _, namespace := range reportNamespaces(report) {
+		if _, ok := v.allowed[namespace]; !ok {
+			unreviewed = append(unreviewed, namespace)
+		}
+	}
+
+	if len(unreviewed) > 0 {
+		allowed, err := v.authorizer.AllowedNamespaces(v.ctx, v.user, v.groups, unreviewed)
+		if err != nil {
+			return analyzer.Report{}, err
+		}
+		for _, namespace := range unreviewed {
+			v.allowed[namespace] = allowed[namespace]
+		}
+	}
+
+	return analyzer.FilterReport(report, func(namespace string) bool { return v.allowed[namespace] }), nil
+}
+
+// reportNamespaces returns the namespaces of the report to review the access to: those of
+// the analyzed Ingresses, the unanalyzed ones, and those of the IngressClass topology,
+// which counts the Ingresses of every controller.
+func reportNamespaces(report analyzer.Report) []string {
+	namespaces := slices.Clone(report.UnanalyzedNamespaces)
+	for _, ing := range slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses) {
+		namespaces = append(namespaces, ing.Namespace)
+	}
+	if topology := report.IngressClassTopology; topology != nil {
+		namespaces = slices.AppendSeq(namespaces, maps.Keys(topology.WithoutClassIngressCountByNamespace))
+		for _, ic := range topology.IngressClasses {
+			namespaces = slices.AppendSeq(namespaces, maps.Keys(ic.IngressCountByNamespace))
+		}
+	}
+	slices.Sort(namespaces)
+
+	return slices.Compact(namespaces)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/history"
+)
+
+type fakeHistory struct{}
+
+func (fakeHistory) Save(analyzer.Report) (bool, error) { return true, nil }
+
+func (fakeHistory) List() []history.Summary { return nil }
+
+func (fakeHistory) Get(string) (analyzer.Report, error) {
+	return analyzer.Report{}, history.ErrNotFound
+}
+
+func (fakeHistory) Trend() history.Trend { return history.Trend{} }
+
+func (fakeHistory) FilteredTrend(func(analyzer.Report) (analyzer.Report, error)) (history.Trend, error) {
+	return history.Trend{}, nil
+}
+
+type fakeAuthorizer struct {
+	allowed map[string][]string
+	groups  []string
+}
+
+func (f *fakeAuthorizer) AllowedNamespaces(_ context.Context, user string, groups, namespaces []string) (map[string]bool, error) {
+	f.groups = groups
+
+	allowed := make(map[string]bool)
+	for _, namespace := range namespaces {
+		for _, ns := range f.allowed[user] {
+			if ns == namespace {
+				allowed[namespace] = true
+			}
+		}
+	}
+
+	return allowed, nil
+}
+
+func TestIngresses_Scoped(t *testing.T) {
+	t.Parallel()
+
+	authorizer := &fakeAuthorizer{allowed: map[string][]string{"alice": {"staging"}}}
+
+	h, err := New(nil, nil, nil, &NamespaceScope{Authorizer: authorizer, UserHeader: "X-Forwarded-User", GroupsHeader: "X-Forwarded-Groups"})
+	require.NoError(t, err)
+	h.analyzer = fakeAnalyzer{report: queryReport()}
+
+	rec := httptest.NewRecorder()
+	h.Ingresses(rec, httptest.NewRequest(http.MethodGet, "/api/ingresses?verdict=all", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ingresses?verdict=all", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+	req.Header.Set("X-Forwarded-Groups", "dev, ops")
+
+	rec = httptest.NewRecorder()
+	h.Ingresses(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var page ingressPage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Ingresses, 1)
+	assert.Equal(t, "web", page.Ingresses[0].Name)
+	assert.Equal(t, 1, page.Total)
+	assert.Equal(t, []string{"dev", "ops"}, authorizer.groups)
+}
+
+func TestReport_Scoped(t *testing.T) {
+	t.Parallel()
+
+	store, err := history.Open(t.TempDir())
+	require.NoError(t, err)
+	_, err = store.Save(queryReport())
+	require.NoError(t, err)
+
+	h, err := New(nil, nil, store, &NamespaceScope{Authorizer: &fakeAuthorizer{allowed: map[string][]string{"bob": {"staging"}}}, UserHeader: "X-Forwarded-User"})
+	require.NoError(t, err)
+	h.analyzer = fakeAnalyzer{report: queryReport()}
+
+	req := httptest.NewRequest(http.MethodGet, "/?verdict=all", nil)
+	req.Header.Set("X-Forwarded-User", "bob")
+
+	rec := httptest.NewRecorder()
+	h.Report(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "<strong>api</strong>")
+	assert.Contains(t, rec.Body.String(), "<strong>web</strong>")
+	assert.Contains(t, rec.Body.String(), `href="trend"`)
+
+	// The history summaries only count the Ingresses of the namespaces of the viewer.
+	rec = httptest.NewRecorder()
+	h.Reports(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var summaries []history.Summary
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&summaries))
+	require.Len(t, summaries, 1)
+	assert.Equal(t, 1, summaries[0].IngressCount)
+	assert.Equal(t, 1, summaries[0].UnsupportedIngressCount)
+	assert.Zero(t, summaries[0].CompatibleIngressCount)
+
+	rec = httptest.NewRecorder()
+	h.Trend(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "web")
+	assert.NotContains(t, rec.Body.String(), "shop")
+
+	req.Header.Del("X-Forwarded-User")
+	rec = httptest.NewRecorder()
+	h.Reports(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestScopedReport_Topology(t *testing.T) {
+	t.Parallel()
+
+	report := queryReport()
+	report.IngressClassTopology = &analyzer.IngressClassTopology{
+		IngressClasses: []analyzer.IngressClassReport{
+			{Name: "traefik", Controller: "traefik.io/ingress-controller", IngressCount: 3, IngressCountByNamespace: map[string]int{"dev": 2, "prod": 1}},
+		},
+		WithoutClassIngressCount:            2,
+		WithoutClassIngressCountByNamespace: map[string]int{"dev": 1, "prod": 1},
+	}
+	report.UnanalyzedNamespaces = []string{"dev", "kube-system"}
+	assert.Equal(t, []string{"dev", "kube-system", "prod", "staging"}, reportNamespaces(report))
+
+	// The namespace dev has no Ingress of the NGINX ingress controller, but its other
+	// Ingresses are counted for a viewer allowed in it.
+	scope := &NamespaceScope{Authorizer: &fakeAuthorizer{allowed: map[string][]string{"alice": {"dev"}}}, UserHeader: "X-Forwarded-User"}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-User", "alice")
+
+	scoped, ok := scope.scopedReport(httptest.NewRecorder(), req, report)
+	require.True(t, ok)
+	assert.Zero(t, scoped.IngressCount)
+	require.NotNil(t, scoped.IngressClassTopology)
+	assert.Equal(t, 2, scoped.IngressClassTopology.IngressClasses[0].IngressCount)
+	assert.Equal(t, 1, scoped.IngressClassTopology.WithoutClassIngressCount)
+	assert.Equal(t, []string{"dev"}, scoped.UnanalyzedNamespaces)
+}
@@ -0,0 +1,308 @@
+// Package history persists the successive analysis reports to a directory of
+// JSON files, so that the migration progress can be followed over time.
+package history
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// idTimeFormat names the stored reports so that the lexical order of their
+// IDs is also their chronological order.
+const idTimeFormat = "20060102T150405.000000000Z"
+
+var idRegexp = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}\.[0-9]{9}Z-[0-9a-f]{0,12}$`)
+
+// ErrNotFound is returned when a report is not in the store.
+var ErrNotFound = errors.New("report not found")
+
+// Summary is the summary of a stored report.
+type Summary struct {
+	ID             string    `json:"id"`
+	GenerationDate time.Time `json:"generationDate"`
+	Hash           string    `json:"hash"`
+
+	IngressCount            int `json:"ingressCount"`
+	CompatibleIngressCount  int `json:"compatibleIngressCount"`
+	UnsupportedIngressCount int `json:"unsupportedIngressCount"`
+
+	// UnknownIngressCount is the number of unsupported ingresses having at
+	// least one unknown annotation.
+	UnknownIngressCount int `json:"unknownIngressCount"`
+}
+
+// IngressTrend is the migration progress of a single Ingress.
+type IngressTrend struct {
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	FirstSeen time.Time `json:"firstSeen"`
+
+	// CompatibleSince is the generation date of the first report of the
+	// current streak in which the Ingress is compatible. Nil when the Ingress is
+	// not compatible in the latest report it appears in.
+	CompatibleSince *time.Time `json:"compatibleSince,omitempty"`
+}
+
+// Trend is the evolution of the reports over time.
+type Trend struct {
+	Reports   []Summary      `json:"reports"`
+	Ingresses []IngressTrend `json:"ingresses"`
+}
+
+// record is the in-memory index of a stored report.
+type record struct {
+	summary     Summary
+	compatible  []string
+	unsupported []string
+}
+
+// storedReport is the JSON content of a stored report, with the compatible Ingresses
+// left out of the report JSON, which the trend follows.
+type storedReport struct {
+	analyzer.Report
+
+	CompatibleIngresses []analyzer.IngressReport `json:"compatibleIngresses,omitempty"`
+}
+
+// Store is a directory of JSON reports.
+type Store struct {
+	dir string
+
+	mu      sync.RWMutex
+	records []record
+}
+
+// Open opens the store in dir, creating the directory when it does not exist.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("creating history directory: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading history directory: %w", err)
+	}
+
+	s := &Store{dir: dir}
+
+	for _, dirEntry := range dirEntries {
+		id, ok := strings.CutSuffix(dirEntry.Name(), ".json")
+		if dirEntry.IsDir() || !ok || !idRegexp.MatchString(id) {
+			continue
+		}
+
+		report, err := s.read(id)
+		if err != nil {
+			return nil, err
+		}
+
+		s.records = append(s.records, newRecord(id, report))
+	}
+
+	slices.SortFunc(s.records, func(a, b record) int {
+		return cmp.Compare(a.summary.ID, b.summary.ID)
+	})
+
+	return s, nil
+}
+
+// Save stores the report when its hash differs from the latest stored report.
+// It returns whether the report was stored.
+func (s *Store) Save(report analyzer.Report) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.records) > 0 && s.records[len(s.records)-1].summary.Hash == report.Hash {
+		return false, nil
+	}
+
+	hash := report.Hash
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	id := report.GenerationDate.UTC().Format(idTimeFormat) + "-" + hash
+
+	data, err := json.Marshal(storedReport{Report: report, CompatibleIngresses: report.CompatibleIngresses})
+	if err != nil {
+		return false, fmt.Errorf("marshaling report: %w", err)
+	}
+
+	// Write to a temporary file first so that a crash never leaves a partial report behind.
+	name := filepath.Join(s.dir, id+".json")
+	if err := os.WriteFile(name+".tmp", data, 0o600); err != nil {
+		return false, fmt.Errorf("writing report: %w", err)
+	}
+	if err := os.Rename(name+".tmp", name); err != nil {
+		_ = os.Remove(name + ".tmp")
+		return false, fmt.Errorf("renaming report: %w", err)
+	}
+
+	s.records = append(s.records, newRecord(id, report))
+
+	return true, nil
+}
+
+// List returns the summaries of the stored reports, oldest first.
+func (s *Store) List() []Summary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summaries := make([]Summary, 0, len(s.records))
+	for _, r := range s.records {
+		summaries = append(summaries, r.summary)
+	}
+
+	return summaries
+}
+
+// Get returns the stored report with the given ID.
+func (s *Store) Get(id string) (analyzer.Report, error) {
+	if !idRegexp.MatchString(id) {
+		return analyzer.Report{}, ErrNotFound
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.read(id)
+}
+
+// Trend returns the evolution of the stored reports and the date each Ingress became compatible.
+func (s *Store) Trend() Trend {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return buildTrend(s.records)
+}
+
+// FilteredTrend returns the evolution of the stored reports restricted by filter, for
+// example to the namespaces a viewer is allowed to see.
+func (s *Store) FilteredTrend(filter func(analyzer.Report) (analyzer.Report, error)) (Trend, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]record, 0, len(s.records))
+	for _, r := range s.records {
+		report, err := s.read(r.summary.ID)
+		if err != nil {
+			return Trend{}, err
+		}
+
+		report, err = filter(report)
+		if err != nil {
+			return Trend{}, err
+		}
+
+		// The filtered report has no hash of its own: keep the one of the stored report.
+		filtered := newRecord(r.summary.ID, report)
+		filtered.summary.Hash = r.summary.Hash
+		records = append(records, filtered)
+	}
+
+	return buildTrend(records), nil
+}
+
+// buildTrend returns the evolution of the records, oldest first, and the date each Ingress became compatible.
+func buildTrend(records []record) Trend {
+	trend := Trend{
+		Reports:   make([]Summary, 0, len(records)),
+		Ingresses: make([]IngressTrend, 0),
+	}
+
+	ingresses := make(map[string]*IngressTrend)
+	track := func(key string, date time.Time) *IngressTrend {
+		ing, ok := ingresses[key]
+		if !ok {
+			namespace, name, _ := strings.Cut(key, "/")
+			ing = &IngressTrend{Namespace: namespace, Name: name, FirstSeen: date}
+			ingresses[key] = ing
+		}
+		return ing
+	}
+
+	for _, r := range records {
+		trend.Reports = append(trend.Reports, r.summary)
+
+		date := r.summary.GenerationDate
+		for _, key := range r.compatible {
+			ing := track(key, date)
+			if ing.CompatibleSince == nil {
+				ing.CompatibleSince = &date
+			}
+		}
+		for _, key := range r.unsupported {
+			track(key, date).CompatibleSince = nil
+		}
+	}
+
+	for _, ing := range ingresses {
+		trend.Ingresses = append(trend.Ingresses, *ing)
+	}
+
+	slices.SortFunc(trend.Ingresses, func(a, b IngressTrend) int {
+		if c := cmp.Compare(a.Namespace, b.Namespace); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+
+	return trend
+}
+
+func (s *Store) read(id string) (analyzer.Report, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, id+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return analyzer.Report{}, ErrNotFound
+	}
+	if err != nil {
+		return analyzer.Report{}, fmt.Errorf("reading report: %w", err)
+	}
+
+	var stored storedReport
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return analyzer.Report{}, fmt.Errorf("unmarshaling report %s: %w", id, err)
+	}
+
+	report := stored.Report
+	report.CompatibleIngresses = stored.CompatibleIngresses
+
+	return report, nil
+}
+
+func newRecord(id string, report analyzer.Report) record {
+	r := record{
+		summary: Summary{
+			ID:                      id,
+			GenerationDate:          report.GenerationDate,
+			Hash:                    report.Hash,
+			IngressCount:            report.IngressCount,
+			CompatibleIngressCount:  report.CompatibleIngressCount,
+			UnsupportedIngressCount: report.UnsupportedIngressCount,
+		},
+	}
+
+	for _, ing := range report.CompatibleIngresses {
+		r.compatible = append(r.compatible, ing.Namespace+"/"+ing.Name)
+	}
+
+	for _, ing := range report.UnsupportedIngresses {
+		r.unsupported = append(r.unsupported, ing.Namespace+"/"+ing.Name)
+
+		if len(ing.UnknownAnnotations) > 0 {
+			r.summary.UnknownIngressCount++
+		}
+	}
+
+	return r
+}
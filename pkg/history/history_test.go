@@ -0,0 +1,134 @@
+package history
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	store, err := Open(dir)
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 5, 25, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+	day3 := day2.Add(24 * time.Hour)
+
+	reports := []analyzer.Report{
+		{
+			GenerationDate:          day1,
+			Hash:                    "aaaaaaaaaaaaaaaa",
+			IngressCount:            2,
+			CompatibleIngressCount:  1,
+			UnsupportedIngressCount: 1,
+			CompatibleIngresses:     []analyzer.IngressReport{{Namespace: "prod", Name: "web"}},
+			UnsupportedIngresses: []analyzer.IngressReport{{
+				Namespace:          "prod",
+				Name:               "api",
+				UnknownAnnotations: []string{"nginx.ingress.kubernetes.io/totally-made-up"},
+			}},
+		},
+		// Same hash: not stored.
+		{GenerationDate: day1.Add(time.Hour), Hash: "aaaaaaaaaaaaaaaa"},
+		{
+			GenerationDate:         day2,
+			Hash:                   "bbbbbbbbbbbbbbbb",
+			IngressCount:           2,
+			CompatibleIngressCount: 2,
+			CompatibleIngresses: []analyzer.IngressReport{
+				{Namespace: "prod", Name: "api"},
+				{Namespace: "prod", Name: "web"},
+			},
+		},
+		{
+			GenerationDate:          day3,
+			Hash:                    "cccccccccccccccc",
+			IngressCount:            2,
+			CompatibleIngressCount:  1,
+			UnsupportedIngressCount: 1,
+			CompatibleIngresses:     []analyzer.IngressReport{{Namespace: "prod", Name: "api"}},
+			UnsupportedIngresses:    []analyzer.IngressReport{{Namespace: "prod", Name: "web"}},
+		},
+	}
+
+	var saved int
+	for _, report := range reports {
+		ok, err := store.Save(report)
+		require.NoError(t, err)
+		if ok {
+			saved++
+		}
+	}
+	assert.Equal(t, 3, saved)
+
+	// The store is reloaded from disk.
+	store, err = Open(dir)
+	require.NoError(t, err)
+
+	summaries := store.List()
+	require.Len(t, summaries, 3)
+	assert.Equal(t, "20260525T100000.000000000Z-aaaaaaaaaaaa", summaries[0].ID)
+	assert.Equal(t, 1, summaries[0].UnknownIngressCount)
+	assert.Equal(t, 2, summaries[1].CompatibleIngressCount)
+
+	report, err := store.Get(summaries[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "bbbbbbbbbbbbbbbb", report.Hash)
+	// The compatible Ingresses, left out of the report JSON, are stored along with it.
+	assert.Len(t, report.CompatibleIngresses, 2)
+
+	_, err = store.Get("../../etc/passwd")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	trend := store.Trend()
+	require.Len(t, trend.Reports, 3)
+	assert.Equal(t, []IngressTrend{
+		// Became compatible on day 2.
+		{Namespace: "prod", Name: "api", FirstSeen: day1, CompatibleSince: &day2},
+		// Compatible on day 1, regressed on day 3.
+		{Namespace: "prod", Name: "web", FirstSeen: day1},
+	}, trend.Ingresses)
+}
+
+func TestStore_FilteredTrend(t *testing.T) {
+	t.Parallel()
+
+	store, err := Open(t.TempDir())
+	require.NoError(t, err)
+
+	day1 := time.Date(2026, 5, 25, 10, 0, 0, 0, time.UTC)
+	_, err = store.Save(analyzer.Report{
+		GenerationDate:          day1,
+		Hash:                    "aaaaaaaaaaaaaaaa",
+		IngressCount:            2,
+		CompatibleIngressCount:  1,
+		UnsupportedIngressCount: 1,
+		CompatibleIngresses:     []analyzer.IngressReport{{Namespace: "prod", Name: "web"}},
+		UnsupportedIngresses:    []analyzer.IngressReport{{Namespace: "staging", Name: "api"}},
+	})
+	require.NoError(t, err)
+
+	trend, err := store.FilteredTrend(func(report analyzer.Report) (analyzer.Report, error) {
+		return analyzer.FilterReport(report, func(namespace string) bool { return namespace == "prod" }), nil
+	})
+	require.NoError(t, err)
+	require.Len(t, trend.Reports, 1)
+	assert.Equal(t, "aaaaaaaaaaaaaaaa", trend.Reports[0].Hash)
+	assert.Equal(t, 1, trend.Reports[0].IngressCount)
+	assert.Equal(t, 1, trend.Reports[0].CompatibleIngressCount)
+	assert.Zero(t, trend.Reports[0].UnsupportedIngressCount)
+	assert.Equal(t, []IngressTrend{{Namespace: "prod", Name: "web", FirstSeen: day1, CompatibleSince: &day1}}, trend.Ingresses)
+
+	_, err = store.FilteredTrend(func(analyzer.Report) (analyzer.Report, error) {
+		return analyzer.Report{}, errors.New("boom")
+	})
+	require.EqualError(t, err, "boom")
+}
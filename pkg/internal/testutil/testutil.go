@@ -0,0 +1,61 @@
+// Package testutil provides the Ingress fixtures and the golden file assertion
+// shared by the tests.
+package testutil
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Ingress returns the Ingress of the namespace with the annotations and the spec.
+func Ingress(namespace, name string, annotations map[string]string, spec netv1.IngressSpec) *netv1.Ingress {
+	return &netv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Annotations: annotations},
+		Spec:       spec,
+	}
+}
+
+// Rule returns the rule of the host with the HTTP paths.
+func Rule(host string, paths ...netv1.HTTPIngressPath) netv1.IngressRule {
+	return netv1.IngressRule{
+		Host:             host,
+		IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{Paths: paths}},
+	}
+}
+
+// Path returns the path to the port of the Service.
+func Path(path string, pathType netv1.PathType, service string, port int32) netv1.HTTPIngressPath {
+	return netv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &pathType,
+		Backend: netv1.IngressBackend{Service: &netv1.IngressServiceBackend{
+			Name: service,
+			Port: netv1.ServiceBackendPort{Number: port},
+		}},
+	}
+}
+
+// AssertGolden asserts that got is the content of the golden file of the testdata
+// directory, which is written first when the tests run with -update.
+func AssertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
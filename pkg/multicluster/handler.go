@@ -0,0 +1,33 @@
+package multicluster
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/rs/zerolog/log"
+)
+
+// Handler returns the HTTP handler serving the combined report of the clusters, as an
+// HTML page and as JSON. The reports are generated again on every request.
+func Handler(clusters []Cluster) http.Handler {
+	router := httprouter.New()
+	router.HandlerFunc(http.MethodGet, "/", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+
+		if err := WriteHTML(rw, Combine(clusters)); err != nil {
+			log.Err(err).Msg("Error while writing the fleet report")
+		}
+	})
+	router.HandlerFunc(http.MethodGet, "/api/report", func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusOK)
+
+		if err := json.NewEncoder(rw).Encode(Combine(clusters)); err != nil {
+			log.Err(err).Msg("Error while encoding the fleet report")
+		}
+	})
+
+	return router
+}
@@ -0,0 +1,79 @@
+// Package multicluster analyzes several clusters in one run, one per kubeconfig context,
+// and combines their reports into a fleet report with a per-cluster breakdown.
+package multicluster
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// StartFunc creates and starts the analyzer of the cluster of the kubeconfig context.
+// The informers of the analyzer run until ctx is done.
+type StartFunc func(ctx context.Context, kubeContext string) (*analyzer.Analyzer, error)
+
+// Cluster is a cluster of the run, with its analyzer or the error preventing its analysis.
+type Cluster struct {
+	Context  string
+	Analyzer *analyzer.Analyzer
+	Err      error
+}
+
+// Start starts the analyzers of the contexts concurrently, and returns the clusters in
+// the order of the contexts. A cluster whose analyzer fails to start, or to sync within
+// the timeout when greater than zero, is returned with its error without affecting the others.
+func Start(ctx context.Context, kubeContexts []string, timeout time.Duration, start StartFunc) []Cluster {
+	clusters := make([]Cluster, len(kubeContexts))
+
+	var wg sync.WaitGroup
+	for i, kubeContext := range kubeContexts {
+		clusters[i].Context = kubeContext
+		wg.Go(func() {
+			clusters[i].Analyzer, clusters[i].Err = startCluster(ctx, kubeContext, timeout, start)
+		})
+	}
+	wg.Wait()
+
+	return clusters
+}
+
+func startCluster(ctx context.Context, kubeContext string, timeout time.Duration, start StartFunc) (*analyzer.Analyzer, error) {
+	// The informers of a cluster which does not sync in time are stopped.
+	clusterCtx, cancel := context.WithCancel(ctx)
+
+	type result struct {
+		analyzr *analyzer.Analyzer
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		analyzr, err := start(clusterCtx, kubeContext)
+		done <- result{analyzr: analyzr, err: err}
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			cancel()
+			return nil, res.err
+		}
+		context.AfterFunc(ctx, cancel)
+		return res.analyzr, nil
+	case <-expired:
+		cancel()
+		return nil, fmt.Errorf("timed out after %s waiting for the analyzer to start", timeout)
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+}
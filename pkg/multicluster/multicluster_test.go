@@ -0,0 +1,206 @@
+package multicluster
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestStart(t *testing.T) {
+	t.Parallel()
+
+	analyzr := &analyzer.Analyzer{}
+	start := func(ctx context.Context, kubeContext string) (*analyzer.Analyzer, error) {
+		switch kubeContext {
+		case "unreachable":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case "forbidden":
+			return nil, errors.New("forbidden")
+		default:
+			return analyzr, nil
+		}
+	}
+
+	clusters := Start(t.Context(), []string{"prod", "unreachable", "forbidden"}, 50*time.Millisecond, start)
+	require.Len(t, clusters, 3)
+
+	assert.Equal(t, "prod", clusters[0].Context)
+	assert.Same(t, analyzr, clusters[0].Analyzer)
+	require.NoError(t, clusters[0].Err)
+
+	assert.Equal(t, "unreachable", clusters[1].Context)
+	assert.Nil(t, clusters[1].Analyzer)
+	require.EqualError(t, clusters[1].Err, "timed out after 50ms waiting for the analyzer to start")
+
+	assert.Equal(t, "forbidden", clusters[2].Context)
+	assert.Nil(t, clusters[2].Analyzer)
+	require.EqualError(t, clusters[2].Err, "forbidden")
+}
+
+func TestNewReport(t *testing.T) {
+	t.Parallel()
+
+	report := fleetReport()
+
+	assert.Equal(t, 3, report.ClusterCount)
+	assert.Equal(t, 2, report.AnalyzedClusterCount)
+	assert.Equal(t, 1, report.FailedClusterCount)
+	assert.Equal(t, 10, report.IngressCount)
+	assert.Equal(t, 7, report.CompatibleIngressCount)
+	assert.InDelta(t, 70.0, report.CompatibleIngressPercentage, 0.01)
+	assert.Equal(t, 3, report.UnsupportedIngressCount)
+	assert.InDelta(t, 30.0, report.UnsupportedIngressPercentage, 0.01)
+	assert.Equal(t, 5, report.CompatibleV36IngressCount)
+
+	assert.Equal(t, []analyzer.BlockingAnnotation{
+		{Annotation: "nginx.ingress.kubernetes.io/configuration-snippet", Kind: analyzer.BlockingUnsupported, IngressCount: 3, ClusterCount: 2},
+		{Annotation: "nginx.ingress.kubernetes.io/custom-thing", Kind: analyzer.BlockingUnknown, IngressCount: 1, ClusterCount: 1},
+	}, report.BlockingAnnotations)
+}
+
+func TestNewReport_Empty(t *testing.T) {
+	t.Parallel()
+
+	report := NewReport(nil)
+
+	assert.Zero(t, report.ClusterCount)
+	assert.Zero(t, report.CompatibleIngressPercentage)
+	assert.NotNil(t, report.BlockingAnnotations)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		format  string
+		summary bool
+		golden  string
+	}{
+		{name: "json", format: FormatJSON, golden: "fleet.json"},
+		{name: "markdown full", format: FormatMarkdown, golden: "fleet.full.md"},
+		{name: "markdown summary", format: FormatMarkdown, summary: true, golden: "fleet.summary.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, Write(&buf, fleetReport(), tt.format, tt.summary))
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, buf.Bytes(), 0o600))
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.Error(t, Write(&buf, fleetReport(), "yaml", false))
+	assert.Empty(t, buf.String())
+}
+
+func TestWriteHTML(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(&buf, fleetReport()))
+
+	assert.Contains(t, buf.String(), "prod")
+	assert.Contains(t, buf.String(), "connection refused")
+	assert.Contains(t, buf.String(), "nginx.ingress.kubernetes.io/configuration-snippet")
+}
+
+func fleetReport() Report {
+	report := NewReport([]ClusterReport{
+		{
+			Context: "prod",
+			Report: &analyzer.Report{
+				IngressCount:                 6,
+				CompatibleIngressCount:       4,
+				CompatibleIngressPercentage:  66.67,
+				VanillaIngressCount:          2,
+				SupportedIngressCount:        2,
+				UnsupportedIngressCount:      2,
+				UnsupportedIngressPercentage: 33.33,
+				CompatibleV36IngressCount:    3,
+				CompatibleV37IngressCount:    1,
+				UnsupportedIngressAnnotations: map[string]int{
+					"nginx.ingress.kubernetes.io/configuration-snippet": 2,
+				},
+				UnknownIngressAnnotations: map[string]int{
+					"nginx.ingress.kubernetes.io/custom-thing": 1,
+				},
+				UnsupportedIngresses: []analyzer.IngressReport{
+					{
+						Namespace:              "shop",
+						Name:                   "cart",
+						IngressClassName:       "nginx",
+						UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/configuration-snippet"},
+						UnknownAnnotations:     []string{"nginx.ingress.kubernetes.io/custom-thing"},
+					},
+					{
+						Namespace:              "shop",
+						Name:                   "checkout",
+						IngressClassName:       "nginx",
+						UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/configuration-snippet"},
+					},
+				},
+			},
+		},
+		{
+			Context: "staging",
+			Report: &analyzer.Report{
+				IngressCount:                 4,
+				CompatibleIngressCount:       3,
+				CompatibleIngressPercentage:  75,
+				VanillaIngressCount:          3,
+				UnsupportedIngressCount:      1,
+				UnsupportedIngressPercentage: 25,
+				CompatibleV36IngressCount:    2,
+				CompatibleHubIngressCount:    1,
+				UnsupportedIngressAnnotations: map[string]int{
+					"nginx.ingress.kubernetes.io/configuration-snippet": 1,
+				},
+				UnsupportedIngresses: []analyzer.IngressReport{
+					{
+						Namespace:              "default",
+						Name:                   "legacy",
+						IngressClassName:       "nginx",
+						UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/configuration-snippet"},
+					},
+				},
+			},
+		},
+		{
+			Context: "edge",
+			Error:   "dial tcp 10.0.0.1:6443: connect: connection refused",
+		},
+	})
+
+	report.GenerationDate = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report.Version = "test"
+
+	return report
+}
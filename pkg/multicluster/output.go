@@ -0,0 +1,96 @@
+package multicluster
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+// Supported report formats.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+var (
+	//go:embed multicluster.md.tmpl
+	markdownTemplate string
+
+	//go:embed multicluster.html
+	htmlTemplate string
+)
+
+// Write writes the report in the given format. summary omits the per-Ingress detail
+// of the Markdown report.
+func Write(w io.Writer, report Report, format string, summary bool) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("encoding report as JSON: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		return writeMarkdown(w, report, summary)
+	default:
+		return fmt.Errorf("unknown format %q (must be %q or %q)", format, FormatJSON, FormatMarkdown)
+	}
+}
+
+// markdownView is the report handed to the Markdown template.
+type markdownView struct {
+	Report
+	Summary bool
+}
+
+func writeMarkdown(w io.Writer, report Report, summary bool) error {
+	tmpl, err := template.New("multicluster.md").Funcs(template.FuncMap{
+		"pct": func(pct float64) string {
+			return fmt.Sprintf("%.1f%%", pct)
+		},
+		"share": func(count, total int) float64 {
+			if total == 0 {
+				return 0
+			}
+			return float64(count) / float64(total) * 100
+		},
+		// cell escapes the pipes of a table cell.
+		"cell": func(s string) string {
+			return strings.ReplaceAll(s, "|", `\|`)
+		},
+		"fixes": func(ing analyzer.IngressReport) string {
+			return strings.Join(slices.Concat(ing.UnsupportedAnnotations, ing.UnknownAnnotations), ", ")
+		},
+	}).Parse(markdownTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, markdownView{Report: report, Summary: summary}); err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
+
+// WriteHTML writes the report as an HTML page.
+func WriteHTML(w io.Writer, report Report) error {
+	tmpl, err := htmltemplate.New("multicluster.html").Parse(htmlTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing HTML template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("executing HTML template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package multicluster
+
+import (
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"github.com/traefik/ingress-nginx-migration/pkg/version"
+)
+
+// Report is the combined report of the clusters of a run, with the fleet totals.
+type Report struct {
+	GenerationDate time.Time `json:"generationDate"`
+	Version        string    `json:"version"`
+
+	ClusterCount         int `json:"clusterCount"`
+	AnalyzedClusterCount int `json:"analyzedClusterCount"`
+	FailedClusterCount   int `json:"failedClusterCount"`
+
+	analyzer.IngressTotals
+
+	CompatibleV36IngressCount int `json:"compatibleV36IngressCount"`
+	CompatibleV37IngressCount int `json:"compatibleV37IngressCount"`
+	CompatibleHubIngressCount int `json:"compatibleHubIngressCount"`
+
+	// BlockingAnnotations lists the annotations preventing the migration across the
+	// clusters, most used first.
+	BlockingAnnotations []analyzer.BlockingAnnotation `json:"blockingAnnotations"`
+
+	Clusters []ClusterReport `json:"clusters"`
+}
+
+// ClusterReport is the report of a cluster, or the error preventing its analysis.
+type ClusterReport struct {
+	Context string           `json:"context"`
+	Error   string           `json:"error,omitempty"`
+	Report  *analyzer.Report `json:"report,omitempty"`
+}
+
+// Combine generates the reports of the clusters and combines them.
+func Combine(clusters []Cluster) Report {
+	reports := make([]ClusterReport, 0, len(clusters))
+	for _, cluster := range clusters {
+		clusterReport := ClusterReport{Context: cluster.Context}
+
+		switch {
+		case cluster.Err != nil:
+			clusterReport.Error = cluster.Err.Error()
+		default:
+			if err := cluster.Analyzer.GenerateReport(); err != nil {
+				clusterReport.Error = err.Error()
+				break
+			}
+			report := cluster.Analyzer.Report()
+			clusterReport.Report = &report
+		}
+
+		reports = append(reports, clusterReport)
+	}
+
+	return NewReport(reports)
+}
+
+// NewReport returns the combined report of the cluster reports, with the fleet totals
+// of the analyzed clusters.
+func NewReport(clusters []ClusterReport) Report {
+	combined := Report{
+		GenerationDate: time.Now().UTC(),
+		Version:        version.Version,
+		ClusterCount:   len(clusters),
+		Clusters:       clusters,
+	}
+
+	var reports []analyzer.Report
+	for _, cluster := range clusters {
+		report := cluster.Report
+		if report == nil {
+			combined.FailedClusterCount++
+			continue
+		}
+		combined.AnalyzedClusterCount++
+		reports = append(reports, *report)
+
+		combined.CompatibleV36IngressCount += report.CompatibleV36IngressCount
+		combined.CompatibleV37IngressCount += report.CompatibleV37IngressCount
+		combined.CompatibleHubIngressCount += report.CompatibleHubIngressCount
+	}
+
+	combined.IngressTotals, combined.BlockingAnnotations = analyzer.AggregateReports(reports)
+
+	return combined
+}
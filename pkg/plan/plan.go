@@ -0,0 +1,295 @@
+// Package plan builds a cutover plan switching the compatible Ingresses from the
+// NGINX ingress class to the Traefik one, in waves, with the patches applying
+// and rolling back each wave.
+package plan
+
+import (
+	"cmp"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	"sigs.k8s.io/yaml"
+)
+
+// Criteria grouping the Ingresses into waves.
+const (
+	GroupByNamespace = "namespace"
+	GroupBySize      = "size"
+	GroupByVersion   = "version"
+)
+
+// Fields an Ingress class is set with.
+const (
+	FieldIngressClassName = "spec.ingressClassName"
+	FieldAnnotation       = "annotation"
+)
+
+const annotationIngressClass = "kubernetes.io/ingress.class"
+
+//go:embed plan.md.tmpl
+var markdownTemplate string
+
+// Options configures the plan.
+type Options struct {
+	// GroupBy is the criterion grouping the Ingresses into waves.
+	GroupBy string
+	// WaveSize is the maximum number of Ingresses of a wave. When 0, waves are not split.
+	WaveSize int
+	// IngressClass is the Traefik class the Ingresses are switched to.
+	IngressClass string
+}
+
+// Plan is a cutover plan.
+type Plan struct {
+	GenerationDate time.Time
+	Version        string
+	Options        Options
+
+	Waves []Wave
+
+	// Excluded are the unsupported Ingresses, which are never part of a wave.
+	Excluded []analyzer.IngressReport
+}
+
+// Wave is a set of Ingresses switched together.
+type Wave struct {
+	Number int
+	// Group is the namespace or the minimum Traefik version of the Ingresses,
+	// depending on the grouping criterion.
+	Group     string
+	Ingresses []Cutover
+}
+
+// Cutover is the switch of an Ingress to the Traefik class.
+type Cutover struct {
+	Namespace      string
+	Name           string
+	Verdict        string
+	TraefikVersion string
+
+	// Field is the field the class is set with, as the NGINX class was.
+	Field string
+
+	// Apply and Rollback are the JSON merge patches of the Ingress, as YAML.
+	Apply    []byte
+	Rollback []byte
+}
+
+// New builds the cutover plan of the Ingresses of the report.
+func New(report analyzer.Report, opts Options) (Plan, error) {
+	if opts.IngressClass == "" {
+		return Plan{}, fmt.Errorf("the Traefik ingress class is required")
+	}
+	if opts.WaveSize < 0 {
+		return Plan{}, fmt.Errorf("invalid wave size %d", opts.WaveSize)
+	}
+
+	var groups [][]analyzer.IngressReport
+	switch opts.GroupBy {
+	case GroupByNamespace:
+		groups = groupBy(report.CompatibleIngresses, func(ing analyzer.IngressReport) string { return ing.Namespace }, cmp.Compare[string])
+	case GroupByVersion:
+		groups = groupBy(report.CompatibleIngresses, analyzer.IngressReport.TraefikVersion, compareVersions)
+	case GroupBySize:
+		if opts.WaveSize == 0 {
+			return Plan{}, fmt.Errorf("a wave size is required to group by %s", GroupBySize)
+		}
+		groups = [][]analyzer.IngressReport{bySimplicity(report.CompatibleIngresses)}
+	default:
+		return Plan{}, fmt.Errorf("unknown grouping %q (must be %q, %q or %q)", opts.GroupBy, GroupByNamespace, GroupBySize, GroupByVersion)
+	}
+
+	plan := Plan{
+		GenerationDate: report.GenerationDate,
+		Version:        report.Version,
+		Options:        opts,
+		Excluded:       report.UnsupportedIngresses,
+	}
+
+	for _, group := range groups {
+		var label string
+		switch opts.GroupBy {
+		case GroupByNamespace:
+			label = group[0].Namespace
+		case GroupByVersion:
+			label = group[0].TraefikVersion()
+		}
+
+		for chunk := range chunks(group, opts.WaveSize) {
+			wave := Wave{Number: len(plan.Waves) + 1, Group: label}
+			for _, ing := range chunk {
+				cutover, err := newCutover(ing, opts.IngressClass)
+				if err != nil {
+					return Plan{}, err
+				}
+				wave.Ingresses = append(wave.Ingresses, cutover)
+			}
+			plan.Waves = append(plan.Waves, wave)
+		}
+	}
+
+	return plan, nil
+}
+
+// groupBy groups the Ingresses by key, in key order. The Ingresses keep their order.
+func groupBy(ingresses []analyzer.IngressReport, key func(analyzer.IngressReport) string, compare func(a, b string) int) [][]analyzer.IngressReport {
+	index := make(map[string][]analyzer.IngressReport)
+	var keys []string
+	for _, ing := range ingresses {
+		k := key(ing)
+		if _, ok := index[k]; !ok {
+			keys = append(keys, k)
+		}
+		index[k] = append(index[k], ing)
+	}
+	slices.SortFunc(keys, compare)
+
+	groups := make([][]analyzer.IngressReport, 0, len(keys))
+	for _, k := range keys {
+		groups = append(groups, index[k])
+	}
+	return groups
+}
+
+// compareVersions orders the minimum Traefik versions, from the oldest to Traefik Hub.
+func compareVersions(a, b string) int {
+	versions := []string{analyzer.TraefikV36, analyzer.TraefikV37, analyzer.TraefikHub}
+	return cmp.Compare(slices.Index(versions, a), slices.Index(versions, b))
+}
+
+// bySimplicity orders the Ingresses from the simplest to switch, without NGINX
+// annotations, to the ones with the most supported annotations.
+func bySimplicity(ingresses []analyzer.IngressReport) []analyzer.IngressReport {
+	sorted := slices.Clone(ingresses)
+	slices.SortStableFunc(sorted, func(a, b analyzer.IngressReport) int {
+		return cmp.Compare(len(a.SupportedAnnotations), len(b.SupportedAnnotations))
+	})
+	return sorted
+}
+
+// chunks splits the Ingresses into chunks of at most size Ingresses, or a single chunk when size is 0.
+func chunks(ingresses []analyzer.IngressReport, size int) func(yield func([]analyzer.IngressReport) bool) {
+	if size == 0 {
+		size = max(len(ingresses), 1)
+	}
+	return slices.Chunk(ingresses, size)
+}
+
+// newCutover returns the patches switching the Ingress class with the field
+// selecting the NGINX class, and restoring it. The Ingresses without class get an
+// IngressClass, which the rollback removes, instead of adding a class they never had.
+func newCutover(ing analyzer.IngressReport, ingressClass string) (Cutover, error) {
+	cutover := Cutover{
+		Namespace:      ing.Namespace,
+		Name:           ing.Name,
+		Verdict:        ing.Verdict(),
+		TraefikVersion: ing.TraefikVersion(),
+	}
+
+	var apply, rollback map[string]any
+	switch {
+	case ing.ClassLess:
+		cutover.Field = FieldIngressClassName
+		apply = map[string]any{"spec": map[string]any{"ingressClassName": ingressClass}}
+		rollback = map[string]any{"spec": map[string]any{"ingressClassName": nil}}
+
+	case ing.IngressClassName != "" && ing.IngressClassName == ing.Class:
+		cutover.Field = FieldIngressClassName
+		apply = map[string]any{"spec": map[string]any{"ingressClassName": ingressClass}}
+		rollback = map[string]any{"spec": map[string]any{"ingressClassName": ing.Class}}
+
+	default:
+		cutover.Field = FieldAnnotation
+		apply = map[string]any{"metadata": map[string]any{"annotations": map[string]any{annotationIngressClass: ingressClass}}}
+		rollback = map[string]any{"metadata": map[string]any{"annotations": map[string]any{annotationIngressClass: ing.Class}}}
+	}
+
+	var err error
+	if cutover.Apply, err = yaml.Marshal(apply); err != nil {
+		return Cutover{}, fmt.Errorf("marshaling patch of Ingress %s/%s: %w", ing.Namespace, ing.Name, err)
+	}
+	if cutover.Rollback, err = yaml.Marshal(rollback); err != nil {
+		return Cutover{}, fmt.Errorf("marshaling rollback patch of Ingress %s/%s: %w", ing.Namespace, ing.Name, err)
+	}
+
+	return cutover, nil
+}
+
+// ApplyFile is the path of the patch switching the Ingress, relative to the plan directory.
+func (w Wave) ApplyFile(c Cutover) string {
+	return filepath.Join(w.dir(), "apply", c.Namespace+"_"+c.Name+".yaml")
+}
+
+// RollbackFile is the path of the patch restoring the Ingress, relative to the plan directory.
+func (w Wave) RollbackFile(c Cutover) string {
+	return filepath.Join(w.dir(), "rollback", c.Namespace+"_"+c.Name+".yaml")
+}
+
+func (w Wave) dir() string {
+	return "wave-" + strconv.Itoa(w.Number)
+}
+
+// Write writes the plan Markdown and the patches of the waves to the directory.
+func (p Plan) Write(dir string) error {
+	for _, wave := range p.Waves {
+		for _, cutover := range wave.Ingresses {
+			if err := writeFile(filepath.Join(dir, wave.ApplyFile(cutover)), cutover.Apply); err != nil {
+				return err
+			}
+			if err := writeFile(filepath.Join(dir, wave.RollbackFile(cutover)), cutover.Rollback); err != nil {
+				return err
+			}
+		}
+	}
+
+	file, err := os.Create(filepath.Join(dir, "plan.md"))
+	if err != nil {
+		return fmt.Errorf("creating plan: %w", err)
+	}
+
+	if err := WriteMarkdown(file, p); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("closing plan: %w", err)
+	}
+
+	return nil
+}
+
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing patch: %w", err)
+	}
+
+	return nil
+}
+
+// WriteMarkdown writes the plan as Markdown, listing the commands applying and
+// rolling back each wave from the plan directory.
+func WriteMarkdown(w io.Writer, p Plan) error {
+	tmpl, err := template.New("plan.md").Parse(markdownTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, p); err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package plan
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func testReport() analyzer.Report {
+	v37 := []analyzer.AnnotationInfo{{Name: "nginx.ingress.kubernetes.io/rewrite-target", Version: "v3.7"}}
+	v36 := []analyzer.AnnotationInfo{{Name: "nginx.ingress.kubernetes.io/ssl-redirect", Version: "v3.6"}}
+
+	return analyzer.Report{
+		GenerationDate: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Version:        "v1.0.0",
+		CompatibleIngresses: []analyzer.IngressReport{
+			{Namespace: "api", Name: "backend", IngressClassName: "nginx", Class: "nginx", SupportedAnnotations: v37},
+			{Namespace: "api", Name: "docs", Class: "nginx"},
+			{Namespace: "shop", Name: "cart", Class: analyzer.WithoutClass, ClassLess: true, SupportedAnnotations: v36},
+			{Namespace: "shop", Name: "web", IngressClassName: "nginx", Class: "nginx"},
+		},
+		UnsupportedIngresses: []analyzer.IngressReport{
+			{
+				Namespace:              "shop",
+				Name:                   "legacy",
+				Class:                  "nginx",
+				UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/limit-connections"},
+				UnknownAnnotations:     []string{"nginx.ingress.kubernetes.io/made-up"},
+			},
+		},
+	}
+}
+
+func TestNew_Grouping(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		opts   Options
+		groups []string
+		waves  [][]string
+	}{
+		{
+			desc:   "by namespace",
+			opts:   Options{GroupBy: GroupByNamespace},
+			groups: []string{"api", "shop"},
+			waves:  [][]string{{"api/backend", "api/docs"}, {"shop/cart", "shop/web"}},
+		},
+		{
+			desc:   "by namespace with a wave size",
+			opts:   Options{GroupBy: GroupByNamespace, WaveSize: 1},
+			groups: []string{"api", "api", "shop", "shop"},
+			waves:  [][]string{{"api/backend"}, {"api/docs"}, {"shop/cart"}, {"shop/web"}},
+		},
+		{
+			desc:   "by version",
+			opts:   Options{GroupBy: GroupByVersion},
+			groups: []string{"v3.6", "v3.7"},
+			waves:  [][]string{{"api/docs", "shop/cart", "shop/web"}, {"api/backend"}},
+		},
+		{
+			desc:   "by size, simplest first",
+			opts:   Options{GroupBy: GroupBySize, WaveSize: 3},
+			groups: []string{"", ""},
+			waves:  [][]string{{"api/docs", "shop/web", "api/backend"}, {"shop/cart"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.opts.IngressClass = "traefik"
+
+			plan, err := New(testReport(), test.opts)
+			require.NoError(t, err)
+
+			var (
+				groups []string
+				waves  [][]string
+			)
+			for i, wave := range plan.Waves {
+				assert.Equal(t, i+1, wave.Number)
+
+				var names []string
+				for _, cutover := range wave.Ingresses {
+					names = append(names, cutover.Namespace+"/"+cutover.Name)
+				}
+				groups = append(groups, wave.Group)
+				waves = append(waves, names)
+			}
+
+			assert.Equal(t, test.groups, groups)
+			assert.Equal(t, test.waves, waves)
+
+			// The unsupported Ingresses are never part of a wave.
+			require.Len(t, plan.Excluded, 1)
+			assert.Equal(t, "legacy", plan.Excluded[0].Name)
+		})
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(testReport(), Options{GroupBy: GroupBySize, IngressClass: "traefik"})
+	require.Error(t, err)
+
+	_, err = New(testReport(), Options{GroupBy: "cluster", IngressClass: "traefik"})
+	require.Error(t, err)
+
+	_, err = New(testReport(), Options{GroupBy: GroupByNamespace})
+	require.Error(t, err)
+}
+
+func TestNew_Patches(t *testing.T) {
+	t.Parallel()
+
+	plan, err := New(testReport(), Options{GroupBy: GroupByNamespace, IngressClass: "traefik"})
+	require.NoError(t, err)
+
+	cutovers := map[string]Cutover{}
+	for _, wave := range plan.Waves {
+		for _, cutover := range wave.Ingresses {
+			cutovers[cutover.Namespace+"/"+cutover.Name] = cutover
+		}
+	}
+
+	tests := []struct {
+		ingress  string
+		field    string
+		apply    string
+		rollback string
+	}{
+		{
+			ingress:  "api/backend",
+			field:    FieldIngressClassName,
+			apply:    "spec:\n  ingressClassName: traefik\n",
+			rollback: "spec:\n  ingressClassName: nginx\n",
+		},
+		{
+			ingress:  "api/docs",
+			field:    FieldAnnotation,
+			apply:    "metadata:\n  annotations:\n    kubernetes.io/ingress.class: traefik\n",
+			rollback: "metadata:\n  annotations:\n    kubernetes.io/ingress.class: nginx\n",
+		},
+		{
+			ingress:  "shop/cart",
+			field:    FieldIngressClassName,
+			apply:    "spec:\n  ingressClassName: traefik\n",
+			rollback: "spec:\n  ingressClassName: null\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ingress, func(t *testing.T) {
+			t.Parallel()
+
+			cutover, ok := cutovers[test.ingress]
+			require.True(t, ok)
+
+			assert.Equal(t, test.field, cutover.Field)
+			assert.Equal(t, test.apply, string(cutover.Apply))
+			assert.Equal(t, test.rollback, string(cutover.Rollback))
+		})
+	}
+}
+
+func TestNewCutover_ClassLess(t *testing.T) {
+	t.Parallel()
+
+	// An Ingress without class keeps none on rollback, even reported with a class name.
+	cutover, err := newCutover(analyzer.IngressReport{Namespace: "shop", Name: "cart", Class: "nginx", ClassLess: true}, "traefik")
+	require.NoError(t, err)
+
+	assert.Equal(t, FieldIngressClassName, cutover.Field)
+	assert.Equal(t, "spec:\n  ingressClassName: traefik\n", string(cutover.Apply))
+	assert.Equal(t, "spec:\n  ingressClassName: null\n", string(cutover.Rollback))
+}
+
+func TestPlan_Write(t *testing.T) {
+	t.Parallel()
+
+	plan, err := New(testReport(), Options{GroupBy: GroupByNamespace, WaveSize: 10, IngressClass: "traefik"})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, plan.Write(dir))
+
+	patch, err := os.ReadFile(filepath.Join(dir, "wave-2", "apply", "shop_web.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "spec:\n  ingressClassName: traefik\n", string(patch))
+
+	patch, err = os.ReadFile(filepath.Join(dir, "wave-2", "rollback", "shop_web.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "spec:\n  ingressClassName: nginx\n", string(patch))
+
+	got, err := os.ReadFile(filepath.Join(dir, "plan.md"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, plan))
+	assert.Equal(t, buf.String(), string(got))
+
+	golden := filepath.Join("testdata", "plan.md")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, got, 0o644))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
@@ -45,6 +45,58 @@ func Render(report analyzer.Report, format string, summary bool, w io.Writer) er
 	}
 }
 
+// ControllerReport is the report of an NGINX ingress controller of the cluster.
+type ControllerReport struct {
+	analyzer.Controller
+	Report analyzer.Report `json:"report"`
+}
+
+// RenderControllers writes the reports of several NGINX ingress controllers to w in the
+// given format: a JSON object listing them, or their Markdown reports one after the other.
+func RenderControllers(reports []ControllerReport, format string, summary bool, w io.Writer) error {
+	if summary && format != FormatMarkdown {
+		return fmt.Errorf("--summary is only valid with format %q, got %q", FormatMarkdown, format)
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(struct {
+			Controllers []ControllerReport `json:"controllers"`
+		}{Controllers: reports}); err != nil {
+			return fmt.Errorf("encoding reports as JSON: %w", err)
+		}
+
+		return nil
+	case FormatMarkdown:
+		tmpl, err := template.New("report.md").Parse(markdownTemplate)
+		if err != nil {
+			return fmt.Errorf("parsing markdown template: %w", err)
+		}
+
+		for i, report := range reports {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return fmt.Errorf("writing markdown report: %w", err)
+				}
+			}
+
+			view := buildMarkdownView(report.Report, summary)
+			view.Controller = report.ControllerClass + " (class " + report.IngressClass + ")"
+
+			if err := tmpl.Execute(w, view); err != nil {
+				return fmt.Errorf("executing markdown template: %w", err)
+			}
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (must be %q or %q)", format, FormatJSON, FormatMarkdown)
+	}
+}
+
 // renderJSON writes the full analyzer.Report as indented JSON with a trailing
 // newline. The internal struct is the public contract by design.
 func renderJSON(report analyzer.Report, w io.Writer) error {
@@ -61,9 +113,10 @@ func renderJSON(report analyzer.Report, w io.Writer) error {
 // blockingRow is one annotation that prevents automatic migration, with how many
 // Ingresses carry it and whether it is a known-unsupported or an unknown annotation.
 type blockingRow struct {
-	Annotation string
-	Count      int
-	Kind       string // "unsupported" or "unknown"
+	Annotation  string
+	Count       int
+	Kind        string // "unsupported" or "unknown"
+	Remediation string // "<kind>: <summary>" of a known-unsupported annotation
 }
 
 // detailRow is one Ingress that needs manual attention.
@@ -74,13 +127,60 @@ type detailRow struct {
 	Fixes     string // comma-joined unsupported + unknown annotations
 }
 
+// remediationRow is the Traefik alternative to an unsupported annotation of an Ingress.
+type remediationRow struct {
+	Ingress string // namespace/name
+	analyzer.Remediation
+}
+
+// regexRow is a path that NGINX and Traefik may match differently.
+type regexRow struct {
+	Ingress string // namespace/name
+	Host    string
+	Path    string // escaped for the table cells
+	Kind    string
+	Message string
+}
+
+// conflictRow is a conflict between the paths of several Ingresses of a host.
+type conflictRow struct {
+	Host      string
+	Path      string // escaped for the table cells
+	Kind      string
+	Ingresses string // comma-joined namespace/name
+	Message   string
+}
+
+// serverRow is a server-scope annotation that the Ingresses of a host do not agree on.
+type serverRow struct {
+	Host       string
+	Annotation string
+	Kind       string
+	Ingresses  string // comma-joined namespace/name
+	Winner     string
+	Message    string
+}
+
+type controllerRow struct {
+	Workload        string // kind namespace/name
+	Image           string
+	Version         string
+	ControllerClass string
+	IngressClass    string
+	Equivalent      []analyzer.ControllerFlag
+	Missing         []analyzer.ControllerFlag
+}
+
 // markdownView is the pre-computed, deterministically-ordered view model handed
 // to the Markdown template, so the template itself stays free of sorting and
 // formatting logic.
 type markdownView struct {
+	// Controller names the NGINX ingress controller of the report, when there are several.
+	Controller  string
 	GeneratedAt string
 	Version     string
 	Hash        string
+	Unanalyzed  string // comma-joined namespaces
 
 	IngressCount     int
 	CompatibleCount  int
@@ -98,8 +198,15 @@ type markdownView struct {
 
 	Blocking []blockingRow
 
-	ShowDetail bool
-	Detail     []detailRow
+	Topology    *analyzer.IngressClassTopology
+	Controllers []controllerRow
+
+	ShowDetail   bool
+	Detail       []detailRow
+	Remediations []remediationRow
+	RegexPaths   []regexRow
+	Conflicts    []conflictRow
+	Servers      []serverRow
 }
 
 func renderMarkdown(report analyzer.Report, summary bool, w io.Writer) error {
@@ -120,6 +227,7 @@ func buildMarkdownView(report analyzer.Report, summary bool) markdownView {
 		GeneratedAt:      report.GenerationDate.UTC().Format(time.RFC3339),
 		Version:          report.Version,
 		Hash:             report.Hash,
+		Unanalyzed:       strings.Join(report.UnanalyzedNamespaces, ", "),
 		IngressCount:     report.IngressCount,
 		CompatibleCount:  report.CompatibleIngressCount,
 		CompatiblePct:    formatPct(report.CompatibleIngressPercentage),
@@ -133,11 +241,17 @@ func buildMarkdownView(report analyzer.Report, summary bool) markdownView {
 		V37:              report.CompatibleV37IngressCount,
 		Hub:              report.CompatibleHubIngressCount,
 		Blocking:         buildBlockingRows(report),
+		Topology:         report.IngressClassTopology,
+		Controllers:      buildControllerRows(report.ControllerDeployments),
 		ShowDetail:       !summary,
 	}
 
 	if view.ShowDetail {
 		view.Detail = buildDetailRows(report.UnsupportedIngresses)
+		view.Remediations = buildRemediationRows(report.UnsupportedIngresses)
+		view.RegexPaths = buildRegexRows(slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses))
+		view.Conflicts = buildConflictRows(slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses))
+		view.Servers = buildServerRows(slices.Concat(report.UnsupportedIngresses, report.CompatibleIngresses))
 	}
 
 	return view
@@ -150,7 +264,11 @@ func buildBlockingRows(report analyzer.Report) []blockingRow {
 	rows := make([]blockingRow, 0, len(report.UnsupportedIngressAnnotations)+len(report.UnknownIngressAnnotations))
 
 	for ann, count := range report.UnsupportedIngressAnnotations {
-		rows = append(rows, blockingRow{Annotation: ann, Count: count, Kind: "unsupported"})
+		row := blockingRow{Annotation: ann, Count: count, Kind: "unsupported"}
+		if kind, summary, ok := analyzer.RemediationSummary(ann); ok {
+			row.Remediation = kind + ": " + summary
+		}
+		rows = append(rows, row)
 	}
 	for ann, count := range report.UnknownIngressAnnotations {
 		rows = append(rows, blockingRow{Annotation: ann, Count: count, Kind: "unknown"})
@@ -187,6 +305,128 @@ func buildDetailRows(ingresses []analyzer.IngressReport) []detailRow {
 	return rows
 }
 
+// buildRemediationRows lists the remediations of the (already namespace/name-sorted)
+// unsupported Ingresses, in the order of their annotations.
+func buildRemediationRows(ingresses []analyzer.IngressReport) []remediationRow {
+	var rows []remediationRow
+
+	for _, ing := range ingresses {
+		for _, remediation := range ing.Remediations {
+			rows = append(rows, remediationRow{Ingress: ing.Namespace + "/" + ing.Name, Remediation: remediation})
+		}
+	}
+
+	return rows
+}
+
+// buildRegexRows lists the regex warnings of the Ingresses, sorted by namespace then name.
+func buildRegexRows(ingresses []analyzer.IngressReport) []regexRow {
+	slices.SortStableFunc(ingresses, func(a, b analyzer.IngressReport) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	var rows []regexRow
+	for _, ing := range ingresses {
+		for _, warning := range ing.RegexWarnings {
+			rows = append(rows, regexRow{
+				Ingress: ing.Namespace + "/" + ing.Name,
+				Host:    warning.Host,
+				// Pipes would split the table cells, even in code spans.
+				Path:    strings.ReplaceAll(warning.Path, "|", `\|`),
+				Kind:    warning.Kind,
+				Message: strings.ReplaceAll(warning.Message, "|", `\|`),
+			})
+		}
+	}
+
+	return rows
+}
+
+// buildConflictRows lists the path conflicts of the Ingresses once, as every Ingress
+// involved reports them, sorted by host then path.
+func buildConflictRows(ingresses []analyzer.IngressReport) []conflictRow {
+	seen := make(map[conflictRow]bool)
+
+	var rows []conflictRow
+	for _, ing := range ingresses {
+		for _, conflict := range ing.PathConflicts {
+			row := conflictRow{
+				Host: conflict.Host,
+				// Pipes would split the table cells, even in code spans.
+				Path:      strings.ReplaceAll(conflict.Path, "|", `\|`),
+				Kind:      conflict.Kind,
+				Ingresses: strings.Join(conflict.Ingresses, ", "),
+				Message:   strings.ReplaceAll(conflict.Message, "|", `\|`),
+			}
+			if !seen[row] {
+				seen[row] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b conflictRow) int {
+		return cmp.Or(cmp.Compare(a.Host, b.Host), cmp.Compare(a.Path, b.Path), cmp.Compare(a.Ingresses, b.Ingresses))
+	})
+
+	return rows
+}
+
+// buildServerRows lists the server annotation conflicts of the Ingresses once, as every
+// Ingress involved reports them, sorted by host then annotation.
+func buildServerRows(ingresses []analyzer.IngressReport) []serverRow {
+	seen := make(map[serverRow]bool)
+
+	var rows []serverRow
+	for _, ing := range ingresses {
+		for _, conflict := range ing.ServerConflicts {
+			row := serverRow{
+				Host:       conflict.Host,
+				Annotation: conflict.Annotation,
+				Kind:       conflict.Kind,
+				Ingresses:  strings.Join(conflict.Ingresses, ", "),
+				Winner:     conflict.Winner,
+				Message:    strings.ReplaceAll(conflict.Message, "|", `\|`),
+			}
+			if !seen[row] {
+				seen[row] = true
+				rows = append(rows, row)
+			}
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b serverRow) int {
+		return cmp.Or(cmp.Compare(a.Host, b.Host), cmp.Compare(a.Annotation, b.Annotation))
+	})
+
+	return rows
+}
+
+// buildControllerRows splits the flags of each controller deployment between the
+// flags Traefik has an equivalent for and the others.
+func buildControllerRows(deployments []analyzer.ControllerDeployment) []controllerRow {
+	var rows []controllerRow
+	for _, deployment := range deployments {
+		row := controllerRow{
+			Workload:        deployment.Kind + " " + deployment.Namespace + "/" + deployment.Name,
+			Image:           deployment.Image,
+			Version:         cmp.Or(deployment.Version, "unknown"),
+			ControllerClass: deployment.ControllerClass,
+			IngressClass:    deployment.IngressClass,
+		}
+		for _, flag := range deployment.Flags {
+			if flag.Traefik != "" {
+				row.Equivalent = append(row.Equivalent, flag)
+			} else {
+				row.Missing = append(row.Missing, flag)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
 func formatPct(pct float64) string {
 	return fmt.Sprintf("%.1f%%", pct)
 }
@@ -22,6 +22,7 @@ func mixedReport() analyzer.Report {
 		GenerationDate:               time.Date(2026, 5, 27, 10, 0, 0, 0, time.UTC),
 		Version:                      "v0.3.0",
 		Hash:                         "abc123def456",
+		UnanalyzedNamespaces:         []string{"kube-system", "restricted"},
 		IngressCount:                 4,
 		IngressCountByClass:          map[string]int{"nginx": 4},
 		CompatibleIngressCount:       2,
@@ -38,12 +39,65 @@ func mixedReport() analyzer.Report {
 		UnknownIngressAnnotations: map[string]int{
 			"nginx.ingress.kubernetes.io/totally-made-up": 1,
 		},
+		IngressClassTopology: &analyzer.IngressClassTopology{
+			IngressClasses: []analyzer.IngressClassReport{
+				{Name: "nginx", Controller: "k8s.io/ingress-nginx", Default: true, NGINX: true, IngressCount: 4},
+				{Name: "traefik", Controller: "traefik.io/ingress-controller", Parameters: "IngressParameters.example.com traefik/public", IngressCount: 1},
+			},
+			Default: "nginx",
+			Takeover: []string{
+				"Configure the kubernetesIngressNGINX provider of Traefik with ingressClass=nginx, controllerClass=k8s.io/ingress-nginx.",
+				"The IngressClass traefik of Traefik exists.",
+			},
+		},
+		ControllerDeployments: []analyzer.ControllerDeployment{{
+			Kind:            "Deployment",
+			Namespace:       "ingress-nginx",
+			Name:            "ingress-nginx-controller",
+			Image:           "registry.k8s.io/ingress-nginx/controller:v1.11.2",
+			Version:         "v1.11.2",
+			ControllerClass: "k8s.io/ingress-nginx",
+			IngressClass:    "nginx",
+			Flags: []analyzer.ControllerFlag{
+				{Name: "annotations-prefix", Value: "custom.ingress.kubernetes.io", Note: "Traefik only reads the annotations prefixed with nginx.ingress.kubernetes.io."},
+				{Name: "default-backend-service", Value: "ingress-nginx/default-backend", Traefik: "providers.kubernetesIngressNGINX.defaultBackendService"},
+				{Name: "enable-annotation-validation", Value: "true"},
+				{Name: "publish-service", Value: "ingress-nginx/ingress-nginx-controller", Traefik: "providers.kubernetesIngressNGINX.publishService", Note: "Set it to the Service of Traefik."},
+			},
+		}},
 		UnsupportedIngresses: []analyzer.IngressReport{
 			{
 				Name:                   "api",
 				Namespace:              "prod",
 				IngressClassName:       "nginx",
 				UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/limit-connections"},
+				Remediations: []analyzer.Remediation{{
+					Annotation: "nginx.ingress.kubernetes.io/limit-connections",
+					Kind:       analyzer.RemediationMiddleware,
+					Summary:    "Limit the simultaneous requests per client IP with an InFlightReq middleware.",
+					YAML:       "inFlightReq:\n  amount: 5\n",
+				}},
+				RegexWarnings: []analyzer.RegexWarning{{
+					Host:    "api.example.com",
+					Path:    "/(?!internal)(v1|v2)/.*",
+					Kind:    analyzer.RegexPCREOnly,
+					Message: "Go regexes do not support the PCRE lookaheads",
+				}},
+				PathConflicts: []analyzer.PathConflict{{
+					Host:      "api.example.com",
+					Path:      "/v1",
+					Kind:      analyzer.PathShadowed,
+					Ingresses: []string{"prod/web", "prod/api"},
+					Message:   `the requests to "/v1" under the path "/" of the Ingress prod/web are routed to the more specific path "/v1" of the Ingress prod/api, by both NGINX and Traefik`,
+				}},
+				ServerConflicts: []analyzer.ServerConflict{{
+					Host:       "api.example.com",
+					Annotation: "server-snippet",
+					Kind:       analyzer.ServerAnnotationPartial,
+					Ingresses:  []string{"prod/web", "prod/api"},
+					Winner:     "prod/web",
+					Message:    "the server-snippet annotation is only set by prod/web, and NGINX adds the snippet of prod/web to the whole server, including the paths of prod/api, while Traefik applies the converted snippet to the routers of its Ingress only",
+				}},
 			},
 			{
 				Name:                   "web",
@@ -51,6 +105,21 @@ func mixedReport() analyzer.Report {
 				IngressClassName:       "nginx",
 				UnsupportedAnnotations: []string{"nginx.ingress.kubernetes.io/limit-connections"},
 				UnknownAnnotations:     []string{"nginx.ingress.kubernetes.io/totally-made-up"},
+				PathConflicts: []analyzer.PathConflict{{
+					Host:      "api.example.com",
+					Path:      "/v1",
+					Kind:      analyzer.PathShadowed,
+					Ingresses: []string{"prod/web", "prod/api"},
+					Message:   `the requests to "/v1" under the path "/" of the Ingress prod/web are routed to the more specific path "/v1" of the Ingress prod/api, by both NGINX and Traefik`,
+				}},
+				ServerConflicts: []analyzer.ServerConflict{{
+					Host:       "api.example.com",
+					Annotation: "server-snippet",
+					Kind:       analyzer.ServerAnnotationPartial,
+					Ingresses:  []string{"prod/web", "prod/api"},
+					Winner:     "prod/web",
+					Message:    "the server-snippet annotation is only set by prod/web, and NGINX adds the snippet of prod/web to the whole server, including the paths of prod/api, while Traefik applies the converted snippet to the routers of its Ingress only",
+				}},
 			},
 		},
 		SupportedIngressAnnotations: []analyzer.AnnotationInfo{
@@ -136,6 +205,45 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestRenderControllers(t *testing.T) {
+	t.Parallel()
+
+	reports := []ControllerReport{
+		{Controller: analyzer.Controller{ControllerClass: "k8s.io/ingress-nginx", IngressClass: "nginx"}, Report: compatibleReport()},
+		{Controller: analyzer.Controller{ControllerClass: "k8s.io/ingress-nginx-internal", IngressClass: "nginx-internal"}, Report: emptyReport()},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		golden string
+	}{
+		{name: "json", format: FormatJSON, golden: "controllers.json"},
+		{name: "markdown", format: FormatMarkdown, golden: "controllers.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, RenderControllers(reports, tt.format, false, &buf))
+
+			goldenPath := filepath.Join("testdata", tt.golden)
+			if *update {
+				require.NoError(t, os.WriteFile(goldenPath, buf.Bytes(), 0o600))
+			}
+
+			want, err := os.ReadFile(goldenPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String())
+		})
+	}
+
+	var buf bytes.Buffer
+	require.Error(t, RenderControllers(reports, FormatJSON, true, &buf))
+}
+
 func TestRenderUnknownFormat(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,86 @@
+package replay
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultLogFormat is the default log-format-upstream of the NGINX ingress controller.
+const DefaultLogFormat = `$remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent" $request_length $request_time [$proxy_upstream_name] [$proxy_alternative_upstream_name] $upstream_addr $upstream_response_length $upstream_response_time $upstream_status $req_id`
+
+var logVariable = regexp.MustCompile(`\$(?:\{(\w+)\}|(\w+))`)
+
+// LogFormat parses the access log lines written with an NGINX log_format.
+type LogFormat struct {
+	re *regexp.Regexp
+}
+
+// ParseLogFormat returns the parser of the NGINX log_format. Every variable of the format
+// matches up to the text following it, the last one up to the end of the line.
+func ParseLogFormat(format string) (*LogFormat, error) {
+	matches := logVariable.FindAllStringSubmatchIndex(format, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("the log format %q has no variables", format)
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	seen := make(map[string]bool)
+	last := 0
+	for i, m := range matches {
+		pattern.WriteString(regexp.QuoteMeta(format[last:m[0]]))
+
+		var name string
+		if m[2] >= 0 {
+			name = format[m[2]:m[3]] // ${name}
+		} else {
+			name = format[m[4]:m[5]]
+		}
+
+		group := ".*?"
+		if i == len(matches)-1 {
+			group = ".*"
+		}
+
+		// A variable logged twice is only captured once.
+		if seen[name] {
+			pattern.WriteString("(?:" + group + ")")
+		} else {
+			seen[name] = true
+			pattern.WriteString("(?P<" + name + ">" + group + ")")
+		}
+
+		last = m[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(format[last:]))
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, fmt.Errorf("compiling the log format %q: %w", format, err)
+	}
+
+	return &LogFormat{re: re}, nil
+}
+
+// Parse returns the variables of the line, false when it does not match the format.
+// The variables NGINX logs as "-" when empty are empty.
+func (f *LogFormat) Parse(line string) (map[string]string, bool) {
+	match := f.re.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+
+	vars := make(map[string]string)
+	for i, name := range f.re.SubexpNames() {
+		if name == "" {
+			continue
+		}
+		if value := match[i]; value != "-" {
+			vars[name] = value
+		}
+	}
+	return vars, true
+}
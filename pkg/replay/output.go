@@ -0,0 +1,89 @@
+package replay
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Supported output formats.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+//go:embed replay.md.tmpl
+var markdownTemplate string
+
+// Report is the comparison of the responses of the controllers to the replayed requests.
+type Report struct {
+	OldURL  string   `json:"oldURL"`
+	NewURL  string   `json:"newURL"`
+	Skipped Skipped  `json:"skipped"`
+	Results []Result `json:"results"`
+	// Mismatches is the number of requests with different responses.
+	Mismatches int `json:"mismatches"`
+}
+
+// NewReport returns the report of the results.
+func NewReport(opts Options, skipped Skipped, results []Result) Report {
+	report := Report{OldURL: opts.OldURL, NewURL: opts.NewURL, Skipped: skipped, Results: results}
+	for _, result := range results {
+		if len(result.Diffs) > 0 {
+			report.Mismatches++
+		}
+	}
+	return report
+}
+
+// Write writes the report in the given format.
+func Write(w io.Writer, report Report, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("encoding report as JSON: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		return writeMarkdown(w, report)
+	default:
+		return fmt.Errorf("unknown format %q (must be %q or %q)", format, FormatJSON, FormatMarkdown)
+	}
+}
+
+func writeMarkdown(w io.Writer, report Report) error {
+	tmpl, err := template.New("replay.md").Funcs(template.FuncMap{
+		// cell escapes the pipes of a table cell.
+		"cell": func(v any) string {
+			return strings.ReplaceAll(fmt.Sprint(v), "|", `\|`)
+		},
+		"join": func(items []string) string {
+			return strings.Join(items, "; ")
+		},
+		"response": func(r Response) string {
+			if r.Error != "" {
+				return "error"
+			}
+			s := strconv.Itoa(r.Status)
+			if r.Location != "" {
+				s += " → " + strings.ReplaceAll(r.Location, "|", `\|`)
+			}
+			return s
+		},
+	}).Parse(markdownTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
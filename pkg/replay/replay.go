@@ -0,0 +1,362 @@
+// Package replay replays the requests of NGINX access logs against two ingress
+// controllers, such as the NGINX ingress controller and Traefik, and compares their
+// responses: status, redirect Location and selected headers.
+package replay
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Request is a request of the access logs, replayed once however often it was logged.
+type Request struct {
+	Method string `json:"method"`
+	Host   string `json:"host"`
+	URI    string `json:"uri"`
+	// Headers are the logged request headers, such as the User-Agent.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Line is the first line of the request in the logs, Count the number of lines.
+	Line  int `json:"line"`
+	Count int `json:"count"`
+}
+
+// String returns the request line of the request.
+func (r Request) String() string {
+	return r.Method + " " + r.Host + r.URI
+}
+
+// ReadOptions configures the reading of the access logs.
+type ReadOptions struct {
+	// DefaultHost is the host of the requests whose host is not logged, such as
+	// with the default NGINX ingress controller log format.
+	DefaultHost string
+	// Methods are the replayed methods, the other requests are skipped.
+	Methods []string
+	// Limit is the maximum number of replayed requests. When 0, every request is replayed.
+	Limit int
+}
+
+// Skipped counts the log lines whose requests are not replayed.
+type Skipped struct {
+	// Unparsed lines do not match the log format or have no valid request.
+	Unparsed int `json:"unparsed"`
+	// Methods counts the requests of the methods which are not replayed.
+	Methods int `json:"methods"`
+	// NoHost counts the requests without host.
+	NoHost int `json:"noHost"`
+	// Limit counts the requests over the limit.
+	Limit int `json:"limit"`
+}
+
+// headerVariables are the logged variables replayed as request headers.
+var headerVariables = map[string]string{
+	"http_user_agent": "User-Agent",
+	"http_referer":    "Referer",
+	"http_accept":     "Accept",
+}
+
+// ReadRequests returns the requests of the access logs written with the format, in order
+// of first appearance.
+func ReadRequests(r io.Reader, format *LogFormat, opts ReadOptions) ([]Request, Skipped, error) {
+	var requests []Request
+	var skipped Skipped
+	index := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		vars, ok := format.Parse(scanner.Text())
+		if !ok {
+			skipped.Unparsed++
+			continue
+		}
+
+		req, ok := request(vars)
+		if !ok {
+			skipped.Unparsed++
+			continue
+		}
+
+		req.Host = cmp.Or(req.Host, opts.DefaultHost)
+		switch {
+		case req.Host == "":
+			skipped.NoHost++
+			continue
+		case !slices.Contains(opts.Methods, req.Method):
+			skipped.Methods++
+			continue
+		}
+
+		key := req.String()
+		if i, ok := index[key]; ok {
+			requests[i].Count++
+			continue
+		}
+		if opts.Limit > 0 && len(requests) >= opts.Limit {
+			skipped.Limit++
+			continue
+		}
+
+		req.Line, req.Count = line, 1
+		index[key] = len(requests)
+		requests = append(requests, req)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, skipped, fmt.Errorf("reading access logs: %w", err)
+	}
+
+	return requests, skipped, nil
+}
+
+// request returns the request of the logged variables.
+func request(vars map[string]string) (Request, bool) {
+	var req Request
+
+	if line, ok := vars["request"]; ok {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return req, false
+		}
+		req.Method, req.URI = fields[0], fields[1]
+	} else {
+		req.Method, req.URI = vars["request_method"], vars["request_uri"]
+	}
+	if req.Method == "" || !strings.HasPrefix(req.URI, "/") {
+		return req, false
+	}
+
+	req.Host = cmp.Or(vars["host"], vars["http_host"], vars["server_name"])
+	if h, _, ok := strings.Cut(req.Host, ":"); ok {
+		req.Host = h
+	}
+	// The default server of NGINX is not a host.
+	if req.Host == "_" {
+		req.Host = ""
+	}
+
+	for variable, header := range headerVariables {
+		if value := vars[variable]; value != "" {
+			if req.Headers == nil {
+				req.Headers = make(map[string]string)
+			}
+			req.Headers[header] = value
+		}
+	}
+
+	return req, true
+}
+
+// Options configures the replay.
+type Options struct {
+	// OldURL and NewURL are the base URLs of the old and new ingress controllers.
+	OldURL string
+	NewURL string
+	// Headers are the response headers compared, besides the status and the Location.
+	Headers []string
+	// Concurrency is the number of requests replayed at once.
+	Concurrency int
+	// Client sends the requests. It must not follow the redirects.
+	Client *http.Client
+}
+
+// Response is the response of a controller to a replayed request.
+type Response struct {
+	Status   int               `json:"status,omitempty"`
+	Location string            `json:"location,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// Result is the comparison of the responses of the controllers to a request.
+type Result struct {
+	Request Request  `json:"request"`
+	Old     Response `json:"old"`
+	New     Response `json:"new"`
+	// Diffs are the differences between the responses.
+	Diffs []string `json:"diffs,omitempty"`
+}
+
+// NewClient returns a client which does not follow the redirects, for their Location
+// to be compared. The host of each request is sent as TLS server name, whatever the
+// address of the controller, with a clone of the transport per host. When nil, the
+// transport is a clone of the default one.
+func NewClient(transport *http.Transport) *http.Client {
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+
+	return &http.Client{
+		Transport: &hostTransport{base: transport, transports: make(map[string]*http.Transport)},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// hostTransport sends the requests with a transport whose TLS server name is their host,
+// so that the controllers serve the certificate of the host.
+type hostTransport struct {
+	base *http.Transport
+
+	mu         sync.Mutex
+	transports map[string]*http.Transport
+}
+
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" || req.Host == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return t.transport(host).RoundTrip(req)
+}
+
+// transport returns the transport of the host, whose connections are not shared with
+// the other hosts.
+func (t *hostTransport) transport(host string) *http.Transport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	transport, ok := t.transports[host]
+	if !ok {
+		transport = t.base.Clone()
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transport.TLSClientConfig.ServerName = host
+		t.transports[host] = transport
+	}
+
+	return transport
+}
+
+// Replay sends the requests to both controllers and compares their responses.
+func Replay(ctx context.Context, requests []Request, opts Options) ([]Result, error) {
+	oldURL, err := url.Parse(opts.OldURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the old URL: %w", err)
+	}
+	newURL, err := url.Parse(opts.NewURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing the new URL: %w", err)
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = NewClient(nil)
+	}
+
+	results := make([]Result, len(requests))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(1, opts.Concurrency) {
+		wg.Go(func() {
+			for i := range jobs {
+				req := requests[i]
+				result := Result{
+					Request: req,
+					Old:     send(ctx, client, oldURL, req, opts.Headers),
+					New:     send(ctx, client, newURL, req, opts.Headers),
+				}
+				result.Diffs = diff(result.Old, result.New, opts.Headers)
+				results[i] = result
+			}
+		})
+	}
+
+	for i := range requests {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, nil
+}
+
+// send sends the request to the controller at the base URL.
+func send(ctx context.Context, client *http.Client, base *url.URL, req Request, headers []string) Response {
+	uri, err := url.ParseRequestURI(req.URI)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+
+	target := *base
+	target.Path = strings.TrimSuffix(base.Path, "/") + uri.Path
+	target.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + uri.EscapedPath()
+	target.RawQuery = uri.RawQuery
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target.String(), nil)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	httpReq.Host = req.Host
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Response{Error: err.Error()}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	response := Response{Status: resp.StatusCode, Location: resp.Header.Get("Location")}
+	for _, name := range headers {
+		if value := strings.Join(resp.Header.Values(name), ", "); value != "" {
+			if response.Headers == nil {
+				response.Headers = make(map[string]string)
+			}
+			response.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+
+	return response
+}
+
+// diff returns the differences between the responses.
+func diff(old, new Response, headers []string) []string {
+	var diffs []string
+	if old.Error != "" || new.Error != "" {
+		if old.Error != new.Error {
+			diffs = append(diffs, fmt.Sprintf("error: %q vs %q", old.Error, new.Error))
+		}
+		return diffs
+	}
+
+	if old.Status != new.Status {
+		diffs = append(diffs, fmt.Sprintf("status: %d vs %d", old.Status, new.Status))
+	}
+	if old.Location != new.Location {
+		diffs = append(diffs, fmt.Sprintf("Location: %q vs %q", old.Location, new.Location))
+	}
+	for _, name := range headers {
+		name = http.CanonicalHeaderKey(name)
+		if old.Headers[name] != new.Headers[name] {
+			diffs = append(diffs, fmt.Sprintf("%s: %q vs %q", name, old.Headers[name], new.Headers[name]))
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,177 @@
+package replay
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestParseLogFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := ParseLogFormat(DefaultLogFormat)
+	require.NoError(t, err)
+
+	vars, ok := format.Parse(`10.0.0.1 - - [18/Oct/2026:10:00:00 +0000] "GET /api/v1/users?page=2 HTTP/1.1" 200 612 "-" "curl/8.5.0" 95 0.003 [default-api-80] [] 10.42.0.12:8080 612 0.003 200 5f3c`)
+	require.True(t, ok)
+	assert.Equal(t, "GET /api/v1/users?page=2 HTTP/1.1", vars["request"])
+	assert.Equal(t, "200", vars["status"])
+	assert.Equal(t, "curl/8.5.0", vars["http_user_agent"])
+	assert.Equal(t, "default-api-80", vars["proxy_upstream_name"])
+	assert.Equal(t, "5f3c", vars["req_id"])
+	assert.NotContains(t, vars, "http_referer")
+
+	_, ok = format.Parse("not an access log line")
+	assert.False(t, ok)
+
+	format, err = ParseLogFormat(`${host} "$request_method $request_uri" $status`)
+	require.NoError(t, err)
+
+	vars, ok = format.Parse(`shop.example.com "POST /cart" 302`)
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"host": "shop.example.com", "request_method": "POST", "request_uri": "/cart", "status": "302"}, vars)
+
+	_, err = ParseLogFormat("no variables")
+	assert.Error(t, err)
+}
+
+func TestReadRequests(t *testing.T) {
+	t.Parallel()
+
+	format, err := ParseLogFormat(`$remote_addr $host "$request" $status "$http_user_agent"`)
+	require.NoError(t, err)
+
+	logs := strings.Join([]string{
+		`10.0.0.1 shop.example.com "GET /cart HTTP/1.1" 200 "Mozilla/5.0"`,
+		`10.0.0.2 shop.example.com:443 "GET /cart HTTP/1.1" 200 "Mozilla/5.0"`,
+		`10.0.0.1 shop.example.com "POST /cart HTTP/1.1" 302 "Mozilla/5.0"`,
+		`10.0.0.1 _ "GET / HTTP/1.1" 404 "-"`,
+		`garbage`,
+		`10.0.0.1 api.example.com "HEAD /health HTTP/1.1" 200 "-"`,
+		`10.0.0.1 api.example.com "GET /v1 HTTP/1.1" 200 "-"`,
+		``,
+	}, "\n")
+
+	requests, skipped, err := ReadRequests(strings.NewReader(logs), format, ReadOptions{Methods: []string{"GET", "HEAD"}, Limit: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Request{
+		{Method: "GET", Host: "shop.example.com", URI: "/cart", Headers: map[string]string{"User-Agent": "Mozilla/5.0"}, Line: 1, Count: 2},
+		{Method: "HEAD", Host: "api.example.com", URI: "/health", Line: 6, Count: 1},
+	}, requests)
+	assert.Equal(t, Skipped{Unparsed: 1, Methods: 1, NoHost: 1, Limit: 1}, skipped)
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	// The old and new controllers agree on the root, and differ on the redirects and headers.
+	old := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/app":
+			http.Redirect(rw, req, "http://"+req.Host+"/app/", http.StatusMovedPermanently)
+		default:
+			rw.Header().Set("Content-Type", "text/html")
+			rw.Header().Set("X-Host", req.Host)
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(old.Close)
+
+	current := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/app":
+			http.Redirect(rw, req, "https://"+req.Host+"/app", http.StatusFound)
+		case "/missing":
+			rw.WriteHeader(http.StatusNotFound)
+		default:
+			rw.Header().Set("Content-Type", "text/html")
+			rw.Header().Set("X-Host", req.Host+"?"+req.URL.RawQuery)
+			rw.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(current.Close)
+
+	requests := []Request{
+		{Method: "GET", Host: "shop.example.com", URI: "/", Count: 3},
+		{Method: "GET", Host: "shop.example.com", URI: "/app", Count: 1},
+		{Method: "GET", Host: "shop.example.com", URI: "/missing?q=1", Count: 1},
+	}
+
+	opts := Options{OldURL: old.URL, NewURL: current.URL + "/", Headers: []string{"content-type"}, Concurrency: 2}
+
+	results, err := Replay(t.Context(), requests, opts)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Empty(t, results[0].Diffs)
+	assert.Equal(t, Response{Status: http.StatusOK, Headers: map[string]string{"Content-Type": "text/html"}}, results[0].Old)
+
+	assert.Equal(t, []string{
+		"status: 301 vs 302",
+		`Location: "http://shop.example.com/app/" vs "https://shop.example.com/app"`,
+	}, results[1].Diffs)
+
+	assert.Equal(t, []string{
+		"status: 200 vs 404",
+		`Content-Type: "text/html" vs ""`,
+	}, results[2].Diffs)
+
+	report := NewReport(Options{OldURL: "http://nginx.local", NewURL: "http://traefik.local"}, Skipped{Unparsed: 1}, results)
+	assert.Equal(t, 2, report.Mismatches)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, FormatMarkdown))
+
+	golden := filepath.Join("testdata", "replay.md")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o644))
+	}
+
+	want, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), buf.String())
+}
+
+func TestNewClient_ServerName(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu          sync.Mutex
+		serverNames []string
+	)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		serverNames = append(serverNames, req.TLS.ServerName)
+		mu.Unlock()
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	transport := srv.Client().Transport.(*http.Transport).Clone()
+	transport.TLSClientConfig.InsecureSkipVerify = true
+
+	requests := []Request{
+		{Method: "GET", Host: "shop.example.com", URI: "/"},
+		{Method: "GET", Host: "api.example.com:8443", URI: "/"},
+	}
+
+	results, err := Replay(t.Context(), requests, Options{OldURL: srv.URL, NewURL: srv.URL, Client: NewClient(transport)})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	// Both controllers are sent the host of each request, not their address.
+	assert.ElementsMatch(t, []string{"shop.example.com", "shop.example.com", "api.example.com", "api.example.com"}, serverNames)
+}
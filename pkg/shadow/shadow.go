@@ -0,0 +1,208 @@
+// Package shadow clones Ingresses onto rehearsal hosts served by Traefik, so that
+// the applications can be tested through Traefik before switching their hosts.
+package shadow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	annotationIngressClass = "kubernetes.io/ingress.class"
+	annotationLastApplied  = "kubectl.kubernetes.io/last-applied-configuration"
+	annotationServerAlias  = "nginx.ingress.kubernetes.io/server-alias"
+	annotationFromToWWW    = "nginx.ingress.kubernetes.io/from-to-www-redirect"
+	defaultNameSuffix      = "-shadow"
+	ingressAPIVersion      = "networking.k8s.io/v1"
+	ingressKind            = "Ingress"
+	labelShadowOf          = "ingress-nginx-migration.traefik.io/shadow-of"
+	annotationShadowOf     = "ingress-nginx-migration.traefik.io/shadow-of"
+)
+
+// Options configures the shadow Ingresses.
+type Options struct {
+	// HostSuffix is appended to the hosts, replacing ReplaceHostSuffix when they end with it.
+	HostSuffix        string
+	ReplaceHostSuffix string
+	// NameSuffix is appended to the names of the Ingresses.
+	NameSuffix string
+	// IngressClass is the Traefik class of the shadow Ingresses.
+	IngressClass string
+}
+
+// Shadow is the shadow of an Ingress.
+type Shadow struct {
+	Ingress  *netv1.Ingress
+	Warnings []string
+}
+
+// New returns the shadows of the Ingresses: copies on the rewritten hosts, with the
+// Traefik IngressClass and every annotation.
+func New(ingresses []*netv1.Ingress, opts Options) ([]Shadow, error) {
+	if opts.HostSuffix == "" {
+		return nil, fmt.Errorf("the host suffix is required")
+	}
+	if opts.IngressClass == "" {
+		return nil, fmt.Errorf("the Traefik ingress class is required")
+	}
+	if opts.NameSuffix == "" {
+		opts.NameSuffix = defaultNameSuffix
+	}
+
+	shadows := make([]Shadow, 0, len(ingresses))
+	for _, ing := range ingresses {
+		// The API server rejects the Ingress names longer than a DNS subdomain.
+		if name := ing.Name + opts.NameSuffix; len(name) > validation.DNS1123SubdomainMaxLength {
+			return nil, fmt.Errorf("the shadow name %s of the Ingress %s/%s is longer than %d characters", name, ing.Namespace, ing.Name, validation.DNS1123SubdomainMaxLength)
+		}
+
+		shadows = append(shadows, newShadow(ing, opts))
+	}
+
+	return shadows, nil
+}
+
+func newShadow(ing *netv1.Ingress, opts Options) Shadow {
+	var warnings []string
+
+	clone := &netv1.Ingress{
+		TypeMeta: metav1.TypeMeta{APIVersion: ingressAPIVersion, Kind: ingressKind},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        ing.Name + opts.NameSuffix,
+			Namespace:   ing.Namespace,
+			Labels:      map[string]string{labelShadowOf: labelValue(ing.Name)},
+			Annotations: make(map[string]string, len(ing.Annotations)+1),
+		},
+		Spec: *ing.Spec.DeepCopy(),
+	}
+
+	for key, value := range ing.Labels {
+		clone.Labels[key] = value
+	}
+
+	// The last applied configuration is the one of the original Ingress.
+	for key, value := range ing.Annotations {
+		if key != annotationLastApplied {
+			clone.Annotations[key] = value
+		}
+	}
+	clone.Annotations[annotationShadowOf] = ing.Name
+
+	// The API server rejects the Ingresses setting both the class annotation and field.
+	if _, ok := clone.Annotations[annotationIngressClass]; ok {
+		clone.Annotations[annotationIngressClass] = opts.IngressClass
+		clone.Spec.IngressClassName = nil
+	} else {
+		clone.Spec.IngressClassName = ptr.To(opts.IngressClass)
+	}
+
+	for i := range clone.Spec.Rules {
+		if host := clone.Spec.Rules[i].Host; host != "" {
+			clone.Spec.Rules[i].Host = rewriteHost(host, opts)
+		}
+	}
+
+	for i, tls := range clone.Spec.TLS {
+		for j, host := range tls.Hosts {
+			clone.Spec.TLS[i].Hosts[j] = rewriteHost(host, opts)
+		}
+		if tls.SecretName != "" {
+			warnings = append(warnings, fmt.Sprintf("the certificate of the Secret %s must also cover the rewritten hosts %s", tls.SecretName, strings.Join(clone.Spec.TLS[i].Hosts, ", ")))
+		}
+	}
+
+	// The annotations are kept as is, the ones holding hosts are not rewritten.
+	if alias, ok := clone.Annotations[annotationServerAlias]; ok {
+		warnings = append(warnings, fmt.Sprintf("the %s annotation still serves the production hosts %s", annotationServerAlias, alias))
+	}
+	if _, ok := clone.Annotations[annotationFromToWWW]; ok {
+		warnings = append(warnings, fmt.Sprintf("the %s annotation redirects between the rewritten hosts and their www variants, which must resolve", annotationFromToWWW))
+	}
+
+	return Shadow{Ingress: clone, Warnings: warnings}
+}
+
+// labelValue returns the name as a label value: as is when short enough, otherwise
+// truncated and followed by a hash of the name, the annotation holding the full name.
+func labelValue(name string) string {
+	if len(name) <= validation.LabelValueMaxLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:4])
+
+	return name[:validation.LabelValueMaxLength-len(hash)-1] + "-" + hash
+}
+
+// rewriteHost appends the host suffix, replacing the suffix to replace when the host ends with it.
+// The wildcard hosts keep their wildcard.
+func rewriteHost(host string, opts Options) string {
+	if opts.ReplaceHostSuffix != "" {
+		if trimmed, ok := strings.CutSuffix(host, opts.ReplaceHostSuffix); ok {
+			return trimmed + opts.HostSuffix
+		}
+	}
+	return host + opts.HostSuffix
+}
+
+// WriteManifests writes the shadow Ingresses as a multi-document YAML stream.
+// Each Ingress is introduced by comments listing its original Ingress and the
+// warnings of the rehearsal.
+func WriteManifests(w io.Writer, shadows []Shadow) error {
+	for _, shadow := range shadows {
+		ing := shadow.Ingress
+
+		if _, err := fmt.Fprintf(w, "# Shadow of Ingress %s/%s\n", ing.Namespace, ing.Annotations[annotationShadowOf]); err != nil {
+			return fmt.Errorf("writing manifests: %w", err)
+		}
+
+		for _, warning := range shadow.Warnings {
+			if _, err := fmt.Fprintf(w, "# Warning: %s\n", warning); err != nil {
+				return fmt.Errorf("writing manifests: %w", err)
+			}
+		}
+
+		data, err := marshalIngress(ing)
+		if err != nil {
+			return fmt.Errorf("marshaling Ingress %s/%s: %w", ing.Namespace, ing.Name, err)
+		}
+
+		if _, err := fmt.Fprintf(w, "---\n%s", data); err != nil {
+			return fmt.Errorf("writing manifests: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// marshalIngress marshals the Ingress without its empty status and creation timestamp,
+// which the API server sets.
+func marshalIngress(ing *netv1.Ingress) ([]byte, error) {
+	data, err := json.Marshal(ing)
+	if err != nil {
+		return nil, err
+	}
+
+	var object map[string]any
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+
+	delete(object, "status")
+	if metadata, ok := object["metadata"].(map[string]any); ok {
+		delete(metadata, "creationTimestamp")
+	}
+
+	return yaml.Marshal(object)
+}
@@ -0,0 +1,170 @@
+package shadow
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	netv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/ptr"
+)
+
+func testIngresses() []*netv1.Ingress {
+	pathType := netv1.PathTypePrefix
+	backend := netv1.IngressBackend{Service: &netv1.IngressServiceBackend{Name: "web", Port: netv1.ServiceBackendPort{Number: 80}}}
+
+	return []*netv1.Ingress{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "shop",
+				Name:            "web",
+				ResourceVersion: "42",
+				UID:             "0b9c2d4e",
+				Labels:          map[string]string{"app": "web"},
+				Annotations: map[string]string{
+					"nginx.ingress.kubernetes.io/server-alias":         "www.example.com",
+					"nginx.ingress.kubernetes.io/ssl-redirect":         "false",
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				},
+			},
+			Spec: netv1.IngressSpec{
+				IngressClassName: ptr.To("nginx"),
+				TLS:              []netv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}},
+				Rules: []netv1.IngressRule{
+					{
+						Host: "shop.example.com",
+						IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{{Path: "/", PathType: &pathType, Backend: backend}},
+						}},
+					},
+					{
+						Host: "*.shop.internal",
+						IngressRuleValue: netv1.IngressRuleValue{HTTP: &netv1.HTTPIngressRuleValue{
+							Paths: []netv1.HTTPIngressPath{{Path: "/", PathType: &pathType, Backend: backend}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "api",
+				Name:        "backend",
+				Annotations: map[string]string{"kubernetes.io/ingress.class": "nginx"},
+			},
+			Spec: netv1.IngressSpec{DefaultBackend: &backend},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	ingresses := testIngresses()
+
+	shadows, err := New(ingresses, Options{HostSuffix: ".traefik.example.com", ReplaceHostSuffix: ".example.com", IngressClass: "traefik"})
+	require.NoError(t, err)
+	require.Len(t, shadows, 2)
+
+	web := shadows[0].Ingress
+	assert.Equal(t, "web-shadow", web.Name)
+	assert.Equal(t, "shop", web.Namespace)
+	assert.Empty(t, web.ResourceVersion)
+	assert.Empty(t, web.UID)
+	assert.Equal(t, map[string]string{"app": "web", labelShadowOf: "web"}, web.Labels)
+	assert.Equal(t, map[string]string{
+		"nginx.ingress.kubernetes.io/server-alias": "www.example.com",
+		"nginx.ingress.kubernetes.io/ssl-redirect": "false",
+		annotationShadowOf:                         "web",
+	}, web.Annotations)
+	assert.Equal(t, ptr.To("traefik"), web.Spec.IngressClassName)
+	assert.Equal(t, "shop.traefik.example.com", web.Spec.Rules[0].Host)
+	assert.Equal(t, "*.shop.internal.traefik.example.com", web.Spec.Rules[1].Host)
+	assert.Equal(t, []string{"shop.traefik.example.com"}, web.Spec.TLS[0].Hosts)
+	assert.Equal(t, []string{
+		"the certificate of the Secret shop-tls must also cover the rewritten hosts shop.traefik.example.com",
+		"the nginx.ingress.kubernetes.io/server-alias annotation still serves the production hosts www.example.com",
+	}, shadows[0].Warnings)
+
+	// The class annotation is switched rather than the class field, which the API server rejects together.
+	backend := shadows[1].Ingress
+	assert.Nil(t, backend.Spec.IngressClassName)
+	assert.Equal(t, map[string]string{"kubernetes.io/ingress.class": "traefik", annotationShadowOf: "backend"}, backend.Annotations)
+	assert.Empty(t, shadows[1].Warnings)
+
+	// The original Ingresses are left untouched.
+	assert.Equal(t, "shop.example.com", ingresses[0].Spec.Rules[0].Host)
+	assert.Equal(t, []string{"shop.example.com"}, ingresses[0].Spec.TLS[0].Hosts)
+	assert.Equal(t, ptr.To("nginx"), ingresses[0].Spec.IngressClassName)
+}
+
+func TestNew_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(testIngresses(), Options{IngressClass: "traefik"})
+	require.Error(t, err)
+
+	_, err = New(testIngresses(), Options{HostSuffix: ".traefik.local"})
+	require.Error(t, err)
+
+	long := testIngresses()[1]
+	long.Name = strings.Repeat("a", 250)
+	_, err = New([]*netv1.Ingress{long}, Options{HostSuffix: ".traefik.local", IngressClass: "traefik"})
+	require.Error(t, err)
+}
+
+func TestNew_LongName(t *testing.T) {
+	t.Parallel()
+
+	long := testIngresses()[1]
+	long.Name = strings.Repeat("backend-", 20) + "api"
+
+	shadows, err := New([]*netv1.Ingress{long}, Options{HostSuffix: ".traefik.local", IngressClass: "traefik"})
+	require.NoError(t, err)
+	require.Len(t, shadows, 1)
+
+	shadow := shadows[0].Ingress
+	assert.Equal(t, long.Name+"-shadow", shadow.Name)
+	assert.Equal(t, long.Name, shadow.Annotations[annotationShadowOf])
+	assert.Len(t, shadow.Labels[labelShadowOf], 63)
+	assert.Empty(t, validation.IsValidLabelValue(shadow.Labels[labelShadowOf]))
+
+	// The header names the original Ingress, whose name may be longer than a label value.
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, shadows))
+	assert.True(t, strings.HasPrefix(buf.String(), "# Shadow of Ingress api/"+long.Name+"\n"), buf.String())
+}
+
+func TestWriteManifests(t *testing.T) {
+	t.Parallel()
+
+	shadows, err := New(testIngresses()[1:], Options{HostSuffix: ".traefik.local", NameSuffix: "-rehearsal", IngressClass: "traefik"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteManifests(&buf, shadows))
+
+	assert.Equal(t, `# Shadow of Ingress api/backend
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    ingress-nginx-migration.traefik.io/shadow-of: backend
+    kubernetes.io/ingress.class: traefik
+  labels:
+    ingress-nginx-migration.traefik.io/shadow-of: backend
+  name: backend-rehearsal
+  namespace: api
+spec:
+  defaultBackend:
+    service:
+      name: web
+      port:
+        number: 80
+`, buf.String())
+}
@@ -0,0 +1,80 @@
+package simulate
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	netv1 "k8s.io/api/networking/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// ReadIngresses reads the Ingresses of a multi-document YAML or JSON stream, such as the
+// output of "kubectl get ingresses -A -o yaml". The other objects are ignored.
+func ReadIngresses(r io.Reader) ([]*netv1.Ingress, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+
+	var ingresses []*netv1.Ingress
+	for {
+		doc, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading manifests: %w", err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		var object struct {
+			Kind  string            `json:"kind"`
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := yaml.Unmarshal(doc, &object); err != nil {
+			return nil, fmt.Errorf("parsing manifest: %w", err)
+		}
+
+		items := [][]byte{doc}
+		if object.Kind == "List" || object.Kind == "IngressList" {
+			items = items[:0]
+			for _, item := range object.Items {
+				items = append(items, item)
+			}
+		}
+
+		for _, item := range items {
+			var ing netv1.Ingress
+			if err := yaml.Unmarshal(item, &ing); err != nil {
+				return nil, fmt.Errorf("parsing Ingress: %w", err)
+			}
+			if ing.Kind == "Ingress" {
+				ingresses = append(ingresses, &ing)
+			}
+		}
+	}
+
+	return ingresses, nil
+}
+
+// ReadRequests reads the sample requests of a YAML or JSON list.
+func ReadRequests(data []byte) ([]Request, error) {
+	var requests []Request
+	if err := yaml.UnmarshalStrict(data, &requests); err != nil {
+		return nil, fmt.Errorf("parsing requests: %w", err)
+	}
+
+	for i, req := range requests {
+		if req.Host == "" {
+			return nil, fmt.Errorf("request %d has no host", i+1)
+		}
+		requests[i].Path = cmp.Or(req.Path, "/")
+	}
+
+	return requests, nil
+}
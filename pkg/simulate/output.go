@@ -0,0 +1,66 @@
+package simulate
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// Supported output formats.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+//go:embed simulate.md.tmpl
+var markdownTemplate string
+
+// Write writes the results in the given format.
+func Write(w io.Writer, results []Result, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return fmt.Errorf("encoding results as JSON: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		return writeMarkdown(w, results)
+	default:
+		return fmt.Errorf("unknown format %q (must be %q or %q)", format, FormatJSON, FormatMarkdown)
+	}
+}
+
+func writeMarkdown(w io.Writer, results []Result) error {
+	tmpl, err := template.New("simulate.md").Funcs(template.FuncMap{
+		// cell escapes the pipes of a table cell.
+		"cell": func(v fmt.Stringer) string {
+			return strings.ReplaceAll(v.String(), "|", `\|`)
+		},
+	}).Parse(markdownTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	var mismatches int
+	for _, result := range results {
+		if len(result.Mismatches) > 0 {
+			mismatches++
+		}
+	}
+
+	data := struct {
+		Results    []Result
+		Mismatches int
+	}{Results: results, Mismatches: mismatches}
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,540 @@
+// Package simulate compares, without any cluster or proxy, the routes NGINX and the
+// Traefik ingress-nginx provider pick for sample requests, from the Ingresses alone.
+//
+// NGINX first selects the server of the request host: an exact server name or alias,
+// then the longest wildcard, then the default server of the rules without host. It then
+// selects an exact location, the first matching regex location by decreasing path
+// length when an Ingress of the host enables regex paths, or the longest prefix location.
+//
+// Traefik matches the routers of every host at once, the router with the longest rule
+// wins. The wildcard hosts match a single label, and only the Ingresses enabling regex
+// paths have regex routers.
+package simulate
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/traefik/ingress-nginx-migration/pkg/analyzer"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	annotationPrefix = "nginx.ingress.kubernetes.io/"
+
+	annotationUseRegex      = annotationPrefix + "use-regex"
+	annotationRewriteTarget = annotationPrefix + "rewrite-target"
+	annotationServerAlias   = annotationPrefix + "server-alias"
+
+	annotationCanary              = annotationPrefix + "canary"
+	annotationCanaryByHeader      = annotationPrefix + "canary-by-header"
+	annotationCanaryHeaderValue   = annotationPrefix + "canary-by-header-value"
+	annotationCanaryHeaderPattern = annotationPrefix + "canary-by-header-pattern"
+	annotationCanaryByCookie      = annotationPrefix + "canary-by-cookie"
+	annotationCanaryWeight        = annotationPrefix + "canary-weight"
+	annotationCanaryWeightTotal   = annotationPrefix + "canary-weight-total"
+)
+
+var nginxCapture = regexp.MustCompile(`\$(\d)`)
+
+// Request is a sample request.
+type Request struct {
+	Method  string            `json:"method,omitempty"`
+	Host    string            `json:"host"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// String returns the request line of the request.
+func (r Request) String() string {
+	return cmp.Or(r.Method, "GET") + " " + r.Host + r.Path
+}
+
+// Match is the route picked for a request.
+type Match struct {
+	// Ingress is the Ingress of the route, as "namespace/name".
+	Ingress  string `json:"ingress"`
+	Host     string `json:"host,omitempty"`
+	Path     string `json:"path"`
+	PathType string `json:"pathType"`
+	// Backend is the Service of the route, as "name:port".
+	Backend string `json:"backend"`
+	// RewrittenPath is the path forwarded to the backend.
+	RewrittenPath string `json:"rewrittenPath"`
+	// Canary is the canary Ingress the request is sent to, if any.
+	Canary string `json:"canary,omitempty"`
+}
+
+// String returns a one-line description of the match.
+func (m *Match) String() string {
+	if m == nil {
+		return "default backend"
+	}
+
+	s := fmt.Sprintf("%s %q → %s %s", m.Ingress, m.Path, m.Backend, m.RewrittenPath)
+	if m.Canary != "" {
+		s += " (canary " + m.Canary + ")"
+	}
+	return s
+}
+
+// Result is the comparison of the routes picked for a request.
+type Result struct {
+	Request Request `json:"request"`
+	// NGINX and Traefik are the picked routes, nil when the request reaches the default backend.
+	NGINX   *Match `json:"nginx,omitempty"`
+	Traefik *Match `json:"traefik,omitempty"`
+	// Mismatches are the differences between the routes.
+	Mismatches []string `json:"mismatches,omitempty"`
+	// Notes explain the choices, such as the routes which could not be simulated.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// route is a path of an Ingress rule.
+type route struct {
+	ref      string
+	host     string
+	aliases  []string
+	path     string
+	pathType netv1.PathType
+	// regex is true when the Ingress of the route enables regex paths.
+	regex         bool
+	backend       string
+	rewriteTarget string
+	canaries      []canary
+}
+
+// canary is a canary Ingress of a route.
+type canary struct {
+	ref           string
+	backend       string
+	header        string
+	headerValue   string
+	headerPattern string
+	cookie        string
+	weight        int
+	weightTotal   int
+}
+
+// Simulator picks the routes of the requests.
+type Simulator struct {
+	routes []*route
+}
+
+// New returns a simulator of the Ingresses. The Ingresses are given in creation order,
+// in which NGINX keeps the first of the locations with the same path.
+func New(ingresses []*netv1.Ingress) *Simulator {
+	s := &Simulator{}
+
+	var canaries []*netv1.Ingress
+	for _, ing := range ingresses {
+		if ing.Annotations[annotationCanary] == "true" {
+			canaries = append(canaries, ing)
+			continue
+		}
+
+		_, hasRewrite := ing.Annotations[annotationRewriteTarget]
+		regex := ing.Annotations[annotationUseRegex] == "true" || hasRewrite
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+
+			var aliases []string
+			if rule.Host != "" {
+				aliases = splitList(ing.Annotations[annotationServerAlias])
+			}
+
+			for _, p := range rule.HTTP.Paths {
+				s.routes = append(s.routes, &route{
+					ref:           ing.Namespace + "/" + ing.Name,
+					host:          strings.ToLower(rule.Host),
+					aliases:       aliases,
+					path:          cmp.Or(p.Path, "/"),
+					pathType:      pathType(p),
+					regex:         regex,
+					backend:       backend(p.Backend),
+					rewriteTarget: ing.Annotations[annotationRewriteTarget],
+				})
+			}
+		}
+	}
+
+	// The canary Ingresses are merged into the routes with the same host and path.
+	for _, ing := range canaries {
+		c := canary{
+			ref:           ing.Namespace + "/" + ing.Name,
+			header:        ing.Annotations[annotationCanaryByHeader],
+			headerValue:   ing.Annotations[annotationCanaryHeaderValue],
+			headerPattern: ing.Annotations[annotationCanaryHeaderPattern],
+			cookie:        ing.Annotations[annotationCanaryByCookie],
+			weightTotal:   100,
+		}
+		if weight, err := strconv.Atoi(ing.Annotations[annotationCanaryWeight]); err == nil {
+			c.weight = weight
+		}
+		if total, err := strconv.Atoi(ing.Annotations[annotationCanaryWeightTotal]); err == nil && total > 0 {
+			c.weightTotal = total
+		}
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, p := range rule.HTTP.Paths {
+				for _, r := range s.routes {
+					if r.host == strings.ToLower(rule.Host) && r.path == cmp.Or(p.Path, "/") && r.ref != c.ref {
+						withBackend := c
+						withBackend.backend = backend(p.Backend)
+						r.canaries = append(r.canaries, withBackend)
+					}
+				}
+			}
+		}
+	}
+
+	return s
+}
+
+// Simulate returns the routes NGINX and Traefik pick for each request.
+func (s *Simulator) Simulate(requests []Request) []Result {
+	results := make([]Result, 0, len(requests))
+	for _, req := range requests {
+		results = append(results, s.simulate(req))
+	}
+	return results
+}
+
+func (s *Simulator) simulate(req Request) Result {
+	result := Result{Request: req}
+
+	host := strings.ToLower(req.Host)
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	path, _, _ := strings.Cut(cmp.Or(req.Path, "/"), "?")
+
+	if r := s.nginxRoute(host, path, &result); r != nil {
+		result.NGINX = match(r, path, req, &result, "NGINX")
+	}
+	if r := s.traefikRoute(host, path, &result); r != nil {
+		result.Traefik = match(r, path, req, &result, "Traefik")
+	}
+
+	switch n, t := result.NGINX, result.Traefik; {
+	case n == nil && t == nil:
+	case n == nil:
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("NGINX sends the request to the default backend, Traefik to the path %q of the Ingress %s", t.Path, t.Ingress))
+	case t == nil:
+		result.Mismatches = append(result.Mismatches, fmt.Sprintf("NGINX sends the request to the path %q of the Ingress %s, Traefik to the default backend", n.Path, n.Ingress))
+	default:
+		if n.Ingress != t.Ingress || n.Host != t.Host || n.Path != t.Path || n.PathType != t.PathType {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("NGINX picks the %s path %q of the Ingress %s, Traefik the %s path %q of the Ingress %s", n.PathType, n.Path, n.Ingress, t.PathType, t.Path, t.Ingress))
+		}
+		if n.Backend != t.Backend || n.Canary != t.Canary {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("NGINX proxies to %s, Traefik to %s", n.Backend, t.Backend))
+		}
+		if n.RewrittenPath != t.RewrittenPath {
+			result.Mismatches = append(result.Mismatches, fmt.Sprintf("NGINX forwards the path %q, Traefik %q", n.RewrittenPath, t.RewrittenPath))
+		}
+	}
+
+	return result
+}
+
+// nginxRoute returns the route of the location NGINX selects, nil for the default backend.
+func (s *Simulator) nginxRoute(host, path string, result *Result) *route {
+	server := s.nginxServer(host)
+
+	var routes []*route
+	regexServer := false
+	for _, r := range s.routes {
+		if r.host == server {
+			routes = append(routes, r)
+			regexServer = regexServer || r.regex
+		}
+	}
+
+	// The exact locations are selected first, including those NGINX adds for the
+	// Prefix paths without their trailing slash.
+	for _, r := range routes {
+		switch {
+		case r.pathType == netv1.PathTypeExact && path == r.path:
+			return r
+		case r.pathType == netv1.PathTypePrefix && !regexServer && r.path != "/" && path == strings.TrimSuffix(r.path, "/"):
+			return r
+		}
+	}
+
+	// Every other location of a host with regex paths is a case-insensitive regex,
+	// tried by decreasing path length.
+	if regexServer {
+		sorted := slices.Clone(routes)
+		slices.SortStableFunc(sorted, func(a, b *route) int {
+			return cmp.Compare(len(b.path), len(a.path))
+		})
+
+		for _, r := range sorted {
+			if r.pathType == netv1.PathTypeExact {
+				continue
+			}
+			if constructs := analyzer.PCREOnlyConstructs(r.path); len(constructs) > 0 {
+				result.Notes = append(result.Notes, fmt.Sprintf("NGINX: the PCRE path %q of the Ingress %s is not simulated", r.path, r.ref))
+				continue
+			}
+			re, err := regexp.Compile("(?i)^" + r.path)
+			if err != nil {
+				result.Notes = append(result.Notes, fmt.Sprintf("NGINX: the path %q of the Ingress %s is not simulated: %v", r.path, r.ref, err))
+				continue
+			}
+			if re.MatchString(path) {
+				return r
+			}
+		}
+		return nil
+	}
+
+	// The longest prefix location wins.
+	var best *route
+	bestLength := -1
+	for _, r := range routes {
+		location := r.path
+		if r.pathType == netv1.PathTypePrefix && r.path != "/" {
+			location = strings.TrimSuffix(r.path, "/") + "/"
+		}
+		if r.pathType != netv1.PathTypeExact && strings.HasPrefix(path, location) && len(location) > bestLength {
+			best, bestLength = r, len(location)
+		}
+	}
+	return best
+}
+
+// nginxServer returns the host of the server NGINX selects for the request host,
+// "" for the default server.
+func (s *Simulator) nginxServer(host string) string {
+	var wildcard string
+	for _, r := range s.routes {
+		if r.host == host {
+			return host
+		}
+		if suffix, ok := strings.CutPrefix(r.host, "*"); ok && strings.HasSuffix(host, suffix) && len(r.host) > len(wildcard) {
+			wildcard = r.host
+		}
+	}
+
+	// The aliases conflicting with a host are ignored.
+	for _, r := range s.routes {
+		if slices.Contains(r.aliases, host) {
+			return r.host
+		}
+	}
+
+	return wildcard
+}
+
+// traefikRoute returns the route of the router Traefik selects, nil when none matches.
+func (s *Simulator) traefikRoute(host, path string, result *Result) *route {
+	var best []*route
+	var bestRule string
+	for _, r := range s.routes {
+		if !traefikHostMatches(r, host) {
+			continue
+		}
+
+		matches, err := traefikPathMatches(r, path)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("Traefik: the router of the path %q of the Ingress %s is rejected: %v", r.path, r.ref, err))
+			continue
+		}
+		if !matches {
+			continue
+		}
+
+		switch rule := analyzer.TraefikRule(r.host, r.aliases, r.path, r.pathType, r.regex); {
+		case len(best) == 0 || len(rule) > len(bestRule):
+			best, bestRule = []*route{r}, rule
+		case len(rule) == len(bestRule):
+			best = append(best, r)
+		}
+	}
+
+	if len(best) == 0 {
+		return nil
+	}
+
+	for _, r := range best[1:] {
+		result.Notes = append(result.Notes, fmt.Sprintf("Traefik: the routers of the path %q of the Ingress %s and of the path %q of the Ingress %s have the same priority %d, the first one is not guaranteed to win", best[0].path, best[0].ref, r.path, r.ref, len(bestRule)))
+	}
+	return best[0]
+}
+
+func traefikHostMatches(r *route, host string) bool {
+	if r.host == "" {
+		return true
+	}
+
+	for _, h := range slices.Concat([]string{r.host}, r.aliases) {
+		if h == host {
+			return true
+		}
+		// The wildcards match a single label.
+		if suffix, ok := strings.CutPrefix(h, "*"); ok {
+			if label, ok := strings.CutSuffix(host, suffix); ok && label != "" && !strings.Contains(label, ".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func traefikPathMatches(r *route, path string) (bool, error) {
+	switch {
+	case r.pathType == netv1.PathTypeExact:
+		return path == r.path, nil
+	case r.regex:
+		if err := analyzer.RegexPathError(r.path); err != nil {
+			return false, err
+		}
+		return regexp.MustCompile("(?i)^" + r.path).MatchString(path), nil
+	case r.pathType == netv1.PathTypePrefix && r.path != "/":
+		trimmed := strings.TrimSuffix(r.path, "/")
+		return path == trimmed || strings.HasPrefix(path, trimmed+"/"), nil
+	default:
+		return strings.HasPrefix(path, r.path), nil
+	}
+}
+
+// match returns the match of the route for the request, sent to a canary or rewritten.
+func match(r *route, path string, req Request, result *Result, proxy string) *Match {
+	m := &Match{Ingress: r.ref, Host: r.host, Path: r.path, PathType: string(r.pathType), Backend: r.backend, RewrittenPath: path}
+
+	for _, c := range r.canaries {
+		if selected, note := c.selects(req); selected {
+			m.Backend, m.Canary = c.backend, c.ref
+			break
+		} else if note != "" {
+			result.Notes = append(result.Notes, proxy+": "+note)
+		}
+	}
+
+	if r.rewriteTarget != "" {
+		rewritten, err := rewrite(r.path, r.rewriteTarget, path)
+		if err != nil {
+			result.Notes = append(result.Notes, fmt.Sprintf("%s: the path %q of the Ingress %s is not rewritten: %v", proxy, r.path, r.ref, err))
+		} else {
+			m.RewrittenPath = rewritten
+		}
+	}
+
+	return m
+}
+
+// selects returns whether the canary receives the request.
+func (c canary) selects(req Request) (bool, string) {
+	if c.header != "" {
+		if value, ok := header(req, c.header); ok {
+			switch {
+			case c.headerValue != "":
+				if value == c.headerValue {
+					return true, ""
+				}
+			case c.headerPattern != "":
+				if re, err := regexp.Compile(c.headerPattern); err == nil && re.MatchString(value) {
+					return true, ""
+				}
+			case value == "always":
+				return true, ""
+			case value == "never":
+				return false, ""
+			}
+		}
+	}
+
+	if c.cookie != "" {
+		switch cookie(req, c.cookie) {
+		case "always":
+			return true, ""
+		case "never":
+			return false, ""
+		}
+	}
+
+	switch {
+	case c.weight >= c.weightTotal:
+		return true, ""
+	case c.weight > 0:
+		return false, fmt.Sprintf("%d/%d of the requests are sent to the canary %s", c.weight, c.weightTotal, c.ref)
+	default:
+		return false, ""
+	}
+}
+
+// rewrite returns the path rewritten with the rewrite target, whose captures $1 to $9
+// refer to the groups of the regex path.
+func rewrite(pattern, target, path string) (string, error) {
+	if err := analyzer.RegexPathError(pattern); err != nil {
+		return "", err
+	}
+
+	// NGINX replaces the whole path with the target.
+	re := regexp.MustCompile("(?i)^(?:" + pattern + ")")
+	loc := re.FindStringSubmatchIndex(path)
+	if loc == nil {
+		return path, nil
+	}
+
+	return string(re.ExpandString(nil, nginxCapture.ReplaceAllString(target, "$${$1}"), path, loc)), nil
+}
+
+func header(req Request, name string) (string, bool) {
+	for key, value := range req.Headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func cookie(req Request, name string) string {
+	cookies, _ := header(req, "Cookie")
+	for c := range strings.SplitSeq(cookies, ";") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(c), "="); ok && key == name {
+			return value
+		}
+	}
+	return ""
+}
+
+func pathType(p netv1.HTTPIngressPath) netv1.PathType {
+	if p.PathType == nil {
+		return netv1.PathTypeImplementationSpecific
+	}
+	return *p.PathType
+}
+
+func backend(b netv1.IngressBackend) string {
+	switch {
+	case b.Service == nil:
+		return "resource"
+	case b.Service.Port.Name != "":
+		return b.Service.Name + ":" + b.Service.Port.Name
+	default:
+		return b.Service.Name + ":" + strconv.Itoa(int(b.Service.Port.Number))
+	}
+}
+
+// splitList splits a comma-separated list.
+func splitList(value string) []string {
+	var items []string
+	for item := range strings.SplitSeq(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.ToLower(item))
+		}
+	}
+	return items
+}
@@ -0,0 +1,188 @@
+package simulate
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func TestSimulate(t *testing.T) {
+	t.Parallel()
+
+	ingresses := []*netv1.Ingress{
+		testutil.Ingress("default", "web", map[string]string{"nginx.ingress.kubernetes.io/server-alias": "www.example.com"}, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("example.com",
+				testutil.Path("/", netv1.PathTypePrefix, "web", 80),
+				testutil.Path("/docs/", netv1.PathTypePrefix, "docs", 80),
+				testutil.Path("/static", netv1.PathTypeImplementationSpecific, "static", 80),
+				testutil.Path("/about", netv1.PathTypeExact, "about", 80),
+				testutil.Path("/about", netv1.PathTypePrefix, "about-prefix", 80),
+			),
+		}}),
+		testutil.Ingress("default", "api", map[string]string{"nginx.ingress.kubernetes.io/rewrite-target": "/$2"}, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("api.example.com",
+				testutil.Path("/v1(/|$)(.*)", netv1.PathTypeImplementationSpecific, "api-v1", 80),
+				testutil.Path("/(?!internal)(.*)", netv1.PathTypeImplementationSpecific, "api", 80),
+			),
+		}}),
+		testutil.Ingress("default", "api-health", nil, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("api.example.com", testutil.Path("/Health", netv1.PathTypePrefix, "health", 80)),
+		}}),
+		testutil.Ingress("default", "wildcard", nil, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("*.apps.example.com", testutil.Path("/", netv1.PathTypePrefix, "apps", 80)),
+		}}),
+		testutil.Ingress("default", "fallback", nil, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("", testutil.Path("/metrics/prometheus/federation", netv1.PathTypePrefix, "metrics", 80)),
+		}}),
+		testutil.Ingress("default", "web-canary", map[string]string{
+			"nginx.ingress.kubernetes.io/canary":           "true",
+			"nginx.ingress.kubernetes.io/canary-by-header": "X-Canary",
+		}, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("example.com", testutil.Path("/", netv1.PathTypePrefix, "web-v2", 80)),
+		}}),
+	}
+
+	results := New(ingresses).Simulate([]Request{
+		{Host: "example.com", Path: "/"},
+		{Host: "Example.com:8080", Path: "/docs"},
+		{Host: "example.com", Path: "/staticfiles/app.js"},
+		{Host: "example.com", Path: "/about"},
+		{Host: "www.example.com", Path: "/", Headers: map[string]string{"x-canary": "always"}},
+		{Host: "api.example.com", Path: "/v1/users?page=2"},
+		{Host: "api.example.com", Path: "/health"},
+		{Host: "a.b.apps.example.com", Path: "/"},
+		{Host: "example.com", Path: "/metrics/prometheus/federation"},
+	})
+	require.Len(t, results, 9)
+
+	summary := func(m *Match) string {
+		if m == nil {
+			return ""
+		}
+		return m.Ingress + " " + m.Path + " " + m.Backend + " " + m.RewrittenPath
+	}
+
+	tests := []struct {
+		nginx      string
+		traefik    string
+		mismatches int
+	}{
+		{nginx: "default/web / web:80 /", traefik: "default/web / web:80 /"},
+		// Prefix paths match on path elements, NGINX adds an exact location without the trailing slash.
+		{nginx: "default/web /docs/ docs:80 /docs", traefik: "default/web /docs/ docs:80 /docs"},
+		// ImplementationSpecific paths are string prefixes.
+		{nginx: "default/web /static static:80 /staticfiles/app.js", traefik: "default/web /static static:80 /staticfiles/app.js"},
+		// The exact locations win in NGINX, the longest rules in Traefik.
+		{nginx: "default/web /about about:80 /about", traefik: "default/web /about about-prefix:80 /about", mismatches: 2},
+		{nginx: "default/web / web-v2:80 /", traefik: "default/web / web-v2:80 /"},
+		{nginx: "default/api /v1(/|$)(.*) api-v1:80 /users", traefik: "default/api /v1(/|$)(.*) api-v1:80 /users"},
+		// NGINX matches the other paths of the host as case-insensitive regexes.
+		{nginx: "default/api-health /Health health:80 /health", traefik: "", mismatches: 1},
+		// The Traefik wildcards match a single label.
+		{nginx: "default/wildcard / apps:80 /", traefik: "", mismatches: 1},
+		// NGINX only uses the rules without host for the unknown hosts.
+		{nginx: "default/web / web:80 /metrics/prometheus/federation", traefik: "default/fallback /metrics/prometheus/federation metrics:80 /metrics/prometheus/federation", mismatches: 2},
+	}
+
+	for i, tt := range tests {
+		t.Run(results[i].Request.String(), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.nginx, summary(results[i].NGINX))
+			assert.Equal(t, tt.traefik, summary(results[i].Traefik))
+			assert.Len(t, results[i].Mismatches, tt.mismatches)
+		})
+	}
+
+	assert.Equal(t, "default/web-canary", results[4].NGINX.Canary)
+	assert.Equal(t, []string{
+		`NGINX: the PCRE path "/(?!internal)(.*)" of the Ingress default/api is not simulated`,
+		`Traefik: the router of the path "/(?!internal)(.*)" of the Ingress default/api is rejected: Go regexes do not support the PCRE lookaheads`,
+	}, results[5].Notes)
+}
+
+func TestReadIngresses(t *testing.T) {
+	t.Parallel()
+
+	manifests := `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: networking.k8s.io/v1
+  kind: Ingress
+  metadata:
+    name: web
+    namespace: default
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+  namespace: default
+`
+
+	ingresses, err := ReadIngresses(strings.NewReader(manifests))
+	require.NoError(t, err)
+
+	var names []string
+	for _, ing := range ingresses {
+		names = append(names, ing.Name)
+	}
+	assert.Equal(t, []string{"web", "api"}, names)
+}
+
+func TestReadRequests(t *testing.T) {
+	t.Parallel()
+
+	requests, err := ReadRequests([]byte(`
+- host: example.com
+- method: POST
+  host: api.example.com
+  path: /v1/users
+  headers:
+    X-Canary: always
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Request{
+		{Host: "example.com", Path: "/"},
+		{Method: "POST", Host: "api.example.com", Path: "/v1/users", Headers: map[string]string{"X-Canary": "always"}},
+	}, requests)
+
+	_, err = ReadRequests([]byte(`- path: /`))
+	assert.EqualError(t, err, "request 1 has no host")
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	ingresses := []*netv1.Ingress{
+		testutil.Ingress("default", "web", nil, netv1.IngressSpec{Rules: []netv1.IngressRule{
+			testutil.Rule("example.com",
+				testutil.Path("/about", netv1.PathTypeExact, "about", 80),
+				testutil.Path("/about", netv1.PathTypePrefix, "about-prefix", 80),
+			),
+		}}),
+	}
+
+	results := New(ingresses).Simulate([]Request{
+		{Host: "example.com", Path: "/about"},
+		{Method: "POST", Host: "example.com", Path: "/about/a|b"},
+		{Host: "other.example.com", Path: "/"},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, results, FormatMarkdown))
+
+	testutil.AssertGolden(t, "simulate.md", buf.Bytes())
+
+	assert.EqualError(t, Write(&buf, results, "yaml"), `unknown format "yaml" (must be "json" or "markdown")`)
+}
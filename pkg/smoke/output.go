@@ -0,0 +1,113 @@
+package smoke
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Supported report formats.
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+//go:embed smoke.md.tmpl
+var markdownTemplate string
+
+// WriteSuite writes the suite as YAML.
+func WriteSuite(w io.Writer, suite Suite) error {
+	data, err := yaml.Marshal(suite)
+	if err != nil {
+		return fmt.Errorf("marshaling suite: %w", err)
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing suite: %w", err)
+	}
+
+	return nil
+}
+
+// ReadSuite reads a suite written by WriteSuite.
+func ReadSuite(data []byte) (Suite, error) {
+	var suite Suite
+	if err := yaml.UnmarshalStrict(data, &suite); err != nil {
+		return Suite{}, fmt.Errorf("parsing suite: %w", err)
+	}
+
+	for i, probe := range suite.Probes {
+		if probe.Scheme != SchemeHTTP && probe.Scheme != SchemeHTTPS {
+			return Suite{}, fmt.Errorf("probe %d has an invalid scheme %q (must be %q or %q)", i+1, probe.Scheme, SchemeHTTP, SchemeHTTPS)
+		}
+		if probe.Host == "" || !strings.HasPrefix(probe.Path, "/") {
+			return Suite{}, fmt.Errorf("probe %d must have a host and an absolute path", i+1)
+		}
+	}
+
+	return suite, nil
+}
+
+// Report is the outcome of the probes of a suite.
+type Report struct {
+	Target  string   `json:"target"`
+	Results []Result `json:"results"`
+	Passed  int      `json:"passed"`
+	Failed  int      `json:"failed"`
+}
+
+// NewReport returns the report of the results.
+func NewReport(target string, results []Result) Report {
+	report := Report{Target: target, Results: results}
+	for _, result := range results {
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// Write writes the report in the given format.
+func Write(w io.Writer, report Report, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return fmt.Errorf("encoding report as JSON: %w", err)
+		}
+		return nil
+	case FormatMarkdown:
+		return writeMarkdown(w, report)
+	default:
+		return fmt.Errorf("unknown format %q (must be %q or %q)", format, FormatJSON, FormatMarkdown)
+	}
+}
+
+func writeMarkdown(w io.Writer, report Report) error {
+	tmpl, err := template.New("smoke.md").Funcs(template.FuncMap{
+		// cell escapes the pipes of a table cell.
+		"cell": func(s string) string {
+			return strings.ReplaceAll(s, "|", `\|`)
+		},
+		"join": func(items []string) string {
+			return strings.Join(items, "; ")
+		},
+	}).Parse(markdownTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing markdown template: %w", err)
+	}
+
+	if err := tmpl.Execute(w, report); err != nil {
+		return fmt.Errorf("executing markdown template: %w", err)
+	}
+
+	return nil
+}
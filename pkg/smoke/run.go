@@ -0,0 +1,135 @@
+package smoke
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RunOptions configures the run of a suite.
+type RunOptions struct {
+	// Address is the address of the ingress controller, the probes are sent to whatever
+	// their host resolves to.
+	Address   string
+	HTTPPort  int
+	HTTPSPort int
+	// Timeout is the timeout of each probe.
+	Timeout     time.Duration
+	Concurrency int
+}
+
+// Result is the outcome of a probe.
+type Result struct {
+	Probe    Probe  `json:"probe"`
+	Status   int    `json:"status,omitempty"`
+	Location string `json:"location,omitempty"`
+	Passed   bool   `json:"passed"`
+	// Failures are the unmet expectations.
+	Failures []string `json:"failures,omitempty"`
+}
+
+// Run sends the probes of the suite to the ingress controller.
+func Run(ctx context.Context, suite Suite, opts RunOptions) []Result {
+	client := &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport(opts),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	results := make([]Result, len(suite.Probes))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(1, opts.Concurrency) {
+		wg.Go(func() {
+			for i := range jobs {
+				results[i] = run(ctx, client, suite.Probes[i])
+			}
+		})
+	}
+
+	for i := range suite.Probes {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// transport returns a transport connecting to the ingress controller whatever the host
+// of the request, and sending the host as SNI. The certificates are checked by the
+// probes, against their host only.
+func transport(opts RunOptions) *http.Transport {
+	dialer := &net.Dialer{Timeout: opts.Timeout}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		port := opts.HTTPPort
+		if _, p, err := net.SplitHostPort(addr); err == nil && p == "443" {
+			port = opts.HTTPSPort
+		}
+		return dialer.DialContext(ctx, network, net.JoinHostPort(opts.Address, strconv.Itoa(port)))
+	}
+	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec // The probes verify the certificates against their host.
+
+	return t
+}
+
+func run(ctx context.Context, client *http.Client, probe Probe) Result {
+	result := Result{Probe: probe}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probe.Scheme+"://"+probe.Host+probe.Path, nil)
+	if err != nil {
+		return result.fail(err.Error())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return result.fail(err.Error())
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	result.Status = resp.StatusCode
+	result.Location = resp.Header.Get("Location")
+
+	expect := probe.Expect
+	if len(expect.Status) > 0 && !slices.Contains(expect.Status, resp.StatusCode) {
+		result.Failures = append(result.Failures, fmt.Sprintf("status %d, expected %v", resp.StatusCode, expect.Status))
+	}
+	if slices.Contains(expect.NotStatus, resp.StatusCode) {
+		result.Failures = append(result.Failures, fmt.Sprintf("status %d", resp.StatusCode))
+	}
+	if expect.Location != "" && result.Location != expect.Location {
+		result.Failures = append(result.Failures, fmt.Sprintf("Location %q, expected %q", result.Location, expect.Location))
+	}
+	if expect.Certificate {
+		switch {
+		case resp.TLS == nil || len(resp.TLS.PeerCertificates) == 0:
+			result.Failures = append(result.Failures, "no certificate served")
+		default:
+			if err := resp.TLS.PeerCertificates[0].VerifyHostname(probe.Host); err != nil {
+				result.Failures = append(result.Failures, err.Error())
+			}
+		}
+	}
+
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+func (r Result) fail(failure string) Result {
+	r.Failures = append(r.Failures, failure)
+	return r
+}
@@ -0,0 +1,225 @@
+// Package smoke generates HTTP probes from the rules of the Ingresses, with the
+// redirect, authentication and TLS behavior their annotations imply, and runs them
+// against an ingress controller as a regression check around each cutover wave.
+package smoke
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	netv1 "k8s.io/api/networking/v1"
+)
+
+const (
+	annotationPrefix = "nginx.ingress.kubernetes.io/"
+
+	// wildcardLabel replaces the wildcard of the hosts probed.
+	wildcardLabel = "smoke"
+)
+
+// Probe schemes.
+const (
+	SchemeHTTP  = "http"
+	SchemeHTTPS = "https"
+)
+
+// unavailable are the statuses of a route which is missing or whose backend is unavailable.
+var unavailable = []int{http.StatusNotFound, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// Suite is a portable suite of probes.
+type Suite struct {
+	Probes []Probe `json:"probes"`
+	// Notes are the paths which could not be probed.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Probe is an HTTP request and its expected response.
+type Probe struct {
+	Name string `json:"name"`
+	// Ingress is the Ingress the probe is generated from, as "namespace/name".
+	Ingress string `json:"ingress"`
+	Scheme  string `json:"scheme"`
+	Host    string `json:"host"`
+	Path    string `json:"path"`
+	Expect  Expect `json:"expect"`
+	// Because is the annotation or rule the expectation is inferred from.
+	Because string `json:"because,omitempty"`
+}
+
+// Expect is the expected response of a probe.
+type Expect struct {
+	// Status lists the expected statuses, any of them passes.
+	Status []int `json:"status,omitempty"`
+	// NotStatus lists the failing statuses.
+	NotStatus []int `json:"notStatus,omitempty"`
+	// Location is the expected redirect Location.
+	Location string `json:"location,omitempty"`
+	// Certificate is true when the served certificate must cover the host.
+	Certificate bool `json:"certificate,omitempty"`
+}
+
+// Generate returns the probes of the Ingresses: a probe per host and path variant over
+// HTTP, and over HTTPS for the hosts of a TLS entry.
+func Generate(ingresses []*netv1.Ingress) Suite {
+	sorted := slices.Clone(ingresses)
+	slices.SortFunc(sorted, func(a, b *netv1.Ingress) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Name, b.Name))
+	})
+
+	var suite Suite
+	for _, ing := range sorted {
+		ref := ing.Namespace + "/" + ing.Name
+		annotations := annotationReader(ing.Annotations)
+
+		_, hasRewrite := annotations.get("rewrite-target")
+		regex := annotations.isTrue("use-regex") || hasRewrite
+
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host == "" {
+				suite.Notes = append(suite.Notes, fmt.Sprintf("the rule without host of the Ingress %s is not probed", ref))
+				continue
+			}
+			if rule.HTTP == nil {
+				continue
+			}
+
+			host := rule.Host
+			if suffix, ok := strings.CutPrefix(host, "*."); ok {
+				host = wildcardLabel + "." + suffix
+			}
+			tls := coversTLS(ing, rule.Host)
+
+			for _, path := range rule.HTTP.Paths {
+				variants, ok := pathVariants(path, regex)
+				if !ok {
+					suite.Notes = append(suite.Notes, fmt.Sprintf("the regex path %q of the Ingress %s is not probed", path.Path, ref))
+					continue
+				}
+
+				for _, variant := range variants {
+					schemes := []string{SchemeHTTP}
+					if tls {
+						schemes = append(schemes, SchemeHTTPS)
+					}
+
+					for _, scheme := range schemes {
+						expect, because := expectation(annotations, scheme, host, variant, tls)
+						suite.Probes = append(suite.Probes, Probe{
+							Name:    fmt.Sprintf("%s %s://%s%s", ref, scheme, host, variant),
+							Ingress: ref,
+							Scheme:  scheme,
+							Host:    host,
+							Path:    variant,
+							Expect:  expect,
+							Because: because,
+						})
+					}
+				}
+			}
+		}
+	}
+
+	return suite
+}
+
+// expectation returns the expected response of the probe and the annotation or rule it
+// is inferred from, in the order NGINX applies them.
+func expectation(annotations annotationReader, scheme, host, path string, tls bool) (Expect, string) {
+	if target, ok := annotations.get("app-root"); ok && path == "/" {
+		return Expect{Status: []int{http.StatusFound}, Location: target}, "app-root"
+	}
+
+	if target, ok := annotations.get("permanent-redirect"); ok {
+		return Expect{Status: []int{annotations.code("permanent-redirect-code", http.StatusMovedPermanently)}, Location: target}, "permanent-redirect"
+	}
+	if target, ok := annotations.get("temporal-redirect"); ok {
+		return Expect{Status: []int{annotations.code("temporal-redirect-code", http.StatusFound)}, Location: target}, "temporal-redirect"
+	}
+
+	if scheme == SchemeHTTP {
+		sslRedirect, _ := annotations.get("ssl-redirect")
+		switch {
+		case annotations.isTrue("force-ssl-redirect"):
+			return Expect{Status: []int{http.StatusPermanentRedirect}, Location: "https://" + host + path}, "force-ssl-redirect"
+		case tls && sslRedirect != "false":
+			return Expect{Status: []int{http.StatusPermanentRedirect}, Location: "https://" + host + path}, "TLS host"
+		}
+	}
+
+	expect := Expect{Certificate: scheme == SchemeHTTPS}
+
+	if authType, ok := annotations.get("auth-type"); ok {
+		expect.Status = []int{http.StatusUnauthorized}
+		return expect, "auth-type " + authType
+	}
+	if _, ok := annotations.get("auth-url"); ok {
+		if _, ok := annotations.get("auth-signin"); ok {
+			expect.Status = []int{http.StatusFound}
+			return expect, "auth-signin"
+		}
+		expect.Status = []int{http.StatusUnauthorized, http.StatusForbidden}
+		return expect, "auth-url"
+	}
+
+	expect.NotStatus = unavailable
+	return expect, ""
+}
+
+// pathVariants returns the paths probing the path of a rule: both with and without
+// trailing slash for the prefixes. The regex paths are only probed without metacharacters.
+func pathVariants(path netv1.HTTPIngressPath, regex bool) ([]string, bool) {
+	p := cmp.Or(path.Path, "/")
+
+	pathType := netv1.PathTypeImplementationSpecific
+	if path.PathType != nil {
+		pathType = *path.PathType
+	}
+
+	switch {
+	case pathType == netv1.PathTypeExact:
+		return []string{p}, true
+	case regex && regexp.QuoteMeta(p) != p:
+		return nil, false
+	case pathType == netv1.PathTypePrefix && p != "/":
+		trimmed := strings.TrimSuffix(p, "/")
+		return []string{trimmed, trimmed + "/"}, true
+	default:
+		return []string{p}, true
+	}
+}
+
+// coversTLS returns whether a TLS entry of the Ingress covers the host.
+func coversTLS(ing *netv1.Ingress, host string) bool {
+	for _, tls := range ing.Spec.TLS {
+		if slices.Contains(tls.Hosts, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationReader reads the NGINX annotations of an Ingress.
+type annotationReader map[string]string
+
+func (a annotationReader) get(name string) (string, bool) {
+	value, ok := a[annotationPrefix+name]
+	return value, ok
+}
+
+func (a annotationReader) isTrue(name string) bool {
+	value, _ := a.get(name)
+	return value == "true"
+}
+
+func (a annotationReader) code(name string, defaultCode int) int {
+	value, _ := a.get(name)
+	if code, err := strconv.Atoi(value); err == nil && code >= 300 && code < 400 {
+		return code
+	}
+	return defaultCode
+}
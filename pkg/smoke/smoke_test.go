@@ -0,0 +1,140 @@
+package smoke
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/ingress-nginx-migration/pkg/internal/testutil"
+	netv1 "k8s.io/api/networking/v1"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	ingresses := []*netv1.Ingress{
+		testutil.Ingress("shop", "web", map[string]string{"nginx.ingress.kubernetes.io/app-root": "/home"}, netv1.IngressSpec{
+			TLS: []netv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "web-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("shop.example.com",
+				testutil.Path("/", netv1.PathTypePrefix, "web", 80),
+				testutil.Path("/cart/", netv1.PathTypePrefix, "web", 80),
+			)},
+		}),
+		testutil.Ingress("shop", "admin", map[string]string{
+			"nginx.ingress.kubernetes.io/auth-type":    "basic",
+			"nginx.ingress.kubernetes.io/ssl-redirect": "false",
+		}, netv1.IngressSpec{
+			TLS:   []netv1.IngressTLS{{Hosts: []string{"admin.example.com"}, SecretName: "admin-tls"}},
+			Rules: []netv1.IngressRule{testutil.Rule("admin.example.com", testutil.Path("/admin", netv1.PathTypeExact, "web", 80))},
+		}),
+		testutil.Ingress("legacy", "moved", map[string]string{
+			"nginx.ingress.kubernetes.io/permanent-redirect":      "https://new.example.com",
+			"nginx.ingress.kubernetes.io/permanent-redirect-code": "308",
+		}, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("old.example.com", testutil.Path("/", netv1.PathTypePrefix, "web", 80))},
+		}),
+		testutil.Ingress("apps", "wildcard", map[string]string{
+			"nginx.ingress.kubernetes.io/use-regex": "true",
+			"nginx.ingress.kubernetes.io/auth-url":  "http://auth.auth.svc/verify",
+		}, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("*.apps.example.com",
+				testutil.Path("/api/v[0-9]+", netv1.PathTypeImplementationSpecific, "web", 80),
+				testutil.Path("/static", netv1.PathTypeImplementationSpecific, "web", 80),
+			)},
+		}),
+		testutil.Ingress("apps", "default", nil, netv1.IngressSpec{
+			Rules: []netv1.IngressRule{testutil.Rule("", testutil.Path("/", netv1.PathTypePrefix, "web", 80))},
+		}),
+	}
+
+	suite := Generate(ingresses)
+
+	assert.Equal(t, []string{
+		"the rule without host of the Ingress apps/default is not probed",
+		`the regex path "/api/v[0-9]+" of the Ingress apps/wildcard is not probed`,
+	}, suite.Notes)
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSuite(&buf, suite))
+	testutil.AssertGolden(t, "suite.yaml", buf.Bytes())
+
+	read, err := ReadSuite(buf.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, suite, read)
+}
+
+func TestReadSuite(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadSuite([]byte("probes:\n- name: x\n  scheme: ftp\n  host: a\n  path: /\n"))
+	assert.EqualError(t, err, `probe 1 has an invalid scheme "ftp" (must be "http" or "https")`)
+
+	_, err = ReadSuite([]byte("probes:\n- name: x\n  scheme: http\n  host: a\n  path: /\n  unknown: true\n"))
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.TLS == nil && req.Host == "example.com":
+			http.Redirect(rw, req, "https://example.com"+req.URL.Path, http.StatusPermanentRedirect)
+		case req.URL.Path == "/missing":
+			rw.WriteHeader(http.StatusNotFound)
+		default:
+			rw.WriteHeader(http.StatusOK)
+		}
+	})
+
+	plain := httptest.NewServer(handler)
+	t.Cleanup(plain.Close)
+	secure := httptest.NewTLSServer(handler)
+	t.Cleanup(secure.Close)
+
+	port := func(server *httptest.Server) int {
+		_, p, err := net.SplitHostPort(server.Listener.Addr().String())
+		require.NoError(t, err)
+		n, err := strconv.Atoi(p)
+		require.NoError(t, err)
+		return n
+	}
+
+	suite := Suite{Probes: []Probe{
+		{Name: "redirect", Scheme: SchemeHTTP, Host: "example.com", Path: "/cart", Expect: Expect{Status: []int{308}, Location: "https://example.com/cart"}},
+		{Name: "https", Scheme: SchemeHTTPS, Host: "example.com", Path: "/cart", Expect: Expect{NotStatus: unavailable, Certificate: true}},
+		{Name: "missing", Scheme: SchemeHTTPS, Host: "example.com", Path: "/missing", Expect: Expect{NotStatus: unavailable}},
+		{Name: "certificate", Scheme: SchemeHTTPS, Host: "other.example.org", Path: "/", Expect: Expect{Certificate: true}},
+		{Name: "auth", Scheme: SchemeHTTP, Host: "admin.example.com", Path: "/", Expect: Expect{Status: []int{401}}},
+	}}
+
+	results := Run(t.Context(), suite, RunOptions{
+		Address:     "127.0.0.1",
+		HTTPPort:    port(plain),
+		HTTPSPort:   port(secure),
+		Timeout:     5 * time.Second,
+		Concurrency: 2,
+	})
+	require.Len(t, results, 5)
+
+	assert.True(t, results[0].Passed, results[0].Failures)
+	assert.True(t, results[1].Passed, results[1].Failures)
+	assert.Equal(t, []string{"status 404"}, results[2].Failures)
+	require.Len(t, results[3].Failures, 1)
+	assert.Contains(t, results[3].Failures[0], "not other.example.org")
+	assert.Equal(t, []string{"status 200, expected [401]"}, results[4].Failures)
+
+	report := NewReport("127.0.0.1", results)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 3, report.Failed)
+
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, report, FormatMarkdown))
+	testutil.AssertGolden(t, "report.md", buf.Bytes())
+}